@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+var amendMessages []string
+var amendGit bool
+var amendForce bool
+var amendMaxAge string
+
+var amendCmd = &cobra.Command{
+	Use:   "amend [bundle]",
+	Short: "Add to the most recent context bundle without starting a session",
+	Long: "Add annotations to a bundle after stopping (-m, repeatable), or refresh\n" +
+		"its git section with --git. The bundle is rewritten in place in its own\n" +
+		"format. Without an argument the newest bundle in the output directory is\n" +
+		"amended. Bundles stopped longer ago than --max-age need --force.",
+	Args: cobra.MaximumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if len(amendMessages) == 0 && !amendGit {
+			return errors.New("nothing to amend: pass -m to add a note or --git to refresh git state")
+		}
+		maxAge, err := parseAge(amendMaxAge)
+		if err != nil {
+			return fmt.Errorf("invalid --max-age %q: %w", amendMaxAge, err)
+		}
+
+		var path string
+		if len(args) == 1 {
+			path = args[0]
+		} else {
+			dir := cfg.OutputDir
+			if dir == "" {
+				dir = "."
+			}
+			if path, _, err = latestBundle(dir, ""); err != nil {
+				return err
+			}
+		}
+		if isEncryptedBundle(path) {
+			return fmt.Errorf("%s is encrypted and can't be amended", path)
+		}
+		data, parser, err := readBundleFile(path)
+		if err != nil {
+			return err
+		}
+		b, err := parser.Parse(data)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if age := now.Sub(b.Session.StopTime); age > maxAge && !amendForce {
+			return fmt.Errorf("%s was stopped %s ago, more than --max-age %s; use --force to amend it anyway",
+				path, age.Round(time.Minute), amendMaxAge)
+		}
+
+		for _, msg := range amendMessages {
+			b.Annotations = append(b.Annotations, session.Annotation{Timestamp: now, Message: msg})
+		}
+
+		if amendGit {
+			c := GetConfig()
+			g := &collector.GitCollector{
+				WorkDir:     b.Session.WorkDir,
+				LogLimit:    c.GitLogLimit,
+				LogAuthor:   c.GitLogAuthor,
+				LogNoMerges: config.On(c.GitLogNoMerges),
+			}
+			timeout, err := time.ParseDuration(c.CollectTimeout)
+			if err != nil || timeout <= 0 {
+				return fmt.Errorf("invalid collect_timeout %q in config", c.CollectTimeout)
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), timeout)
+			defer cancel()
+			result, err := g.Collect(ctx, &session.Session{ID: b.Session.ID, StartTime: b.Session.StartTime, WorkDir: b.Session.WorkDir})
+			if err != nil {
+				return fmt.Errorf("git collector: %w", err)
+			}
+			if result.GitInfo == nil {
+				return fmt.Errorf("cannot refresh git state: %s: %v", b.Session.WorkDir, result.Warnings)
+			}
+			b.Git = result.GitInfo
+			b.SetDiffStat()
+		}
+
+		// The signature covers the old contents, so it can't be kept.
+		signed := b.Signature != "" || bundle.MarkdownSignature(data) != ""
+		b.Signature = ""
+
+		out, err := renderLike(path, data, b)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return fmt.Errorf("write bundle: %w", err)
+		}
+
+		fmt.Printf("Amended %s\n", path)
+		if signed {
+			fmt.Println("The bundle's signature was removed; run 'handoff sign' to sign it again.")
+		}
+		return nil
+	},
+}
+
+func init() {
+	amendCmd.Flags().StringArrayVarP(&amendMessages, "message", "m", nil, "add an annotation with this text (repeatable)")
+	amendCmd.Flags().BoolVar(&amendGit, "git", false, "re-run the git collector and replace the bundle's git state")
+	amendCmd.Flags().BoolVarP(&amendForce, "force", "f", false, "amend even if the bundle is older than --max-age")
+	amendCmd.Flags().StringVar(&amendMaxAge, "max-age", "7d", "refuse to amend bundles stopped longer ago than this, e.g. 7d or 48h")
+	rootCmd.AddCommand(amendCmd)
+}
@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestAmendAddsNote verifies that amend appends annotations to the newest
+// bundle in place.
+func TestAmendAddsNote(t *testing.T) {
+	dir := setupOpenDir(t)
+	writeOpenBundle(t, dir, "handoff-old.md", "/work/old", time.Now().Add(-time.Hour))
+	writeOpenBundle(t, dir, "handoff-new.md", "/work/new", time.Now())
+	t.Cleanup(func() { amendMessages, amendForce = nil, false })
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "amend", "--force", "-m", "forgot this", "-m", "and this")
+	})
+	if runErr != nil {
+		t.Fatalf("amend: %v", runErr)
+	}
+	if !strings.Contains(stdout, "Amended "+filepath.Join(dir, "handoff-new.md")) {
+		t.Errorf("unexpected output: %q", stdout)
+	}
+
+	b, err := readBundle(filepath.Join(dir, "handoff-new.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Annotations) != 2 || b.Annotations[0].Message != "forgot this" || b.Annotations[1].Message != "and this" {
+		t.Errorf("annotations: %+v", b.Annotations)
+	}
+	if old, _ := readBundle(filepath.Join(dir, "handoff-old.md")); len(old.Annotations) != 0 {
+		t.Errorf("older bundle was amended: %+v", old.Annotations)
+	}
+}
+
+// TestAmendRefusesOldBundle verifies that a bundle stopped longer ago than
+// --max-age is only amended with --force.
+func TestAmendRefusesOldBundle(t *testing.T) {
+	dir := setupOpenDir(t)
+	path := writeStoppedBundle(t, dir, "handoff-a.json", time.Now().Add(-10*24*time.Hour))
+	t.Cleanup(func() { amendMessages, amendForce = nil, false })
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "amend", "-m", "late note", path)
+	if err == nil || !strings.Contains(err.Error(), "use --force") {
+		t.Fatalf("expected an age error, got %v", err)
+	}
+
+	amendMessages = nil
+	rootCmd.ResetFlags()
+	captureStdout(t, func() {
+		_, err = executeCommand(rootCmd, "amend", "--force", "-m", "late note", path)
+	})
+	if err != nil {
+		t.Fatalf("amend --force: %v", err)
+	}
+	b, err := readBundle(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b.Annotations) != 1 || b.Annotations[0].Message != "late note" {
+		t.Errorf("annotations: %+v", b.Annotations)
+	}
+}
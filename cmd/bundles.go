@@ -0,0 +1,218 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// readBundle reads and parses the bundle at path, choosing the parser by
+// file extension.
+func readBundle(path string) (*bundle.ContextBundle, error) {
+	data, parser, err := readBundleFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return parser.Parse(data)
+}
+
+// readBundleHeader is like readBundle but only decodes the session metadata
+// and collection sizes.
+func readBundleHeader(path string) (*bundle.SessionMeta, bundle.Counts, error) {
+	data, parser, err := readBundleFile(path)
+	if err != nil {
+		return nil, bundle.Counts{}, err
+	}
+	return parser.ParseHeader(data)
+}
+
+// bundleParser is implemented by both the JSON and Markdown parsers.
+type bundleParser interface {
+	bundle.BundleParser
+	bundle.HeaderParser
+}
+
+// readBundleFile reads path and picks the parser for its extension.
+func readBundleFile(path string) ([]byte, bundleParser, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("file not found: %s", path)
+		}
+		return nil, nil, err
+	}
+
+	// An encrypted bundle is decrypted in memory only; the extension
+	// under .age says what it holds.
+	name := path
+	if isEncryptedBundle(path) {
+		passphrase, err := bundlePassphrase(false)
+		if err != nil {
+			return nil, nil, err
+		}
+		if data, err = bundle.Decrypt(data, passphrase); err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", path, err)
+		}
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return data, &bundle.JSONParser{}, nil
+	case ".jsonl":
+		return nil, nil, fmt.Errorf("%s is a JSON Lines export and cannot be read back; use a markdown or json bundle", path)
+	}
+	return data, &bundle.MarkdownParser{}, nil
+}
+
+// renderLike renders b for the bundle file at path, whose current contents
+// are orig: as JSON or Markdown by the file's extension, and for Markdown
+// with a frontmatter block if orig has one.
+func renderLike(path string, orig []byte, b *bundle.ContextBundle) ([]byte, error) {
+	var renderer bundle.BundleRenderer = &bundle.JSONRenderer{}
+	if !isJSONBundle(path) {
+		renderer = &bundle.MarkdownRenderer{Frontmatter: bytes.HasPrefix(orig, []byte("---\n"))}
+	}
+	data, err := renderer.Render(b)
+	if err != nil {
+		return nil, fmt.Errorf("render bundle: %w", err)
+	}
+	return data, nil
+}
+
+// bundleSaver returns the function the viewer calls to write b, edited,
+// back to path in the file's own format and layout, and whether the file is
+// signed. Saving drops the signature, which no longer matches. An encrypted
+// bundle is encrypted again with the passphrase it was read with.
+func bundleSaver(path string, b *bundle.ContextBundle) (save func(*bundle.ContextBundle) error, signed bool, err error) {
+	orig, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	name, passphrase := path, ""
+	if isEncryptedBundle(path) {
+		if passphrase, err = bundlePassphrase(false); err != nil {
+			return nil, false, err
+		}
+		if orig, err = bundle.Decrypt(orig, passphrase); err != nil {
+			return nil, false, fmt.Errorf("%s: %w", path, err)
+		}
+		name = strings.TrimSuffix(path, filepath.Ext(path))
+	}
+	signed = b.Signature != "" || bundle.MarkdownSignature(orig) != ""
+	save = func(b *bundle.ContextBundle) error {
+		b.Signature = ""
+		data, err := renderLike(name, orig, b)
+		if err != nil {
+			return err
+		}
+		out := data
+		if passphrase != "" {
+			if out, err = bundle.Encrypt(data, passphrase); err != nil {
+				return fmt.Errorf("encrypt bundle: %w", err)
+			}
+		}
+		if err := os.WriteFile(path, out, 0644); err != nil {
+			return fmt.Errorf("write %s: %w", filepath.Base(path), err)
+		}
+		orig = data
+		return nil
+	}
+	return save, signed, nil
+}
+
+// isEncryptedBundle reports whether path is an encrypted bundle by its
+// extension.
+func isEncryptedBundle(path string) bool {
+	return strings.EqualFold(filepath.Ext(path), bundle.EncryptedExt)
+}
+
+// rendererFor returns the renderer for a --format value along with the
+// format's canonical name ("markdown", "json" or "jsonl").
+func rendererFor(format string) (bundle.BundleRenderer, string, error) {
+	switch strings.ToLower(format) {
+	case "markdown", "md":
+		return &bundle.MarkdownRenderer{}, "markdown", nil
+	case "json":
+		return &bundle.JSONRenderer{}, "json", nil
+	case "jsonl":
+		return &bundle.JSONLRenderer{}, "jsonl", nil
+	default:
+		return nil, "", fmt.Errorf("unknown format %q (expected markdown, json or jsonl)", format)
+	}
+}
+
+// bundleExtensions maps a --format value to the file extensions it selects.
+var bundleExtensions = map[string][]string{
+	"":         {".md", ".json"},
+	"markdown": {".md"},
+	"md":       {".md"},
+	"json":     {".json"},
+}
+
+// findBundles returns the candidate bundle files in dir, newest first. format
+// restricts the result to one extension ("markdown" or "json"); empty keeps
+// both. Encrypted bundles count by the extension under .age. Files are not
+// parsed, so callers must still skip invalid ones.
+func findBundles(dir, format string) ([]string, error) {
+	exts, ok := bundleExtensions[strings.ToLower(format)]
+	if !ok {
+		return nil, fmt.Errorf("unknown format %q (expected markdown or json)", format)
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		path  string
+		mtime int64
+	}
+	var found []candidate
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		name := e.Name()
+		if isEncryptedBundle(name) {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+		if !slices.Contains(exts, strings.ToLower(filepath.Ext(name))) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		found = append(found, candidate{filepath.Join(dir, e.Name()), info.ModTime().UnixNano()})
+	}
+
+	sort.SliceStable(found, func(i, j int) bool { return found[i].mtime > found[j].mtime })
+	paths := make([]string, len(found))
+	for i, c := range found {
+		paths[i] = c.path
+	}
+	return paths, nil
+}
+
+// latestBundle returns the newest file in dir that parses as a bundle, along
+// with the parsed bundle.
+func latestBundle(dir, format string) (string, *bundle.ContextBundle, error) {
+	paths, err := findBundles(dir, format)
+	if err != nil {
+		return "", nil, err
+	}
+	for _, path := range paths {
+		if b, err := readBundle(path); err == nil {
+			return path, b, nil
+		}
+	}
+	return "", nil, fmt.Errorf("no handoff bundle found in %s", dir)
+}
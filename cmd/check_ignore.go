@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+var checkIgnoreCmd = &cobra.Command{
+	Use:   "check-ignore <path>...",
+	Short: "Show whether a path is ignored, and by which pattern",
+	Long: "Load the ignore patterns stop uses (ignore_patterns in config, .gitignore\n" +
+		"files and the global git excludes file, then .handoffignore) and report,\n" +
+		"for each path, whether it is left out of bundles. Like 'git check-ignore\n" +
+		"-v', the pattern that decides is shown as <file>:<line>:<pattern>; a \"!\"\n" +
+		"pattern is one that re-includes the path.\n\n" +
+		"Patterns are read from the active session's work dir, or the current\n" +
+		"directory when no session is active.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		workDir, err := checkIgnoreWorkDir()
+		if err != nil {
+			return err
+		}
+		fc := &collector.FileCollector{
+			WorkDir:        workDir,
+			IgnorePatterns: cfg.IgnorePatterns,
+			SkipGitignore:  !config.Enabled(cfg.RespectGitignore),
+		}
+
+		for _, arg := range args {
+			path, err := filepath.Abs(arg)
+			if err != nil {
+				return err
+			}
+			m, err := fc.CheckIgnore(context.Background(), path)
+			if err != nil {
+				return fmt.Errorf("load ignore patterns: %w", err)
+			}
+			verdict := "not ignored"
+			if m.Ignored {
+				verdict = "ignored"
+			}
+			if m.Pattern == "" {
+				fmt.Printf("%s: %s\n", arg, verdict)
+				continue
+			}
+			source := "ignore_patterns"
+			if m.Source != "" {
+				source = m.Source
+				if rel, err := filepath.Rel(workDir, source); err == nil && !strings.HasPrefix(rel, "..") {
+					source = rel
+				}
+			}
+			fmt.Printf("%s: %s (%s:%d:%s)\n", arg, verdict, source, m.Line, m.Pattern)
+		}
+		return nil
+	},
+}
+
+// checkIgnoreWorkDir returns the active session's work dir, or the current
+// directory when there is no active session.
+func checkIgnoreWorkDir() (string, error) {
+	store, err := openSessionStore()
+	if err != nil {
+		return "", err
+	}
+	s, err := store.Load()
+	switch {
+	case err == nil:
+		return s.WorkDir, nil
+	case !errors.Is(err, session.ErrNoSession):
+		return "", err
+	}
+	return os.Getwd()
+}
+
+func init() {
+	addSessionNameFlag(checkIgnoreCmd)
+	rootCmd.AddCommand(checkIgnoreCmd)
+}
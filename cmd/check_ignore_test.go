@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckIgnore verifies that check-ignore reports each path's verdict
+// with the deciding pattern, reading patterns from the current directory
+// when no session is active.
+func TestCheckIgnore(t *testing.T) {
+	tmp := setupOpenDir(t)
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(tmp, ".config"))
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, ".gitignore"), []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workDir, ".handoffignore"), []byte("!keep.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(workDir)
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "check-ignore", "debug.log", "keep.log", "main.go")
+	})
+	if runErr != nil {
+		t.Fatalf("check-ignore: %v", runErr)
+	}
+	want := "debug.log: ignored (.gitignore:1:*.log)\n" +
+		"keep.log: not ignored (.handoffignore:1:!keep.log)\n" +
+		"main.go: not ignored\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+}
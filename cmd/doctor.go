@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
+	"github.com/fakeyudi/handoff/internal/profile"
+	"github.com/fakeyudi/handoff/internal/session"
+	"github.com/fakeyudi/handoff/internal/shell"
+)
+
+// doctorCheck is the outcome of one `handoff doctor` check.
+type doctorCheck struct {
+	name     string
+	ok       bool
+	detail   string
+	hint     string // how to fix it, shown when the check fails
+	critical bool   // a failure makes doctor exit non-zero
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Check that handoff is set up correctly",
+	Long: "Check the profile, config, shell plugin, shell history, required tools " +
+		"and data directories, with a hint for each problem found.\n\n" +
+		"Exits non-zero if a check that stops handoff from working fails.",
+	// Bypass the normal PersistentPreRunE: doctor reports a missing profile
+	// or a broken config instead of tripping over it.
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
+	Args:              cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		checks := runDoctorChecks()
+
+		failed := 0
+		for _, c := range checks {
+			mark := "✓"
+			if !c.ok {
+				mark = "✗"
+				if c.critical {
+					failed++
+				}
+			}
+			fmt.Printf("  %s %-16s %s\n", mark, c.name, c.detail)
+			if !c.ok && c.hint != "" {
+				fmt.Printf("      → %s\n", c.hint)
+			}
+		}
+		if failed > 0 {
+			return fmt.Errorf("%d critical check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// runDoctorChecks runs every check in the order they are reported.
+func runDoctorChecks() []doctorCheck {
+	var checks []doctorCheck
+
+	// Profile.
+	var prof *profile.Profile
+	if !profile.Exists() {
+		checks = append(checks, doctorCheck{name: "Profile", detail: "not found",
+			hint: "run 'handoff setup'", critical: true})
+	} else if p, err := profile.Load(); err != nil {
+		checks = append(checks, doctorCheck{name: "Profile", detail: err.Error(),
+			hint: "run 'handoff setup' to rewrite it", critical: true})
+	} else {
+		prof = p
+		checks = append(checks, doctorCheck{name: "Profile", ok: true, detail: "found"})
+	}
+
+	// Config files.
+	merged := config.Defaults()
+	global, gErr := loadGlobalConfig()
+	project, pErr := loadProjectConfig()
+	switch {
+	case gErr != nil:
+		checks = append(checks, doctorCheck{name: "Config", detail: gErr.Error(),
+			hint: "fix the global config file", critical: true})
+	case pErr != nil:
+		checks = append(checks, doctorCheck{name: "Config", detail: pErr.Error(),
+			hint: "fix .handoffconfig in this directory", critical: true})
+	default:
+		merged = config.Merge(global, project)
+		checks = append(checks, doctorCheck{name: "Config", ok: true, detail: "valid"})
+	}
+
+	checks = append(checks, shellPluginChecks(prof)...)
+	checks = append(checks, historyCheck(merged.ShellHistoryPath))
+
+	// Tools.
+	checks = append(checks, toolCheck("git", "install git; file diffs and branch info need it", true))
+
+	// Directories.
+	if dir, err := session.DataDir(); err == nil {
+		checks = append(checks, dirCheck("Data dir", dir))
+	}
+	if dir, err := profile.ConfigDir(); err == nil {
+		checks = append(checks, dirCheck("Config dir", dir))
+	}
+	return checks
+}
+
+// shellPluginChecks reports whether the plugin is installed and loaded by the
+// shell, when command recording is on.
+func shellPluginChecks(prof *profile.Profile) []doctorCheck {
+	if prof == nil || !prof.RecordCommands || prof.ShellPluginShell == "" {
+		return []doctorCheck{{name: "Shell plugin", ok: true,
+			detail: "not enabled (commands are read from the shell history file)"}}
+	}
+	sh := prof.ShellPluginShell
+	if !shell.IsInstalled(sh) {
+		return []doctorCheck{{name: "Shell plugin", detail: "not installed for " + sh,
+			hint: "run 'handoff setup' to install it"}}
+	}
+	path, _ := shell.PluginPath(sh)
+	sourced, checked := shell.IsSourced(sh)
+	switch {
+	case !checked:
+		return []doctorCheck{{name: "Shell plugin", ok: true,
+			detail: "installed at " + path + " (make sure your shell profile loads it)"}}
+	case !sourced:
+		return []doctorCheck{{name: "Shell plugin", detail: "installed but not loaded by your " + sh + " rc file",
+			hint: "add 'source " + path + "' to your rc file and restart the shell"}}
+	}
+	return []doctorCheck{{name: "Shell plugin", ok: true, detail: "installed and loaded (" + sh + ")"}}
+}
+
+// historyCheck reports whether the shell history file can be read and
+// records timestamps.
+func historyCheck(override string) doctorCheck {
+	status, err := collector.InspectHistory(override)
+	if err != nil {
+		return doctorCheck{name: "Shell history", detail: fmt.Sprintf("%s: %v", status.Path, err),
+			hint: "set shell_history_path in the config, or " + collector.HistoryFlushHint}
+	}
+	if status.Commands > 0 && status.Timestamped == 0 {
+		return doctorCheck{name: "Shell history", detail: status.Path + " has no timestamps",
+			hint: "commands are matched to the session by position only; add 'setopt EXTENDED_HISTORY' " +
+				"to ~/.zshrc (zsh) or 'export HISTTIMEFORMAT=\"%F %T \"' to ~/.bashrc (bash)"}
+	}
+	return doctorCheck{name: "Shell history", ok: true,
+		detail: fmt.Sprintf("%s (%d commands)", status.Path, status.Commands)}
+}
+
+// toolCheck reports whether name is on PATH.
+func toolCheck(name, hint string, critical bool) doctorCheck {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return doctorCheck{name: name, detail: "not found on PATH", hint: hint, critical: critical}
+	}
+	return doctorCheck{name: name, ok: true, detail: path}
+}
+
+// dirCheck reports whether dir exists (or can be created) and is writable.
+func dirCheck(name, dir string) doctorCheck {
+	fail := func(err error) doctorCheck {
+		return doctorCheck{name: name, detail: err.Error(),
+			hint: "check the permissions of " + filepath.Dir(dir), critical: true}
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fail(err)
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return fail(err)
+	}
+	f.Close()
+	os.Remove(f.Name())
+	return doctorCheck{name: name, ok: true, detail: dir}
+}
+
+func init() {
+	rootCmd.AddCommand(doctorCmd)
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/profile"
+)
+
+// TestDoctorReportsMissingProfile verifies that doctor flags a missing
+// profile with a hint and fails, and passes once the profile exists.
+func TestDoctorReportsMissingProfile(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", filepath.Join(tmp, "data"))
+	t.Setenv("SHELL", "/bin/bash")
+	t.Setenv("HISTFILE", "")
+	history := fmt.Sprintf("#%d\ngo test ./...\n", time.Now().Unix())
+	if err := os.WriteFile(filepath.Join(tmp, ".bash_history"), []byte(history), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "doctor")
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "critical") {
+		t.Errorf("expected a critical failure without a profile, got %v", runErr)
+	}
+	if !strings.Contains(stdout, "✗ Profile") || !strings.Contains(stdout, "→ run 'handoff setup'") {
+		t.Errorf("expected a failed profile check with a hint, got:\n%s", stdout)
+	}
+
+	if err := profile.Save(&profile.Profile{Name: "dev", DefaultFormat: "markdown", OutputDir: "."}); err != nil {
+		t.Fatalf("Save profile: %v", err)
+	}
+	stdout = captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "doctor")
+	})
+	if runErr != nil {
+		t.Fatalf("doctor with a profile: %v\n%s", runErr, stdout)
+	}
+	for _, want := range []string{"✓ Profile", "✓ Shell history", "(1 commands)", "✓ Data dir"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var exportFormat string
+var exportOutput string
+
+var exportCmd = &cobra.Command{
+	Use:   "export <bundle>",
+	Short: "Convert a context bundle to another format",
+	Long: "Convert a context bundle between Markdown and JSON.\n\n" +
+		"The bundle is written to stdout unless -o names an output file.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path := args[0]
+
+		if exportFormat == "" {
+			return fmt.Errorf("--format is required (markdown or json)")
+		}
+		renderer, format, err := rendererFor(exportFormat)
+		if err != nil {
+			return err
+		}
+
+		inputFormat := "markdown"
+		if strings.EqualFold(filepath.Ext(path), ".json") {
+			inputFormat = "json"
+		}
+		if inputFormat == format && exportOutput == "" {
+			return fmt.Errorf("%s is already in %s format; use -o to write a copy", path, format)
+		}
+
+		b, err := readBundle(path)
+		if err != nil {
+			return err
+		}
+		data, err := renderer.Render(b)
+		if err != nil {
+			return fmt.Errorf("render bundle: %w", err)
+		}
+
+		if exportOutput == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(exportOutput, data, 0644); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Exported %s to %s\n", path, exportOutput)
+		return nil
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringVar(&exportFormat, "format", "", "output format: markdown, json or jsonl")
+	exportCmd.Flags().StringVarP(&exportOutput, "output", "o", "", "write to this file instead of stdout")
+	rootCmd.AddCommand(exportCmd)
+}
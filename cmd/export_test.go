@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// TestExportConvertsFormat verifies that "export" re-renders a JSON bundle
+// as Markdown without losing data.
+func TestExportConvertsFormat(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	writeOpenBundle(t, tmp, "bundle.json", "/work/export", time.Now())
+	t.Cleanup(func() { exportFormat, exportOutput = "", "" })
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "export", "--format", "markdown", filepath.Join(tmp, "bundle.json"))
+	})
+	if runErr != nil {
+		t.Fatalf("export: %v", runErr)
+	}
+	b, err := (&bundle.MarkdownParser{}).Parse([]byte(stdout))
+	if err != nil {
+		t.Fatalf("output is not a Markdown bundle: %v", err)
+	}
+	if b.Session.WorkDir != "/work/export" {
+		t.Errorf("work dir: got %q, want %q", b.Session.WorkDir, "/work/export")
+	}
+}
+
+// TestExportSameFormatNeedsOutput verifies that exporting to the input's own
+// format without -o is rejected.
+func TestExportSameFormatNeedsOutput(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	writeOpenBundle(t, tmp, "bundle.json", "/work/export", time.Now())
+	t.Cleanup(func() { exportFormat, exportOutput = "", "" })
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "export", "--format", "json", filepath.Join(tmp, "bundle.json"))
+	if err == nil || !strings.Contains(err.Error(), "already in json format") {
+		t.Errorf("expected same-format error, got %v", err)
+	}
+}
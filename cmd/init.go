@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/config"
+)
+
+var initForce bool
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a .handoffconfig for this project",
+	Long: "Write a .handoffconfig in the current directory with the common project\n" +
+		"settings commented out, ready to edit and commit. An existing file is\n" +
+		"left alone unless --force is given.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		const path = ".handoffconfig"
+		flags := os.O_WRONLY | os.O_CREATE | os.O_EXCL
+		if initForce {
+			flags = os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+		}
+		f, err := os.OpenFile(path, flags, 0644)
+		if errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("%s already exists; use --force to overwrite it", path)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := f.WriteString(config.ProjectTemplate); err != nil {
+			f.Close()
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		if err := f.Close(); err != nil {
+			return fmt.Errorf("write %s: %w", path, err)
+		}
+		fmt.Printf("Created %s\n", path)
+		return nil
+	},
+}
+
+func init() {
+	initCmd.Flags().BoolVarP(&initForce, "force", "f", false, "overwrite an existing .handoffconfig")
+	rootCmd.AddCommand(initCmd)
+}
@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/config"
+)
+
+// TestInitWritesProjectConfig verifies that "init" writes the template, won't
+// overwrite an existing .handoffconfig, and will with --force even when the
+// existing file doesn't parse.
+func TestInitWritesProjectConfig(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Chdir(tmp)
+	t.Cleanup(func() { initForce = false })
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "init") })
+	if runErr != nil {
+		t.Fatalf("init: %v", runErr)
+	}
+	if data, err := os.ReadFile(".handoffconfig"); err != nil || string(data) != config.ProjectTemplate {
+		t.Fatalf("expected the template in .handoffconfig, got %q (%v)", data, err)
+	}
+
+	if err := os.WriteFile(".handoffconfig", []byte("{ not json"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "init")
+	if err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Errorf("expected a refusal to overwrite, got %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "init", "--force") })
+	if runErr != nil {
+		t.Fatalf("init --force: %v", runErr)
+	}
+	if data, _ := os.ReadFile(".handoffconfig"); string(data) != config.ProjectTemplate {
+		t.Errorf("--force did not rewrite the file: %q", data)
+	}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+var listTag string
+var listFormat string
+
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the context bundles in the output directory",
+	Long: "List the context bundles in the output directory, newest first.\n\n" +
+		"With --tag, only bundles carrying that tag are shown.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := cfg.OutputDir
+		if dir == "" {
+			dir = "."
+		}
+
+		paths, err := findBundles(dir, listFormat)
+		if err != nil {
+			return err
+		}
+
+		shown := 0
+		for _, path := range paths {
+			b, err := readBundle(path)
+			if err != nil {
+				continue // not a bundle
+			}
+			if listTag != "" && !slices.Contains(b.Tags, listTag) {
+				continue
+			}
+			line := fmt.Sprintf("%s  %s  %s", filepath.Base(path),
+				b.Session.StopTime.Format("2006-01-02 15:04"), b.Session.WorkDir)
+			if stat := b.Session.DiffStatLine(); stat != "" {
+				line += "  (" + stat + ")"
+			}
+			if len(b.Tags) > 0 {
+				line += "  [" + strings.Join(b.Tags, ", ") + "]"
+			}
+			fmt.Println(line)
+			shown++
+		}
+
+		if shown == 0 {
+			if listTag != "" {
+				fmt.Printf("no bundles tagged %q in %s\n", listTag, dir)
+			} else {
+				fmt.Printf("no bundles in %s\n", dir)
+			}
+		}
+		return nil
+	},
+}
+
+func init() {
+	listCmd.Flags().StringVar(&listTag, "tag", "", "only list bundles with this tag")
+	listCmd.Flags().StringVar(&listFormat, "format", "", "only list bundles of this format: markdown or json")
+	rootCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// TestListFiltersByTag verifies that "list --tag" only shows bundles carrying
+// the tag.
+func TestListFiltersByTag(t *testing.T) {
+	dir := setupOpenDir(t)
+	t.Cleanup(func() { listTag = "" })
+
+	for name, tags := range map[string][]string{
+		"handoff-a.json": {"bugfix"},
+		"handoff-b.json": {"feature"},
+	} {
+		data, err := (&bundle.JSONRenderer{}).Render(&bundle.ContextBundle{
+			Session: bundle.SessionMeta{ID: name, StopTime: time.Now()},
+			Tags:    tags,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "list", "--tag", "bugfix")
+	})
+	if runErr != nil {
+		t.Fatalf("list --tag: %v", runErr)
+	}
+	if !strings.Contains(stdout, "handoff-a.json") || !strings.Contains(stdout, "[bugfix]") {
+		t.Errorf("expected the bugfix bundle, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "handoff-b.json") {
+		t.Errorf("expected the feature bundle to be filtered out, got:\n%s", stdout)
+	}
+}
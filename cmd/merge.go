@@ -0,0 +1,69 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+var mergeFormat string
+var mergeOutput string
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <bundle> <bundle>...",
+	Short: "Combine several context bundles into one",
+	Long: "Combine bundles from the same piece of work, e.g. one per machine when pair-programming.\n\n" +
+		"File edits are unioned (keeping the latest edit of each file), notes and commands are\n" +
+		"interleaved by time, and the git section is taken from the first bundle that has one.\n" +
+		"The result is written to stdout unless -o names an output file.",
+	Args: cobra.MinimumNArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format := mergeFormat
+		if format == "" {
+			format = cfg.DefaultFormat
+		}
+		if format == "" {
+			format = "markdown"
+		}
+		renderer, _, err := rendererFor(format)
+		if err != nil {
+			return err
+		}
+
+		bundles := make([]*bundle.ContextBundle, 0, len(args))
+		for _, path := range args {
+			b, err := readBundle(path)
+			if err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			bundles = append(bundles, b)
+		}
+
+		merged, conflicts := bundle.Merge(bundles)
+		data, err := renderer.Render(merged)
+		if err != nil {
+			return fmt.Errorf("render bundle: %w", err)
+		}
+
+		for _, w := range conflicts {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+		}
+		if mergeOutput == "" {
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+		if err := os.WriteFile(mergeOutput, data, 0644); err != nil {
+			return fmt.Errorf("write output file: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Merged %d bundles into %s\n", len(bundles), mergeOutput)
+		return nil
+	},
+}
+
+func init() {
+	mergeCmd.Flags().StringVar(&mergeFormat, "format", "", "output format: markdown or json (default from config)")
+	mergeCmd.Flags().StringVarP(&mergeOutput, "output", "o", "", "write to this file instead of stdout")
+	rootCmd.AddCommand(mergeCmd)
+}
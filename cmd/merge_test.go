@@ -0,0 +1,40 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// TestMergeWritesCombinedBundle verifies that "merge -o" writes one bundle
+// covering the sessions of all inputs.
+func TestMergeWritesCombinedBundle(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	writeOpenBundle(t, tmp, "a.json", "/work", time.Now())
+	writeOpenBundle(t, tmp, "b.md", "/work", time.Now())
+	t.Cleanup(func() { mergeFormat, mergeOutput = "", "" })
+
+	out := filepath.Join(tmp, "merged.json")
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "merge", "--format", "json", "-o", out,
+		filepath.Join(tmp, "a.json"), filepath.Join(tmp, "b.md"))
+	if err != nil {
+		t.Fatalf("merge: %v", err)
+	}
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("read merged bundle: %v", err)
+	}
+	b, err := (&bundle.JSONParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("merged output is not a JSON bundle: %v", err)
+	}
+	if b.Session.ID != "a.json+b.md" {
+		t.Errorf("session ID = %q, want %q", b.Session.ID, "a.json+b.md")
+	}
+}
@@ -3,37 +3,104 @@ package cmd
 import (
 	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/clipboard"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
+var noteFile string
+var noteLine int
+var noteLog string
+var noteLogLines int
+var noteFromClipboard bool
+var noteLevel string
+
+// maxLogExcerptBytes caps how much of a log file's tail note --log reads,
+// however many lines were asked for.
+const maxLogExcerptBytes = 64 * 1024
+
 var noteCmd = &cobra.Command{
-	Use:   "note <message>",
+	Use:   "note [message]",
 	Short: "Add a note to the current tracking session",
-	Args:  cobra.ExactArgs(1),
+	Long: "Add a note to the current tracking session.\n\n" +
+		"With --file (and optionally --line), the note is anchored to that location and " +
+		"shown next to the file's edits when viewing the bundle.\n\n" +
+		"With --log, the last --log-lines lines of a build or test log are attached to the note.\n\n" +
+		"With --from-clipboard, the clipboard contents become the message unless one is given.\n\n" +
+		"With --level blocker (or warn), the note is flagged prominently in the bundle.",
+	Args: cobra.MaximumNArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		store, err := session.NewSessionStore()
-		if err != nil {
-			return err
+		var message string
+		switch {
+		case len(args) == 1:
+			message = args[0]
+		case noteFromClipboard:
+			text, err := clipboard.Paste()
+			if err != nil {
+				return fmt.Errorf("read clipboard: %w", err)
+			}
+			if strings.TrimSpace(text) == "" {
+				return errors.New("clipboard is empty")
+			}
+			message = text
+		default:
+			return errors.New("a message is required (or use --from-clipboard)")
 		}
-
-		s, err := store.Load()
-		if err != nil {
-			if errors.Is(err, session.ErrNoSession) {
-				return fmt.Errorf("no active session")
+		if !slices.Contains(session.Levels, noteLevel) {
+			return fmt.Errorf("invalid --level %q (expected %s)", noteLevel, strings.Join(session.Levels, ", "))
+		}
+		// Info is the default, so leave it out of the session file.
+		level := noteLevel
+		if level == session.LevelInfo {
+			level = ""
+		}
+		if noteLine != 0 && noteFile == "" {
+			return fmt.Errorf("--line requires --file")
+		}
+		if noteLine < 0 {
+			return fmt.Errorf("--line must be a positive line number")
+		}
+		var path string
+		if noteFile != "" {
+			abs, err := filepath.Abs(noteFile)
+			if err != nil {
+				return err
 			}
-			return err
+			path = abs
+		}
+		if noteLogLines <= 0 {
+			return fmt.Errorf("--log-lines must be positive")
+		}
+		var excerpt string
+		if noteLog != "" {
+			tail, err := tailLines(noteLog, noteLogLines)
+			if err != nil {
+				return fmt.Errorf("read log: %w", err)
+			}
+			excerpt = tail
 		}
 
-		s.Annotations = append(s.Annotations, session.Annotation{
-			Timestamp: time.Now(),
-			Message:   args[0],
-			IsSummary: false,
+		annotation := session.Annotation{
+			Timestamp:  time.Now(),
+			Message:    message,
+			IsSummary:  false,
+			Path:       path,
+			Line:       noteLine,
+			LogExcerpt: excerpt,
+			Level:      level,
+		}
+		err := updateActiveSession(func(s *session.Session) error {
+			s.Annotations = append(s.Annotations, annotation)
+			return nil
 		})
-
-		if err := store.Save(s); err != nil {
+		if err != nil {
 			return err
 		}
 
@@ -42,6 +109,49 @@ var noteCmd = &cobra.Command{
 	},
 }
 
+// tailLines returns the last n lines of the file at path. Only the final
+// maxLogExcerptBytes are read, so huge logs are not loaded into memory.
+func tailLines(path string, n int) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return "", err
+	}
+	offset := info.Size() - maxLogExcerptBytes
+	if offset < 0 {
+		offset = 0
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return "", err
+	}
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimRight(string(data), "\n")
+	lines := strings.Split(text, "\n")
+	if offset > 0 && len(lines) > 1 {
+		lines = lines[1:] // the first line was probably cut by the seek
+	}
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
 func init() {
+	noteCmd.Flags().StringVar(&noteFile, "file", "", "Anchor the note to this file")
+	noteCmd.Flags().IntVar(&noteLine, "line", 0, "Anchor the note to this line of --file")
+	noteCmd.Flags().StringVar(&noteLog, "log", "", "Attach the tail of this log file to the note")
+	noteCmd.Flags().IntVar(&noteLogLines, "log-lines", 50, "Number of lines to attach from --log")
+	noteCmd.Flags().StringVar(&noteLevel, "level", session.LevelInfo, "Severity of the note: info, warn or blocker")
+	noteCmd.Flags().BoolVar(&noteFromClipboard, "from-clipboard", false, "Use the clipboard contents as the message")
+	addSessionNameFlag(noteCmd)
 	rootCmd.AddCommand(noteCmd)
 }
@@ -1,6 +1,10 @@
 package cmd
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -116,3 +120,176 @@ func TestAnnotationPersistence(t *testing.T) {
 		}
 	})
 }
+
+// TestNoteAnchoredToFile verifies that --file/--line store an absolute path
+// and line on the annotation, and that --line without --file is rejected.
+func TestNoteAnchoredToFile(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Cleanup(func() { noteFile, noteLine = "", 0 })
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := store.Save(&session.Session{ID: "test-id", StartTime: time.Now(), WorkDir: tmp}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "note", "--line", "3", "orphan"); err == nil {
+		t.Error("expected an error for --line without --file, got nil")
+	}
+
+	target := filepath.Join(tmp, "main.go")
+	if _, err := executeCommand(rootCmd, "note", "--file", target, "--line", "42", "off by one here"); err != nil {
+		t.Fatalf("note: %v", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(s.Annotations))
+	}
+	a := s.Annotations[0]
+	if a.Path != target || a.Line != 42 {
+		t.Errorf("anchor = %s:%d, want %s:42", a.Path, a.Line, target)
+	}
+	if got := a.Location(tmp); got != "main.go:42" {
+		t.Errorf("Location = %q, want %q", got, "main.go:42")
+	}
+}
+
+// TestNoteLevel verifies that --level is validated and stored, with the
+// default info level left out of the session file.
+func TestNoteLevel(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Cleanup(func() { noteLevel = session.LevelInfo })
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := store.Save(&session.Session{ID: "test-id", StartTime: time.Now(), WorkDir: tmp}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "note", "--level", "urgent", "x"); err == nil || !strings.Contains(err.Error(), "invalid --level") {
+		t.Errorf("expected an invalid level error, got %v", err)
+	}
+	if _, err := executeCommand(rootCmd, "note", "--level", "info", "plain"); err != nil {
+		t.Fatalf("note: %v", err)
+	}
+	if _, err := executeCommand(rootCmd, "note", "--level", "blocker", "db migration pending"); err != nil {
+		t.Fatalf("note: %v", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Annotations) != 2 {
+		t.Fatalf("expected 2 annotations, got %d", len(s.Annotations))
+	}
+	if s.Annotations[0].Level != "" || s.Annotations[0].IsBlocker() {
+		t.Errorf("info note: level %q", s.Annotations[0].Level)
+	}
+	if !s.Annotations[1].IsBlocker() {
+		t.Errorf("blocker note: level %q", s.Annotations[1].Level)
+	}
+}
+
+// TestNoteWithLog verifies that --log attaches the last --log-lines lines of
+// the log file to the note.
+func TestNoteWithLog(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Cleanup(func() { noteLog, noteLogLines = "", 50 })
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := store.Save(&session.Session{ID: "test-id", StartTime: time.Now(), WorkDir: tmp}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	logPath := filepath.Join(tmp, "test.log")
+	var log strings.Builder
+	for i := 1; i <= 100; i++ {
+		fmt.Fprintf(&log, "line %d\n", i)
+	}
+	if err := os.WriteFile(logPath, []byte(log.String()), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "note", "--log", logPath, "--log-lines", "3", "tests failing"); err != nil {
+		t.Fatalf("note --log: %v", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Annotations) != 1 {
+		t.Fatalf("expected 1 annotation, got %d", len(s.Annotations))
+	}
+	if got, want := s.Annotations[0].LogExcerpt, "line 98\nline 99\nline 100"; got != want {
+		t.Errorf("LogExcerpt = %q, want %q", got, want)
+	}
+}
+
+// TestNoteFromClipboardMessageWins verifies that a positional message takes
+// precedence over --from-clipboard, and that a note needs one or the other.
+func TestNoteFromClipboardMessageWins(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Cleanup(func() { noteFromClipboard = false })
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := store.Save(&session.Session{ID: "test-id", StartTime: time.Now(), WorkDir: tmp}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "note", "--from-clipboard", "typed message"); err != nil {
+		t.Fatalf("note --from-clipboard: %v", err)
+	}
+	noteFromClipboard = false
+	if _, err := executeCommand(rootCmd, "note"); err == nil || !strings.Contains(err.Error(), "message is required") {
+		t.Errorf("note without a message: got %v, want a missing message error", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Annotations) != 1 || s.Annotations[0].Message != "typed message" {
+		t.Errorf("annotations = %+v, want one with the typed message", s.Annotations)
+	}
+}
+
+// TestTailLinesLargeFile verifies that only the end of a large log is read
+// and a line cut by the seek is dropped.
+func TestTailLinesLargeFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.log")
+	data := strings.Repeat("x", maxLogExcerptBytes) + "\nlast line\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := tailLines(path, 50)
+	if err != nil {
+		t.Fatalf("tailLines: %v", err)
+	}
+	if got != "last line" {
+		t.Errorf("tailLines = %q, want %q", got, "last line")
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/tui"
+)
+
+var openFormat string
+
+var openCmd = &cobra.Command{
+	Use:   "open",
+	Short: "View the most recent context bundle in the output directory",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		dir := cfg.OutputDir
+		if dir == "" {
+			dir = "."
+		}
+
+		path, b, err := latestBundle(dir, openFormat)
+		if err != nil {
+			return err
+		}
+		loc, err := resolveTimeZone()
+		if err != nil {
+			return err
+		}
+		viewLoc = loc
+
+		if plainOutput {
+			printBundle(b)
+			return nil
+		}
+		theme, err := resolveTheme()
+		if err != nil {
+			return err
+		}
+		save, signed, err := bundleSaver(path, b)
+		if err != nil {
+			return err
+		}
+		return tui.Run(b, path, tui.Options{Theme: theme, ClusterWindow: clusterWindow(), Location: loc,
+			Save: save, Signed: signed})
+	},
+}
+
+func init() {
+	openCmd.Flags().BoolVar(&plainOutput, "plain", false, "plain text output instead of TUI")
+	openCmd.Flags().StringVar(&viewTheme, "theme", "", "color theme: dark, light or nocolor")
+	openCmd.Flags().StringVar(&viewTZ, "tz", "", "show times in this zone: an IANA name, local or utc")
+	openCmd.Flags().StringVar(&openFormat, "format", "", "only consider bundles of this format: markdown or json")
+	rootCmd.AddCommand(openCmd)
+}
@@ -0,0 +1,100 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// setupOpenDir isolates config and returns a temp output directory.
+func setupOpenDir(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	cfgDir := filepath.Join(tmp, ".config", "handoff")
+	if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgJSON := fmt.Sprintf(`{"output_dir": %q}`, tmp)
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(cfgJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return tmp
+}
+
+// writeOpenBundle renders a bundle for workDir into dir/name and sets its
+// modification time to mtime.
+func writeOpenBundle(t *testing.T, dir, name, workDir string, mtime time.Time) {
+	t.Helper()
+	b := &bundle.ContextBundle{Session: bundle.SessionMeta{ID: name, WorkDir: workDir}}
+	var r bundle.BundleRenderer = &bundle.MarkdownRenderer{}
+	if strings.HasSuffix(name, ".json") {
+		r = &bundle.JSONRenderer{}
+	}
+	data, err := r.Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, mtime, mtime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestOpenLatestBundle verifies that "open" picks the newest valid bundle,
+// skipping unrelated files, and that --format restricts the candidates.
+func TestOpenLatestBundle(t *testing.T) {
+	dir := setupOpenDir(t)
+	now := time.Now()
+	writeOpenBundle(t, dir, "handoff-old.json", "/work/json", now.Add(-time.Hour))
+	writeOpenBundle(t, dir, "handoff-new.md", "/work/markdown", now.Add(-time.Minute))
+	if err := os.WriteFile(filepath.Join(dir, "README.md"), []byte("# not a bundle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { plainOutput, openFormat = false, "" })
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"open", "--plain"}, "/work/markdown"},
+		{[]string{"open", "--plain", "--format", "json"}, "/work/json"},
+	}
+	for _, tt := range tests {
+		rootCmd.ResetFlags()
+		openFormat = ""
+		var runErr error
+		stdout := captureStdout(t, func() {
+			_, runErr = executeCommand(rootCmd, tt.args...)
+		})
+		if runErr != nil {
+			t.Fatalf("%v: %v", tt.args, runErr)
+		}
+		if !strings.Contains(stdout, "Work dir:  "+tt.want) {
+			t.Errorf("%v: expected bundle for %s, got:\n%s", tt.args, tt.want, stdout)
+		}
+	}
+}
+
+// TestOpenNoBundle verifies that "open" errors when the output directory has
+// no bundles.
+func TestOpenNoBundle(t *testing.T) {
+	dir := setupOpenDir(t)
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "open", "--plain")
+	plainOutput = false
+	if err == nil || !strings.Contains(err.Error(), "no handoff bundle found in "+dir) {
+		t.Errorf("expected a no-bundle error, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// passphraseEnv names the environment variable that supplies the passphrase
+// for encrypted bundles without prompting.
+const passphraseEnv = "HANDOFF_PASSPHRASE"
+
+// typedPassphrase is the last passphrase typed at the terminal, so writing
+// back a bundle that was just decrypted doesn't ask for it again.
+var typedPassphrase string
+
+// bundlePassphrase returns the passphrase for encrypting or decrypting a
+// bundle: $HANDOFF_PASSPHRASE if set, else one typed at the terminal. With
+// confirm (when encrypting) it is asked for twice, so a typo can't lock the
+// bundle away.
+func bundlePassphrase(confirm bool) (string, error) {
+	if p := os.Getenv(passphraseEnv); p != "" {
+		return p, nil
+	}
+	if !confirm && typedPassphrase != "" {
+		return typedPassphrase, nil
+	}
+	if !term.IsTerminal(os.Stdin.Fd()) {
+		return "", fmt.Errorf("no passphrase: set $%s or run in a terminal to be asked for one", passphraseEnv)
+	}
+	p, err := readPassphrase("Passphrase: ")
+	if err != nil {
+		return "", err
+	}
+	if p == "" {
+		return "", errors.New("empty passphrase")
+	}
+	if confirm {
+		again, err := readPassphrase("Repeat passphrase: ")
+		if err != nil {
+			return "", err
+		}
+		if again != p {
+			return "", errors.New("passphrases do not match")
+		}
+	}
+	typedPassphrase = p
+	return p, nil
+}
+
+// readPassphrase prompts on stderr and reads a line from the terminal
+// without echoing it.
+func readPassphrase(prompt string) (string, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	p, err := term.ReadPassword(os.Stdin.Fd())
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(p), nil
+}
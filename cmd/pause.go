@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+var pauseCmd = &cobra.Command{
+	Use:   "pause",
+	Short: "Pause the current tracking session",
+	Long: "Pause the current tracking session.\n\n" +
+		"Paused time is excluded from the session duration, and commands run while " +
+		"paused are left out of the bundle. Resume with 'handoff unpause'.",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		err := updateActiveSession(func(s *session.Session) error {
+			if s.IsPaused() {
+				return fmt.Errorf("session already paused")
+			}
+			s.PausedIntervals = append(s.PausedIntervals, session.PauseInterval{Start: time.Now()})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Println("Session paused.")
+		return nil
+	},
+}
+
+var unpauseCmd = &cobra.Command{
+	Use:   "unpause",
+	Short: "Resume a paused tracking session",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		now := time.Now()
+		var last session.PauseInterval
+		err := updateActiveSession(func(s *session.Session) error {
+			if !s.IsPaused() {
+				return fmt.Errorf("session is not paused")
+			}
+			s.PausedIntervals[len(s.PausedIntervals)-1].End = now
+			last = s.PausedIntervals[len(s.PausedIntervals)-1]
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Session resumed (paused for %s).\n", now.Sub(last.Start).Round(time.Second))
+		return nil
+	},
+}
+
+// updateActiveSession applies fn to the session selected by --name under the
+// store's lock (see session.SessionStore), mapping ErrNoSession to the
+// user-facing "no active session" error.
+func updateActiveSession(fn func(*session.Session) error) error {
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	if err := store.Update(fn); err != nil {
+		if errors.Is(err, session.ErrNoSession) {
+			return noActiveSessionError()
+		}
+		return err
+	}
+	return nil
+}
+
+func init() {
+	addSessionNameFlag(pauseCmd)
+	addSessionNameFlag(unpauseCmd)
+	rootCmd.AddCommand(pauseCmd)
+	rootCmd.AddCommand(unpauseCmd)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestPauseUnpauseRecordsInterval verifies that pause followed by unpause
+// records a single closed interval, and that double-pause is rejected.
+func TestPauseUnpauseRecordsInterval(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := store.Save(&session.Session{ID: "test-id", StartTime: time.Now(), WorkDir: tmp}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "pause"); err != nil {
+		t.Fatalf("pause: %v", err)
+	}
+
+	out, err := executeCommand(rootCmd, "pause")
+	if err == nil {
+		t.Fatal("expected an error from double pause, got nil")
+	}
+	if combined := out + err.Error(); !strings.Contains(combined, "already paused") {
+		t.Errorf("expected error to contain %q, got: %q", "already paused", combined)
+	}
+
+	out, err = executeCommand(rootCmd, "status")
+	if err != nil {
+		t.Fatalf("status: %v", err)
+	}
+	if !strings.Contains(out, "Paused: since") {
+		t.Errorf("expected status to report the pause, got:\n%s", out)
+	}
+
+	if _, err := executeCommand(rootCmd, "unpause"); err != nil {
+		t.Fatalf("unpause: %v", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.PausedIntervals) != 1 {
+		t.Fatalf("expected 1 paused interval, got %d", len(s.PausedIntervals))
+	}
+	if s.IsPaused() {
+		t.Error("expected session to be unpaused")
+	}
+	if s.PausedIntervals[0].End.Before(s.PausedIntervals[0].Start) {
+		t.Errorf("interval ends before it starts: %+v", s.PausedIntervals[0])
+	}
+}
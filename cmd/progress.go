@@ -0,0 +1,93 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/x/term"
+)
+
+// spinnerFrames are drawn in turn, one per spinnerInterval.
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+const spinnerInterval = 100 * time.Millisecond
+
+// progressEnabled reports whether a spinner would be seen: stderr is a
+// terminal. A var so tests can turn it on.
+var progressEnabled = func() bool {
+	return term.IsTerminal(os.Stderr.Fd())
+}
+
+// progress draws a spinner and a status line on stderr, redrawn in place,
+// for steps that can take a while. A nil *progress does nothing, so callers
+// needn't check whether it was started.
+type progress struct {
+	mu      sync.Mutex
+	label   string
+	changed chan struct{} // redraw now rather than at the next tick
+	done    chan struct{}
+	stopped chan struct{}
+	once    sync.Once
+}
+
+// startProgress starts a spinner showing label, or returns nil when
+// progress is off.
+func startProgress(label string) *progress {
+	if !progressEnabled() {
+		return nil
+	}
+	p := &progress{
+		label:   label,
+		changed: make(chan struct{}, 1),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go p.run()
+	return p
+}
+
+// Set changes the status line.
+func (p *progress) Set(label string) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	p.label = label
+	p.mu.Unlock()
+	select {
+	case p.changed <- struct{}{}:
+	default:
+	}
+}
+
+// Stop erases the spinner. It is safe to call more than once.
+func (p *progress) Stop() {
+	if p == nil {
+		return
+	}
+	p.once.Do(func() {
+		close(p.done)
+		<-p.stopped
+	})
+}
+
+func (p *progress) run() {
+	defer close(p.stopped)
+	ticker := time.NewTicker(spinnerInterval)
+	defer ticker.Stop()
+	for i := 0; ; i++ {
+		p.mu.Lock()
+		label := p.label
+		p.mu.Unlock()
+		fmt.Fprintf(os.Stderr, "\r\033[K%s %s", spinnerFrames[i%len(spinnerFrames)], label)
+		select {
+		case <-p.done:
+			fmt.Fprint(os.Stderr, "\r\033[K")
+			return
+		case <-ticker.C:
+		case <-p.changed:
+		}
+	}
+}
@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/tui"
+)
+
+var replayCmd = &cobra.Command{
+	Use:   "replay <file>",
+	Short: "Step through a bundle's timeline one event at a time",
+	Long: "Step through a bundle's timeline one event at a time, oldest first.\n\n" +
+		"space/enter steps to the next event (r reverses the direction), ←/→ step " +
+		"back and forth, and typing a number followed by enter jumps to that event. " +
+		"File edits show their diff.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := readBundle(args[0])
+		if err != nil {
+			return err
+		}
+		theme, err := resolveTheme()
+		if err != nil {
+			return err
+		}
+		return tui.RunReplay(b, args[0], theme)
+	},
+}
+
+func init() {
+	replayCmd.Flags().StringVar(&viewTheme, "theme", "", "color theme: dark, light or nocolor")
+	rootCmd.AddCommand(replayCmd)
+}
@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+var restoreTmuxSession string
+var restoreTmuxPrint bool
+
+// shellCommands are pane programs that are just a prompt; restore-tmux
+// doesn't retype them.
+var shellCommands = map[string]bool{
+	"bash": true, "zsh": true, "fish": true, "sh": true, "dash": true,
+	"ksh": true, "tcsh": true, "pwsh": true, "powershell": true, "nu": true,
+}
+
+var restoreTmuxCmd = &cobra.Command{
+	Use:   "restore-tmux <bundle>",
+	Short: "Recreate the tmux session layout recorded in a context bundle",
+	Long: "Recreate the tmux windows and panes recorded in a context bundle, each in\n" +
+		"its original directory and with the original layout. The program that was\n" +
+		"running in a pane is typed at its prompt but not run, so you can check it\n" +
+		"first and press Enter.\n\n" +
+		"The session keeps its recorded name unless --session gives another one.\n" +
+		"With --print, the tmux commands are printed instead of run.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := readBundle(args[0])
+		if err != nil {
+			return err
+		}
+		if b.Tmux == nil || len(b.Tmux.Windows) == 0 {
+			return fmt.Errorf("%s has no tmux layout (the session wasn't run inside tmux)", args[0])
+		}
+		name := restoreTmuxSession
+		if name == "" {
+			name = b.Tmux.Session
+		}
+		if name == "" {
+			return errors.New("the bundle's tmux session has no name; use --session")
+		}
+
+		commands := tmuxRestoreCommands(b.Tmux, name)
+		if restoreTmuxPrint {
+			for _, args := range commands {
+				fmt.Println(shellJoin(append([]string{"tmux"}, args...)))
+			}
+			return nil
+		}
+		if _, err := exec.LookPath("tmux"); err != nil {
+			return errors.New("tmux is not installed")
+		}
+		for _, args := range commands {
+			c := exec.Command("tmux", args...)
+			c.Stderr = os.Stderr
+			if err := c.Run(); err != nil {
+				return fmt.Errorf("tmux %s: %w", args[0], err)
+			}
+		}
+
+		attach := "attach"
+		if os.Getenv("TMUX") != "" {
+			attach = "switch-client"
+		}
+		fmt.Printf("Restored tmux session %q. Join it with: tmux %s -t %s\n", name, attach, shellJoin([]string{name}))
+		return nil
+	},
+}
+
+// tmuxRestoreCommands returns the tmux invocations (without "tmux") that
+// recreate layout as a detached session called name. Each new window or
+// pane becomes the current one, so later commands target "=name:" (the
+// session's current window) rather than indexes, which depend on the
+// user's base-index settings.
+func tmuxRestoreCommands(layout *bundle.TmuxLayout, name string) [][]string {
+	target := "=" + name + ":"
+	var commands [][]string
+	active := ""
+	for i, w := range layout.Windows {
+		dir := ""
+		if len(w.Panes) > 0 {
+			dir = w.Panes[0].Dir
+		}
+		if i == 0 {
+			commands = append(commands, withDir([]string{"new-session", "-d", "-s", name, "-n", w.Name}, dir))
+		} else {
+			commands = append(commands, withDir([]string{"new-window", "-t", target, "-n", w.Name}, dir))
+		}
+		for j, p := range w.Panes {
+			if j > 0 {
+				commands = append(commands, withDir([]string{"split-window", "-t", target}, p.Dir))
+				// Even out the panes so the next split has room.
+				commands = append(commands, []string{"select-layout", "-t", target, "tiled"})
+			}
+			if p.Command != "" && !shellCommands[strings.TrimSuffix(filepath.Base(p.Command), ".exe")] {
+				commands = append(commands, []string{"send-keys", "-t", target, "-l", p.Command})
+			}
+		}
+		if w.Layout != "" {
+			commands = append(commands, []string{"select-layout", "-t", target, w.Layout})
+		}
+		if w.Active {
+			active = w.Name
+		}
+	}
+	if active != "" {
+		commands = append(commands, []string{"select-window", "-t", target + active})
+	}
+	return commands
+}
+
+// withDir appends a -c start directory to a tmux command when dir is set.
+func withDir(args []string, dir string) []string {
+	if dir == "" {
+		return args
+	}
+	return append(args, "-c", dir)
+}
+
+// shellJoin quotes args for pasting into a POSIX shell.
+func shellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, a := range args {
+		if a != "" && strings.Trim(a, "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789-_./=:,@+") == "" {
+			quoted[i] = a
+		} else {
+			quoted[i] = "'" + strings.ReplaceAll(a, "'", `'\''`) + "'"
+		}
+	}
+	return strings.Join(quoted, " ")
+}
+
+func init() {
+	restoreTmuxCmd.Flags().StringVar(&restoreTmuxSession, "session", "", "name for the new tmux session (default: the recorded name)")
+	restoreTmuxCmd.Flags().BoolVar(&restoreTmuxPrint, "print", false, "print the tmux commands instead of running them")
+	rootCmd.AddCommand(restoreTmuxCmd)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// TestRestoreTmuxPrint verifies the tmux commands printed for a recorded
+// layout: shells aren't retyped, other programs are typed but not run.
+func TestRestoreTmuxPrint(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Cleanup(func() { restoreTmuxPrint, restoreTmuxSession = false, "" })
+
+	data, err := (&bundle.JSONRenderer{}).Render(&bundle.ContextBundle{
+		Tmux: &bundle.TmuxLayout{Session: "work", Windows: []bundle.TmuxWindow{
+			{Index: 1, Name: "editor", Layout: "b25f,200x50", Panes: []bundle.TmuxPane{
+				{Command: "nvim", Dir: "/work/app"},
+				{Index: 1, Command: "zsh", Dir: "/work/my app"},
+			}},
+			{Index: 2, Name: "logs", Active: true, Panes: []bundle.TmuxPane{{Command: "tail", Dir: "/var/log"}}},
+		}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmp, "bundle.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "restore-tmux", "--print", "--session", "copy", path)
+	})
+	if runErr != nil {
+		t.Fatalf("restore-tmux: %v", runErr)
+	}
+	want := strings.Join([]string{
+		"tmux new-session -d -s copy -n editor -c /work/app",
+		"tmux send-keys -t =copy: -l nvim",
+		"tmux split-window -t =copy: -c '/work/my app'",
+		"tmux select-layout -t =copy: tiled",
+		"tmux select-layout -t =copy: b25f,200x50",
+		"tmux new-window -t =copy: -n logs -c /var/log",
+		"tmux send-keys -t =copy: -l tail",
+		"tmux select-window -t =copy:logs",
+	}, "\n") + "\n"
+	if stdout != want {
+		t.Errorf("got:\n%s\nwant:\n%s", stdout, want)
+	}
+}
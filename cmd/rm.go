@@ -0,0 +1,169 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+var rmForce bool
+var pruneOlderThan string
+var pruneKeep int
+var pruneForce bool
+
+var rmCmd = &cobra.Command{
+	Use:   "rm <bundle>...",
+	Short: "Delete context bundle files",
+	Long: "Delete context bundle files. Each file is parsed first, so anything that\n" +
+		"isn't a handoff bundle is refused rather than deleted.\n\n" +
+		"Asks for confirmation unless --force is given.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		for _, path := range args {
+			if _, _, err := readBundleHeader(path); err != nil {
+				return fmt.Errorf("%s is not a handoff bundle, refusing to delete it: %w", path, err)
+			}
+		}
+		return removeBundles(cmd, args, rmForce)
+	},
+}
+
+var pruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Delete old context bundles from the output directory",
+	Long: "Delete old context bundles from the output directory, by session stop time.\n\n" +
+		"--older-than removes bundles that stopped longer ago than the given age\n" +
+		"(e.g. 30d, 2w, 12h); --keep keeps that many of the newest bundles. Given\n" +
+		"both, a bundle is only removed when it is older and not among the newest.\n" +
+		"Files that aren't handoff bundles are never touched.\n\n" +
+		"Asks for confirmation unless --force is given.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if pruneOlderThan == "" && !cmd.Flags().Changed("keep") {
+			return errors.New("--older-than or --keep is required")
+		}
+		if pruneKeep < 0 {
+			return errors.New("--keep must not be negative")
+		}
+		var cutoff time.Time
+		if pruneOlderThan != "" {
+			age, err := parseAge(pruneOlderThan)
+			if err != nil {
+				return err
+			}
+			cutoff = time.Now().Add(-age)
+		}
+
+		dir := GetConfig().OutputDir
+		if dir == "" {
+			dir = "."
+		}
+		paths, err := findBundles(dir, "")
+		if err != nil {
+			return err
+		}
+
+		type candidate struct {
+			path string
+			stop time.Time
+		}
+		var bundles []candidate
+		for _, path := range paths {
+			meta, _, err := readBundleHeader(path)
+			if err != nil {
+				continue // not a bundle
+			}
+			bundles = append(bundles, candidate{path, meta.StopTime})
+		}
+		sort.SliceStable(bundles, func(i, j int) bool { return bundles[i].stop.After(bundles[j].stop) })
+
+		var doomed []string
+		for i, b := range bundles {
+			if cmd.Flags().Changed("keep") && i < pruneKeep {
+				continue
+			}
+			if !cutoff.IsZero() && !b.stop.Before(cutoff) {
+				continue
+			}
+			doomed = append(doomed, b.path)
+		}
+		if len(doomed) == 0 {
+			fmt.Printf("no bundles to prune in %s\n", dir)
+			return nil
+		}
+		return removeBundles(cmd, doomed, pruneForce)
+	},
+}
+
+// removeBundles deletes paths after listing them and asking for
+// confirmation on stdin, unless force is set.
+func removeBundles(cmd *cobra.Command, paths []string, force bool) error {
+	if !force {
+		for _, path := range paths {
+			fmt.Printf("  %s\n", path)
+		}
+		ok, err := confirm(cmd.InOrStdin(), fmt.Sprintf("Delete %d bundle(s)?", len(paths)))
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		fmt.Printf("Deleted %s\n", filepath.Base(path))
+	}
+	return nil
+}
+
+// confirm asks a yes/no question on r, defaulting to no. End of input
+// counts as no, so nothing is deleted when stdin isn't interactive.
+func confirm(r io.Reader, question string) (bool, error) {
+	fmt.Printf("%s [y/N]: ", question)
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return false, err
+	}
+	answer := strings.ToLower(strings.TrimSpace(line))
+	return answer == "y" || answer == "yes", nil
+}
+
+// parseAge parses an age such as "30d" or "2w", as well as anything
+// time.ParseDuration accepts.
+func parseAge(s string) (time.Duration, error) {
+	units := map[byte]time.Duration{'d': 24 * time.Hour, 'w': 7 * 24 * time.Hour}
+	if n := len(s); n > 1 {
+		if unit, ok := units[s[n-1]]; ok {
+			if count, err := strconv.Atoi(s[:n-1]); err == nil && count >= 0 {
+				return time.Duration(count) * unit, nil
+			}
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q (e.g. 30d, 2w or 12h)", s)
+	}
+	return d, nil
+}
+
+func init() {
+	rmCmd.Flags().BoolVarP(&rmForce, "force", "f", false, "delete without asking for confirmation")
+	pruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "", "remove bundles that stopped longer ago than this (e.g. 30d)")
+	pruneCmd.Flags().IntVar(&pruneKeep, "keep", 0, "keep this many of the newest bundles")
+	pruneCmd.Flags().BoolVarP(&pruneForce, "force", "f", false, "delete without asking for confirmation")
+	rootCmd.AddCommand(rmCmd)
+	rootCmd.AddCommand(pruneCmd)
+}
@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// writeStoppedBundle writes a JSON bundle for a session that stopped at stop.
+func writeStoppedBundle(t *testing.T, dir, name string, stop time.Time) string {
+	t.Helper()
+	data, err := (&bundle.JSONRenderer{}).Render(&bundle.ContextBundle{
+		Session: bundle.SessionMeta{ID: name, StopTime: stop},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestRmRefusesNonBundles verifies that rm validates every file before
+// deleting any, and honours the confirmation prompt.
+func TestRmRefusesNonBundles(t *testing.T) {
+	dir := setupOpenDir(t)
+	t.Cleanup(func() { rmForce = false; rootCmd.SetIn(nil) })
+	b := writeStoppedBundle(t, dir, "handoff-a.json", time.Now())
+	other := filepath.Join(dir, "notes.json")
+	if err := os.WriteFile(other, []byte(`["not a bundle"]`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "rm", "--force", b, other) })
+	if runErr == nil || !strings.Contains(runErr.Error(), "not a handoff bundle") {
+		t.Fatalf("expected a refusal, got %v", runErr)
+	}
+	if _, err := os.Stat(b); err != nil {
+		t.Errorf("bundle deleted despite the refusal: %v", err)
+	}
+
+	rootCmd.SetIn(strings.NewReader("n\n"))
+	rmForce = false
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "rm", b) })
+	if runErr != nil {
+		t.Fatalf("rm: %v", runErr)
+	}
+	if _, err := os.Stat(b); err != nil {
+		t.Errorf("bundle deleted without confirmation: %v", err)
+	}
+
+	rootCmd.SetIn(strings.NewReader("y\n"))
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "rm", b) })
+	if runErr != nil {
+		t.Fatalf("rm: %v", runErr)
+	}
+	if _, err := os.Stat(b); !os.IsNotExist(err) {
+		t.Errorf("expected the bundle to be deleted, Stat returned %v", err)
+	}
+}
+
+// TestPrune verifies that --keep and --older-than select bundles by stop
+// time and that both must match for a bundle to be removed.
+func TestPrune(t *testing.T) {
+	dir := setupOpenDir(t)
+	t.Cleanup(func() {
+		pruneOlderThan, pruneKeep, pruneForce = "", 0, false
+		pruneCmd.Flags().Lookup("keep").Changed = false
+	})
+	now := time.Now()
+	paths := []string{
+		writeStoppedBundle(t, dir, "handoff-1.json", now.Add(-1*time.Hour)),
+		writeStoppedBundle(t, dir, "handoff-2.json", now.Add(-48*time.Hour)),
+		writeStoppedBundle(t, dir, "handoff-3.json", now.Add(-72*time.Hour)),
+		writeStoppedBundle(t, dir, "handoff-4.json", now.Add(-96*time.Hour)),
+	}
+	keepFile := filepath.Join(dir, "keep.md")
+	if err := os.WriteFile(keepFile, []byte("# not a bundle\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "prune", "--force"); err == nil {
+		t.Error("expected an error without --older-than or --keep")
+	}
+
+	var runErr error
+	captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "prune", "--force", "--older-than", "1d", "--keep", "2")
+	})
+	if runErr != nil {
+		t.Fatalf("prune: %v", runErr)
+	}
+	for i, path := range paths {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != (i < 2) {
+			t.Errorf("%s: exists = %v, want %v", filepath.Base(path), exists, i < 2)
+		}
+	}
+	if _, err := os.Stat(keepFile); err != nil {
+		t.Errorf("non-bundle file was removed: %v", err)
+	}
+}
+
+// TestParseAge verifies day and week suffixes on top of Go durations.
+func TestParseAge(t *testing.T) {
+	for in, want := range map[string]time.Duration{
+		"30d": 30 * 24 * time.Hour,
+		"2w":  14 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+	} {
+		if got, err := parseAge(in); err != nil || got != want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "d", "-3d", "soon"} {
+		if _, err := parseAge(in); err == nil {
+			t.Errorf("parseAge(%q): expected an error", in)
+		}
+	}
+}
@@ -2,12 +2,15 @@ package cmd
 
 import (
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 
 	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 	"github.com/fakeyudi/handoff/internal/config"
 	"github.com/fakeyudi/handoff/internal/profile"
+	"github.com/fakeyudi/handoff/internal/session"
 )
 
 // cfg holds the merged configuration, populated in PersistentPreRunE.
@@ -16,12 +19,33 @@ var cfg config.Config
 // activeProfile holds the loaded user profile.
 var activeProfile *profile.Profile
 
+// configPath and noProjectConfig are the global --config and
+// --no-project-config flags.
+var (
+	configPath      string
+	noProjectConfig bool
+)
+
+// verbose is the global --verbose flag; logger writes to stderr when it is
+// set and discards everything otherwise.
+var (
+	verbose bool
+	logger  = slog.New(slog.DiscardHandler)
+)
+
+// sessionName is the --name flag shared by the session commands. Empty
+// selects the default session.
+var sessionName string
+
 var rootCmd = &cobra.Command{
 	Use:   "handoff",
 	Short: "Track developer activity and generate shareable context bundles",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// Skip setup check for the setup command itself.
-		if cmd.Name() == "setup" {
+		logger = newLogger(cmd.ErrOrStderr())
+
+		// Skip setup check for the setup command itself, and for init,
+		// which must work even when an existing .handoffconfig is broken.
+		if cmd.Name() == "setup" || cmd.Name() == "init" {
 			return nil
 		}
 
@@ -48,11 +72,11 @@ var rootCmd = &cobra.Command{
 		}
 
 		// Load and merge config files.
-		global, err := config.LoadGlobal()
+		global, err := loadGlobalConfig()
 		if err != nil {
 			return fmt.Errorf("loading global config: %w", err)
 		}
-		project, err := config.LoadProject()
+		project, err := loadProjectConfig()
 		if err != nil {
 			return fmt.Errorf("loading project config: %w", err)
 		}
@@ -74,6 +98,60 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+func init() {
+	addConfigFlags(rootCmd)
+	addVerboseFlag(rootCmd)
+}
+
+// addConfigFlags registers the flags that choose the config files on cmd.
+func addConfigFlags(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVar(&configPath, "config", "",
+		"Read settings from this file instead of ~/.config/handoff/config.json (.handoffconfig still overrides it)")
+	cmd.PersistentFlags().BoolVar(&noProjectConfig, "no-project-config", false,
+		"Ignore .handoffconfig in the current directory")
+}
+
+// addVerboseFlag registers the --verbose flag on cmd.
+func addVerboseFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false,
+		"Log what each collector does to stderr")
+}
+
+// newLogger returns the debug logger for --verbose, writing to w, or one
+// that discards everything when --verbose is off. Times are left out: the
+// lines only ever go to a terminal alongside the command's own output.
+func newLogger(w io.Writer) *slog.Logger {
+	if !verbose {
+		return slog.New(slog.DiscardHandler)
+	}
+	return slog.New(slog.NewTextHandler(w, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// loadGlobalConfig reads the file named by --config, or else the global
+// config file.
+func loadGlobalConfig() (*config.Config, error) {
+	if configPath != "" {
+		return config.LoadFile(configPath)
+	}
+	return config.LoadGlobal()
+}
+
+// loadProjectConfig reads .handoffconfig unless --no-project-config is set.
+func loadProjectConfig() (*config.Config, error) {
+	if noProjectConfig {
+		return nil, nil
+	}
+	return config.LoadProject()
+}
+
 // Execute runs the root command. Exits with code 1 on error.
 func Execute() {
 	if err := rootCmd.Execute(); err != nil {
@@ -90,3 +168,22 @@ func GetConfig() config.Config {
 func GetProfile() *profile.Profile {
 	return activeProfile
 }
+
+// openSessionStore returns the store for the session selected by --name.
+func openSessionStore() (session.SessionStore, error) {
+	return session.NewNamedSessionStore(sessionName)
+}
+
+// noActiveSessionError returns the error reported when the selected session
+// does not exist.
+func noActiveSessionError() error {
+	if sessionName == "" || sessionName == session.DefaultSessionName {
+		return fmt.Errorf("no active session")
+	}
+	return fmt.Errorf("no active session named %q", sessionName)
+}
+
+// addSessionNameFlag registers the shared --name flag on cmd.
+func addSessionNameFlag(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&sessionName, "name", "", "Session name, for running several sessions at once")
+}
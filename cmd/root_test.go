@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestConfigFlag verifies that --config replaces the global config file,
+// that .handoffconfig still applies on top of it unless
+// --no-project-config is given, and that a missing --config file is an
+// error.
+func TestConfigFlag(t *testing.T) {
+	t.Cleanup(func() { configPath, noProjectConfig = "", false })
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("XDG_DATA_HOME", tmp)
+
+	ci := filepath.Join(tmp, "ci.json")
+	if err := os.WriteFile(ci, []byte(`{"default_format": "json", "git_log_limit": 5}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, ".handoffconfig"), []byte(`{"git_log_limit": 7}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Chdir(project)
+
+	rootCmd.ResetFlags()
+	addConfigFlags(rootCmd)
+	if _, err := executeCommand(rootCmd, "status", "--config", ci); err != nil {
+		t.Fatalf("status --config: %v", err)
+	}
+	if cfg.DefaultFormat != "json" || cfg.GitLogLimit != 7 {
+		t.Errorf("with .handoffconfig: got format %q, git_log_limit %d; want json, 7", cfg.DefaultFormat, cfg.GitLogLimit)
+	}
+
+	if _, err := executeCommand(rootCmd, "status", "--config", ci, "--no-project-config"); err != nil {
+		t.Fatalf("status --no-project-config: %v", err)
+	}
+	if cfg.GitLogLimit != 5 {
+		t.Errorf("without .handoffconfig: got git_log_limit %d, want 5", cfg.GitLogLimit)
+	}
+
+	_, err := executeCommand(rootCmd, "status", "--config", filepath.Join(tmp, "missing.json"))
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("expected an error for a missing config file, got %v", err)
+	}
+}
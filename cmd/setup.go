@@ -14,10 +14,56 @@ var setupCmd = &cobra.Command{
 	// Bypass the normal PersistentPreRunE so setup works before profile exists.
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error { return nil },
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if setupAppendHistory {
+			return appendHistory(cmd)
+		}
 		return runSetup(false)
 	},
 }
 
+var setupAppendHistory bool
+var setupYes bool
+
+// appendHistory makes the user's shell save commands to history as they
+// run, the fix for the "shell history was not flushed" warning, without
+// going through the wizard. It shows the line and asks before changing the
+// rc file, unless --yes is given.
+func appendHistory(cmd *cobra.Command) error {
+	sh := shell.Detect()
+	if p, err := profile.Load(); err == nil && p.ShellPluginShell != "" {
+		sh = p.ShellPluginShell
+	}
+	line := shell.HistoryAppendLine(sh)
+	if line == "" {
+		return fmt.Errorf("--append-history supports zsh and bash, not %q", sh)
+	}
+	if present, _ := shell.HasHistoryAppend(sh); present {
+		cmd.Printf("Your %s config already saves history as commands run.\n", sh)
+		return nil
+	}
+	if !setupYes {
+		cmd.Printf("This adds the following line to your %s config:\n  %s\n", sh, line)
+		ok, err := confirm(cmd.InOrStdin(), "Add it?")
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("Aborted.")
+			return nil
+		}
+	}
+	added, err := shell.AppendHistoryLine(sh)
+	if err != nil {
+		return err
+	}
+	if !added {
+		cmd.Printf("Your %s config already saves history as commands run.\n", sh)
+		return nil
+	}
+	cmd.Printf("Added %s to your %s config. Open a new shell for it to take effect.\n", line, sh)
+	return nil
+}
+
 // runSetup runs the interactive setup wizard.
 // If firstRun is true, a welcome message is shown.
 func runSetup(firstRun bool) error {
@@ -59,5 +105,7 @@ func runSetup(firstRun bool) error {
 }
 
 func init() {
+	setupCmd.Flags().BoolVar(&setupAppendHistory, "append-history", false, "Only add the line that makes your shell save commands to history as they run")
+	setupCmd.Flags().BoolVarP(&setupYes, "yes", "y", false, "With --append-history, add the line without asking")
 	rootCmd.AddCommand(setupCmd)
 }
@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/shell"
+)
+
+// TestSetupAppendHistory verifies that "setup --append-history" asks before
+// adding the history line to the rc file (unless --yes is given), adds it
+// once, and leaves an rc file that already has an equivalent setting alone.
+func TestSetupAppendHistory(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("HOME", tmp)
+	t.Setenv("ZDOTDIR", "")
+	t.Cleanup(func() { setupAppendHistory, setupYes = false, false; rootCmd.SetIn(nil) })
+
+	t.Setenv("SHELL", "/bin/bash")
+	bashrc := filepath.Join(tmp, ".bashrc")
+	if err := os.WriteFile(bashrc, []byte("alias ll='ls -l'"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.ResetFlags()
+	rootCmd.SetIn(strings.NewReader("n\n"))
+	if _, err := executeCommand(rootCmd, "setup", "--append-history"); err != nil {
+		t.Fatalf("setup --append-history: %v", err)
+	}
+	if data, _ := os.ReadFile(bashrc); strings.Contains(string(data), "history -a") {
+		t.Errorf("expected the rc file unchanged when the answer is no:\n%s", data)
+	}
+
+	if _, err := executeCommand(rootCmd, "setup", "--append-history", "--yes"); err != nil {
+		t.Fatalf("setup --append-history --yes: %v", err)
+	}
+	setupYes = false
+	rootCmd.SetIn(strings.NewReader("y\n"))
+	if _, err := executeCommand(rootCmd, "setup", "--append-history"); err != nil {
+		t.Fatalf("setup --append-history: %v", err)
+	}
+	data, _ := os.ReadFile(bashrc)
+	if n := strings.Count(string(data), "history -a"); n != 1 {
+		t.Errorf("expected the history line once, got %d times:\n%s", n, data)
+	}
+	if !strings.HasPrefix(string(data), "alias ll='ls -l'\n") {
+		t.Errorf("expected the line on a new line after the existing config:\n%s", data)
+	}
+
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	zshrc := filepath.Join(tmp, ".zshrc")
+	orig := "setopt share_history\n"
+	if err := os.WriteFile(zshrc, []byte(orig), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "setup", "--append-history"); err != nil {
+		t.Fatalf("setup --append-history: %v", err)
+	}
+	if data, _ := os.ReadFile(zshrc); string(data) != orig {
+		t.Errorf("expected SHARE_HISTORY to count as set, got:\n%s", data)
+	}
+
+	t.Setenv("SHELL", "/usr/bin/fish")
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "setup", "--append-history"); err == nil {
+		t.Error("expected an error for an unsupported shell")
+	}
+}
+
+// TestBashPluginSkipsPromptCommand verifies that the bash plugin logs the
+// commands typed at an interactive shell, but neither those PROMPT_COMMAND
+// runs nor the rc file's own, whether the history line comes before or after
+// the plugin in it.
+func TestBashPluginSkipsPromptCommand(t *testing.T) {
+	history := shell.HistoryAppendLine("bash")
+	for name, rc := range map[string]string{
+		"history first": history + "\nsource ~/handoff.plugin.bash",
+		"plugin first":  "source ~/handoff.plugin.bash\n" + history,
+	} {
+		got := bashPluginLog(t, rc, "echo hi\nfalse\n")
+		if want := []string{"0 echo hi", "1 false"}; strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("%s: logged %q, want %q", name, got, want)
+		}
+	}
+}
+
+// TestBashPluginLogsCommandLines verifies that the bash plugin logs a
+// pipeline or list as one command line with the line's exit status, also
+// when history leaves the line out.
+func TestBashPluginLogsCommandLines(t *testing.T) {
+	rc := "source ~/handoff.plugin.bash\n" + shell.HistoryAppendLine("bash")
+	got := bashPluginLog(t, rc, "false | true\ntrue | false\nfalse; echo x\n echo hidden | cat\n", "HISTCONTROL=ignorespace")
+	want := []string{"0 false | true", "1 true | false", "0 false; echo x", "0 echo hidden"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("logged %q, want %q", got, want)
+	}
+}
+
+// bashPluginLog runs input in an interactive bash with an active session
+// and rc as its rc file, where the plugin is at ~/handoff.plugin.bash, and
+// returns the commands the plugin logged as "<status> <command>". env is
+// added to the shell's environment.
+func bashPluginLog(t *testing.T, rc, input string, env ...string) []string {
+	t.Helper()
+	bash, err := exec.LookPath("bash")
+	if err != nil {
+		t.Skip("bash not installed")
+	}
+	tmp := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(tmp, "handoff"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "handoff", "session.json"), []byte("{}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tmp, "handoff.plugin.bash"), []byte(shell.BashPlugin), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	rcFile := filepath.Join(tmp, "bashrc")
+	if err := os.WriteFile(rcFile, []byte(rc+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := exec.Command(bash, "--rcfile", rcFile, "-i")
+	c.Env = append([]string{"HOME=" + tmp, "XDG_DATA_HOME=" + tmp, "PATH=" + os.Getenv("PATH")}, env...)
+	c.Stdin = strings.NewReader(input + "exit 0\n")
+	if out, err := c.CombinedOutput(); err != nil {
+		t.Fatalf("bash: %v\n%s", err, out)
+	}
+	data, err := os.ReadFile(filepath.Join(tmp, "handoff", "commands.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		got = append(got, fields[1]+" "+fields[2])
+	}
+	return got
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSignAndVerify verifies that a signed Markdown bundle verifies, and that
+// an edit to its visible text under the old signature is caught.
+func TestSignAndVerify(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+	cfgDir := filepath.Join(tmp, ".config", "handoff")
+	if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(tmp, "signing.key")
+	if err := os.WriteFile(keyPath, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfgJSON := fmt.Sprintf(`{"signing_key": %q}`, keyPath)
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(cfgJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	writeOpenBundle(t, tmp, "bundle.md", "/work/sign", time.Now())
+	path := filepath.Join(tmp, "bundle.md")
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "sign", path) })
+	if runErr != nil {
+		t.Fatalf("sign: %v", runErr)
+	}
+	stdout := captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "verify", path) })
+	if runErr != nil || !strings.Contains(stdout, "signature OK") {
+		t.Fatalf("verify: err %v, output %q", runErr, stdout)
+	}
+
+	// Edit only the visible title; the embedded payload is untouched.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tampered := strings.Replace(string(data), "# Handoff — /work/sign", "# Handoff — /work/other", 1)
+	if tampered == string(data) {
+		t.Fatalf("title not found in bundle:\n%s", data)
+	}
+	if err := os.WriteFile(path, []byte(tampered), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "verify", path) })
+	if runErr == nil || !strings.Contains(runErr.Error(), "signature does not match") {
+		t.Errorf("expected a mismatch error, got %v", runErr)
+	}
+
+	// A JSON bundle keeps verifying when reindented, and is signed over
+	// its data rather than this release's decoding of it.
+	writeOpenBundle(t, tmp, "bundle.json", "/work/sign", time.Now())
+	jsonPath := filepath.Join(tmp, "bundle.json")
+	captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "sign", jsonPath) })
+	if runErr != nil {
+		t.Fatalf("sign json: %v", runErr)
+	}
+	data, err = os.ReadFile(jsonPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reindented bytes.Buffer
+	if err := json.Indent(&reindented, data, "", "\t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(jsonPath, reindented.Bytes(), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout = captureStdout(t, func() { _, runErr = executeCommand(rootCmd, "verify", jsonPath) })
+	if runErr != nil || !strings.Contains(stdout, "signature OK") {
+		t.Errorf("verify reindented json: err %v, output %q", runErr, stdout)
+	}
+}
@@ -4,20 +4,29 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
 	"github.com/spf13/cobra"
 	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
-// TODO :- add option for custom name of file as a param (while saving check if same file exists then append a number after that incrementally)
+var startTags []string
+var startWatch bool
+var startResumeLast bool
+
 var startCmd = &cobra.Command{
 	Use:   "start",
 	Short: "Begin a new tracking session",
+	Long: "Begin a new tracking session in the current directory.\n\n" +
+		"With --resume-last, reopen the session of the newest bundle in the output\n" +
+		"directory instead, keeping its start time, work dir, tags, annotations and\n" +
+		"file edits, for when you stopped too early.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		store, err := session.NewSessionStore()
+		store, err := openSessionStore()
 		if err != nil {
 			return err
 		}
@@ -27,9 +36,23 @@ var startCmd = &cobra.Command{
 			return err
 		}
 		if s != nil {
+			if sessionName != "" {
+				return fmt.Errorf("session %q already in progress (started at %s)", sessionName, s.StartTime.Format(time.RFC3339))
+			}
 			return fmt.Errorf("session already in progress (started at %s)", s.StartTime.Format(time.RFC3339))
 		}
 
+		var tags []string
+		for _, t := range startTags {
+			tag, err := normalizeTag(t)
+			if err != nil {
+				return err
+			}
+			if !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+
 		cwd, err := os.Getwd()
 		if err != nil {
 			return err
@@ -46,17 +69,101 @@ var startCmd = &cobra.Command{
 			Annotations:          []session.Annotation{},
 			FileEdits:            []session.FileEdit{},
 			HistoryBaselineCount: baselineCount,
+			Tags:                 tags,
+		}
+		var resumedFrom string
+		if startResumeLast {
+			if resumedFrom, err = resumeLastBundle(newSession); err != nil {
+				return err
+			}
 		}
 
 		if err := store.Save(newSession); err != nil {
 			return err
 		}
 
-		fmt.Println("Session started.")
+		// The watcher records edits as they happen. Without it, stop still
+		// finds changed files by modification time, but only the last edit
+		// of each file is known.
+		watch := config.Enabled(GetConfig().EnableWatcher)
+		if cmd.Flags().Changed("watch") {
+			watch = startWatch
+		}
+		if watch {
+			pid, err := launchWatcher()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "warning: %v; file edits will be found by modification time at stop\n", err)
+			} else if err := store.Update(func(s *session.Session) error {
+				s.WatcherPID = pid
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case resumedFrom != "":
+			fmt.Printf("Resumed the session from %s.\n", resumedFrom)
+		case sessionName != "":
+			fmt.Printf("Session %q started.\n", sessionName)
+		default:
+			fmt.Println("Session started.")
+		}
 		return nil
 	},
 }
 
+// resumeLastBundle turns s, a new session, into the session of the newest
+// bundle in the output directory, and returns that bundle's path. The time
+// since the bundle was written is recorded as a pause, so the session's
+// duration and collected commands leave it out; s keeps its fresh history
+// baseline and any tags given to start, and carries the bundle's commands.
+func resumeLastBundle(s *session.Session) (string, error) {
+	dir := GetConfig().OutputDir
+	if dir == "" {
+		dir = "."
+	}
+	path, b, err := latestBundle(dir, "")
+	if err != nil {
+		return "", fmt.Errorf("no session to resume: %w", err)
+	}
+
+	if b.Session.ID != "" {
+		s.ID = b.Session.ID
+	}
+	if !b.Session.StartTime.IsZero() {
+		s.StartTime = b.Session.StartTime
+	}
+	if b.Session.WorkDir != "" {
+		s.WorkDir = b.Session.WorkDir
+	}
+	if !b.Session.StopTime.IsZero() {
+		s.PausedIntervals = []session.PauseInterval{{Start: b.Session.StopTime, End: time.Now()}}
+	}
+	for _, a := range b.Annotations {
+		// The next stop writes its own summary; the old one stays as a note.
+		a.IsSummary = false
+		s.Annotations = append(s.Annotations, a)
+	}
+	// History is read from a fresh baseline, so the commands from before
+	// stopping come from the bundle.
+	s.Commands = b.Commands
+	for _, fe := range b.FileEdits {
+		// The diff is captured again at stop.
+		s.FileEdits = append(s.FileEdits, session.FileEdit{Path: fe.Path, Timestamp: fe.Timestamp})
+	}
+	tags := s.Tags
+	s.Tags = nil
+	for _, tag := range append(slices.Clone(b.Tags), tags...) {
+		s.AddTag(tag)
+	}
+	return path, nil
+}
+
 func init() {
+	startCmd.Flags().BoolVar(&startResumeLast, "resume-last", false, "Reopen the session of the newest bundle in the output directory")
+	startCmd.Flags().BoolVar(&startWatch, "watch", true, "Run a background file watcher (overrides enable_watcher in config)")
+	startCmd.Flags().StringArrayVar(&startTags, "tag", nil, "Tag the session (repeatable), e.g. --tag bugfix")
+	addSessionNameFlag(startCmd)
 	rootCmd.AddCommand(startCmd)
 }
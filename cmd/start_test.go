@@ -2,14 +2,25 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/bundle"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
+// TestMain stubs out the background watcher so that "start" in tests doesn't
+// spawn copies of the test binary. The stub reports the test process itself.
+func TestMain(m *testing.M) {
+	launchWatcher = func() (int, error) { return os.Getpid(), nil }
+	os.Exit(m.Run())
+}
+
 // executeCommand runs a cobra command with the given args and captures combined output.
 func executeCommand(root *cobra.Command, args ...string) (output string, err error) {
 	buf := new(bytes.Buffer)
@@ -55,3 +66,136 @@ func TestDoubleStartError(t *testing.T) {
 		t.Errorf("expected error to contain %q, got: %q", "session already in progress", combined)
 	}
 }
+
+// TestStartWatcherSwitch verifies that enable_watcher and --watch decide
+// whether start launches the watcher, and that status reports it.
+func TestStartWatcherSwitch(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+	cfgDir := filepath.Join(tmp, ".config", "handoff")
+	if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(`{"enable_watcher": false}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	launched := 0
+	orig := launchWatcher
+	launchWatcher = func() (int, error) { launched++; return os.Getpid(), nil }
+	t.Cleanup(func() {
+		launchWatcher, startWatch = orig, true
+		startCmd.Flags().Lookup("watch").Changed = false
+	})
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	for _, tc := range []struct {
+		args       []string
+		wantPID    int
+		wantStatus string
+	}{
+		{[]string{"start"}, 0, "Watcher: off"},
+		{[]string{"start", "--watch"}, os.Getpid(), fmt.Sprintf("Watcher: running (pid %d)", os.Getpid())},
+	} {
+		rootCmd.ResetFlags()
+		captureStdout(t, func() { _, err = executeCommand(rootCmd, tc.args...) })
+		if err != nil {
+			t.Fatalf("%v: %v", tc.args, err)
+		}
+		s, err := store.Load()
+		if err != nil {
+			t.Fatalf("Load: %v", err)
+		}
+		if s.WatcherPID != tc.wantPID {
+			t.Errorf("%v: watcher pid %d, want %d", tc.args, s.WatcherPID, tc.wantPID)
+		}
+		out, err := executeCommand(rootCmd, "status")
+		if err != nil || !strings.Contains(out, tc.wantStatus) {
+			t.Errorf("%v: status %q (err %v), want %q", tc.args, out, err, tc.wantStatus)
+		}
+		if err := store.Delete(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if launched != 1 {
+		t.Errorf("watcher launched %d times, want 1", launched)
+	}
+}
+
+// TestStartResumeLast verifies that start --resume-last reopens the newest
+// bundle's session with its start time, work dir, annotations, file edits,
+// commands and tags, recording the time since it stopped as a pause, and that it is
+// an error when there is no bundle to resume.
+func TestStartResumeLast(t *testing.T) {
+	tmp := setupOpenDir(t)
+	t.Cleanup(func() { startResumeLast, startTags = false, nil })
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "start", "--resume-last")
+	if err == nil || !strings.Contains(err.Error(), "no session to resume") {
+		t.Fatalf("expected an error without a bundle, got %v", err)
+	}
+
+	started := time.Now().Add(-2 * time.Hour).Truncate(time.Second)
+	stopped := time.Now().Add(-time.Hour).Truncate(time.Second)
+	b := &bundle.ContextBundle{
+		Session:     bundle.SessionMeta{ID: "earlier", StartTime: started, StopTime: stopped, WorkDir: "/work/api"},
+		Tags:        []string{"bugfix"},
+		Annotations: []session.Annotation{
+			{Message: "halfway there", Timestamp: started.Add(time.Minute)},
+			{Message: "stopped too early", Timestamp: stopped, IsSummary: true},
+		},
+		FileEdits:   []session.FileEdit{{Path: "/work/api/main.go", Timestamp: started.Add(2 * time.Minute), Diff: "@@"}},
+		Commands:    []bundle.Command{{Raw: "go test ./...", Timestamp: started.Add(3 * time.Minute)}},
+	}
+	data, err := (&bundle.JSONRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmp, "handoff-earlier.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	out := captureStdout(t, func() {
+		_, err = executeCommand(rootCmd, "start", "--resume-last", "--tag", "followup")
+	})
+	if err != nil {
+		t.Fatalf("start --resume-last: %v", err)
+	}
+	if !strings.Contains(out, "Resumed the session from "+path) {
+		t.Errorf("expected the resumed bundle to be named, got %q", out)
+	}
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if s.ID != "earlier" || !s.StartTime.Equal(started) || s.WorkDir != "/work/api" || s.StopTime != nil {
+		t.Errorf("got id %q, start %v, work dir %q, stop %v", s.ID, s.StartTime, s.WorkDir, s.StopTime)
+	}
+	if len(s.Annotations) != 2 || s.Annotations[0].Message != "halfway there" || s.Annotations[1].Message != "stopped too early" {
+		t.Errorf("annotations not restored: %+v", s.Annotations)
+	} else if s.Annotations[1].IsSummary {
+		t.Errorf("expected the old summary to become a plain note: %+v", s.Annotations[1])
+	}
+	if len(s.FileEdits) != 1 || s.FileEdits[0].Path != "/work/api/main.go" || s.FileEdits[0].Diff != "" {
+		t.Errorf("file edits not restored without diffs: %+v", s.FileEdits)
+	}
+	if len(s.Commands) != 1 || s.Commands[0].Raw != "go test ./..." {
+		t.Errorf("commands not restored: %+v", s.Commands)
+	}
+	if strings.Join(s.Tags, ",") != "bugfix,followup" {
+		t.Errorf("got tags %v, want bugfix,followup", s.Tags)
+	}
+	if len(s.PausedIntervals) != 1 || !s.PausedIntervals[0].Start.Equal(stopped) || s.IsPaused() {
+		t.Errorf("expected the gap since stopping as a closed pause, got %+v", s.PausedIntervals)
+	}
+}
@@ -1,39 +1,228 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
+var statusFollow bool
+var statusFormat string
+
+// statusBoxed is set when --format table output goes to a terminal, which
+// gets the table drawn in a box.
+var statusBoxed bool
+
+// statusTerminal reports whether w is a terminal. Tests replace it.
+var statusTerminal = func(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(f.Fd())
+}
+
+// statusRecentNotes is how many annotations the table lists.
+const statusRecentNotes = 3
+
+// statusFollowInterval is how often `status --follow` redraws.
+const statusFollowInterval = time.Second
+
+// clearScreen moves the cursor home and clears the terminal.
+const clearScreen = "\033[H\033[2J"
+
 var statusCmd = &cobra.Command{
 	Use:   "status",
 	Short: "Show the current tracking session status",
+	Long: "Show the current tracking session status.\n\n" +
+		"With --name, shows that session only. Otherwise every active session is listed.\n" +
+		"With --follow, the status is redrawn every second until Ctrl-C.\n" +
+		"With --format table, a fuller summary is shown, boxed on a terminal.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		store, err := session.NewSessionStore()
+		switch statusFormat {
+		case "", "plain":
+		case "table":
+			statusBoxed = statusTerminal(cmd.OutOrStderr())
+		default:
+			return fmt.Errorf("unsupported --format %q (want plain or table)", statusFormat)
+		}
+		if statusFollow {
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt)
+			defer stop()
+			return followStatus(ctx, cmd, statusFollowInterval)
+		}
+		return showStatus(cmd)
+	},
+}
+
+// followStatus redraws the status every interval until ctx is done. Each
+// frame is rendered off-screen first so the terminal doesn't flicker.
+func followStatus(ctx context.Context, cmd *cobra.Command, interval time.Duration) error {
+	out := cmd.OutOrStderr()
+	defer cmd.SetOut(out)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var frame bytes.Buffer
+		cmd.SetOut(&frame)
+		err := showStatus(cmd)
+		cmd.SetOut(out)
 		if err != nil {
 			return err
 		}
+		io.WriteString(out, clearScreen)
+		frame.WriteTo(out)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
 
+// showStatus prints the status of the --name session, or of every active
+// session.
+func showStatus(cmd *cobra.Command) error {
+	if sessionName != "" {
+		store, err := openSessionStore()
+		if err != nil {
+			return err
+		}
 		s, err := store.Load()
 		if err != nil {
 			if errors.Is(err, session.ErrNoSession) {
-				cmd.Println("no active session")
+				cmd.Println(noActiveSessionError().Error())
 				return nil
 			}
 			return err
 		}
+		printStatus(cmd, s)
+		return nil
+	}
 
-		cmd.Printf("Started: %s\n", s.StartTime.Format(time.RFC3339))
-		cmd.Printf("Duration: %s\n", time.Since(s.StartTime).Round(time.Second).String())
-		cmd.Printf("File edits: %d\n", len(s.FileEdits))
-		cmd.Printf("Annotations: %d\n", len(s.Annotations))
+	names, err := session.ListSessions()
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		cmd.Println("no active session")
 		return nil
-	},
+	}
+
+	for i, name := range names {
+		store, err := session.NewNamedSessionStore(name)
+		if err != nil {
+			return err
+		}
+		s, err := store.Load()
+		if err != nil {
+			if errors.Is(err, session.ErrNoSession) {
+				continue // stopped between listing and loading
+			}
+			return err
+		}
+		if i > 0 {
+			cmd.Println()
+		}
+		// Only label sessions when more than the default one is active,
+		// so single-session output is unchanged.
+		if len(names) > 1 || name != session.DefaultSessionName {
+			cmd.Printf("Session: %s\n", name)
+		}
+		printStatus(cmd, s)
+	}
+	return nil
+}
+
+// printStatus writes the status lines for a single session.
+func printStatus(cmd *cobra.Command, s *session.Session) {
+	if statusFormat == "table" {
+		printStatusTable(cmd, s)
+		return
+	}
+	now := time.Now()
+	cmd.Printf("Started: %s\n", s.StartTime.Format(time.RFC3339))
+	cmd.Printf("Duration: %s\n", (now.Sub(s.StartTime) - s.PausedDuration(now)).Round(time.Second).String())
+	if s.IsPaused() {
+		paused := s.PausedIntervals[len(s.PausedIntervals)-1].Start
+		cmd.Printf("Paused: since %s\n", paused.Format(time.RFC3339))
+	}
+	cmd.Printf("File edits: %d\n", len(s.FileEdits))
+	cmd.Printf("Annotations: %d\n", len(s.Annotations))
+	switch {
+	case s.WatcherPID == 0:
+		cmd.Println("Watcher: off (edits are found by modification time at stop)")
+	case processAlive(s.WatcherPID):
+		cmd.Printf("Watcher: running (pid %d)\n", s.WatcherPID)
+	default:
+		cmd.Printf("Watcher: not running (pid %d exited)\n", s.WatcherPID)
+	}
+}
+
+// printStatusTable writes the --format table summary for a single session:
+// aligned rows, with the last few annotations, in a box when statusBoxed.
+func printStatusTable(cmd *cobra.Command, s *session.Session) {
+	now := time.Now()
+	var rows []string
+	row := func(label, value string) {
+		rows = append(rows, fmt.Sprintf("%-12s %s", label, value))
+	}
+	row("Started", s.StartTime.Format("2006-01-02 15:04:05"))
+	row("Elapsed", (now.Sub(s.StartTime) - s.PausedDuration(now)).Round(time.Second).String())
+	if s.IsPaused() {
+		row("State", "paused since "+s.PausedIntervals[len(s.PausedIntervals)-1].Start.Format("15:04:05"))
+	} else {
+		row("State", "tracking")
+	}
+	row("File edits", fmt.Sprint(len(s.FileEdits)))
+	row("Annotations", fmt.Sprint(len(s.Annotations)))
+	if len(s.Tags) > 0 {
+		row("Tags", strings.Join(s.Tags, ", "))
+	}
+	switch {
+	case s.WatcherPID == 0:
+		row("Watcher", "off")
+	case processAlive(s.WatcherPID):
+		row("Watcher", fmt.Sprintf("running (pid %d)", s.WatcherPID))
+	default:
+		row("Watcher", fmt.Sprintf("not running (pid %d exited)", s.WatcherPID))
+	}
+
+	if len(s.Annotations) > 0 {
+		rows = append(rows, "", "Recent notes")
+		recent := s.Annotations[max(len(s.Annotations)-statusRecentNotes, 0):]
+		for _, a := range recent {
+			msg, _, _ := strings.Cut(strings.TrimSpace(a.Message), "\n")
+			if len([]rune(msg)) > 60 {
+				msg = string([]rune(msg)[:59]) + "…"
+			}
+			rows = append(rows, fmt.Sprintf("  %s  %s", a.Timestamp.Format("15:04"), msg))
+		}
+	}
+
+	table := strings.Join(rows, "\n")
+	if statusBoxed {
+		table = lipgloss.NewRenderer(cmd.OutOrStderr()).NewStyle().
+			Border(lipgloss.RoundedBorder()).
+			Padding(0, 1).
+			Render(table)
+	}
+	cmd.Println(table)
 }
 
 func init() {
+	statusCmd.Flags().StringVar(&statusFormat, "format", "", "Output format: plain (the default) or table, a boxed summary with recent notes")
+	statusCmd.Flags().BoolVarP(&statusFollow, "follow", "f", false, "Redraw the status every second until interrupted")
+	addSessionNameFlag(statusCmd)
 	rootCmd.AddCommand(statusCmd)
 }
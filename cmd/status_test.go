@@ -1,7 +1,10 @@
 package cmd
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"strings"
 	"testing"
 	"time"
@@ -73,3 +76,94 @@ func TestStatusCountsAccuracy(t *testing.T) {
 		}
 	})
 }
+
+// TestStatusFollowRedraws verifies that followStatus picks up changes to the
+// session file between frames and stops when its context is cancelled.
+func TestStatusFollowRedraws(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	s := &session.Session{ID: "test-id", StartTime: time.Now()}
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	var out bytes.Buffer
+	statusCmd.SetOut(&out)
+	t.Cleanup(func() { statusCmd.SetOut(nil) })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- followStatus(ctx, statusCmd, 20*time.Millisecond) }()
+
+	time.Sleep(10 * time.Millisecond)
+	s.Annotations = append(s.Annotations, session.Annotation{Timestamp: time.Now(), Message: "hi"})
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	time.Sleep(40 * time.Millisecond)
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("followStatus: %v", err)
+	}
+
+	frames := strings.Split(out.String(), clearScreen)
+	if len(frames) < 3 {
+		t.Fatalf("expected at least two frames, got %q", out.String())
+	}
+	if !strings.Contains(frames[1], "Annotations: 0") {
+		t.Errorf("first frame: %q", frames[1])
+	}
+	if last := frames[len(frames)-1]; !strings.Contains(last, "Annotations: 1") {
+		t.Errorf("last frame: %q", last)
+	}
+}
+
+// TestStatusTable verifies that --format table lists tags and only the last
+// three annotations, and is boxed only when writing to a terminal.
+func TestStatusTable(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	terminal := statusTerminal
+	t.Cleanup(func() {
+		statusTerminal = terminal
+		statusFormat, statusBoxed = "", false
+	})
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	s := &session.Session{ID: "test-id", StartTime: time.Now(), Tags: []string{"bugfix", "api"}}
+	for i := 1; i <= 4; i++ {
+		s.Annotations = append(s.Annotations, session.Annotation{Timestamp: time.Now(), Message: fmt.Sprintf("note %d", i)})
+	}
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	for _, tty := range []bool{false, true} {
+		statusTerminal = func(io.Writer) bool { return tty }
+		rootCmd.ResetFlags()
+		out, err := executeCommand(rootCmd, "status", "--format", "table")
+		if err != nil {
+			t.Fatalf("status --format table: %v", err)
+		}
+		for _, want := range []string{"Tags         bugfix, api", "Recent notes", "note 2", "note 4"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("tty=%v: missing %q in:\n%s", tty, want, out)
+			}
+		}
+		if strings.Contains(out, "note 1") {
+			t.Errorf("tty=%v: expected only the last three notes:\n%s", tty, out)
+		}
+		if boxed := strings.Contains(out, "╭"); boxed != tty {
+			t.Errorf("tty=%v: boxed = %v:\n%s", tty, boxed, out)
+		}
+	}
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "status", "--format", "yaml"); err == nil {
+		t.Error("expected an error for an unsupported format")
+	}
+}
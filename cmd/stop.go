@@ -1,88 +1,315 @@
 package cmd
 
 import (
+	"bufio"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/charmbracelet/x/term"
 	"github.com/spf13/cobra"
 	"github.com/fakeyudi/handoff/internal/bundle"
 	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
+	"github.com/fakeyudi/handoff/internal/publish"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
 var stopMessage string
 var stopFormat string
+var stopJSON bool
+var stopNoEmbed bool
+var stopStdout bool
+var stopDryRun bool
+var stopDryRunSummary bool
+var stopNoFiles, stopNoShell, stopNoGit, stopNoEditors bool
+var stopSessionOnly bool
+var stopPublish string
+var stopDedupeCommands bool
+var stopFrontmatter bool
+var stopNoPrompt bool
+var stopFilename string
+var stopQuiet bool
+var stopNoDiffCache bool
+var stopEncrypt bool
+var stopNoDiffs bool
+
+// diffCache keeps the diffs captured by stop for the life of the process.
+// With diff_cache set, a cache under the data dir is used instead.
+var diffCache = &collector.DiffCache{}
+
+// stopInteractive reports whether stop may prompt for a summary: both stdin
+// and stdout must be terminals. Tests replace it.
+var stopInteractive = func() bool {
+	return term.IsTerminal(os.Stdin.Fd()) && term.IsTerminal(os.Stdout.Fd())
+}
+
+// stopSummary is the machine-readable result printed by `stop --json`.
+type stopSummary struct {
+	OutputPath  string   `json:"output_path"`
+	Format      string   `json:"format"`
+	FileEdits   int      `json:"file_edits"`
+	Commands    int      `json:"commands"`
+	Annotations int      `json:"annotations"`
+	EditorTabs  int      `json:"editor_tabs"`
+	Warnings    []string `json:"warnings"`
+
+	FilesChanged int `json:"files_changed"`
+	Insertions   int `json:"insertions"`
+	Deletions    int `json:"deletions"`
+	// PublishedURL is where --publish (or publish_url) sent the bundle.
+	PublishedURL string `json:"published_url,omitempty"`
+	Encrypted    bool   `json:"encrypted,omitempty"`
+}
 
-// TODO :- Use the name param for file saving while saving check if same file exists then append a number after that incrementally
 var stopCmd = &cobra.Command{
 	Use:   "stop",
 	Short: "End the current tracking session and generate a context bundle",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		store, err := session.NewSessionStore()
+		if strings.ContainsAny(stopFilename, `/\`) {
+			return fmt.Errorf("--filename %q must be a file name, not a path", stopFilename)
+		}
+		if stopStdout && stopJSON {
+			return errors.New("--stdout and --json cannot be used together")
+		}
+		if stopDryRun && stopJSON {
+			return errors.New("--dry-run and --json cannot be used together")
+		}
+		if stopDryRunSummary && !stopDryRun {
+			return errors.New("--summary requires --dry-run")
+		}
+
+		store, err := openSessionStore()
 		if err != nil {
 			return err
 		}
 
-		s, err := store.Load()
-		if err != nil {
+		if _, err := store.Load(); err != nil {
 			if errors.Is(err, session.ErrNoSession) {
-				return fmt.Errorf("no active session")
+				return noActiveSessionError()
 			}
 			return err
 		}
 
+		// The plugin command log is shared by all sessions, so only consume
+		// (truncate) it when this is the last active session.
+		active, err := session.ListSessions()
+		if err != nil {
+			return err
+		}
+		// A dry run must leave the log for the real stop.
+		keepLog := len(active) > 1 || stopDryRun
+
 		now := time.Now()
-		s.StopTime = &now
+		cfg := GetConfig()
 
-		if stopMessage != "" {
+		// Select the format from --format or config DefaultFormat, checked
+		// before anything is collected.
+		format := stopFormat
+		if format == "" {
+			format = cfg.DefaultFormat
+		}
+		if format == "" {
+			format = "markdown"
+		}
+		if _, format, err = rendererFor(format); err != nil {
+			return err
+		}
+
+		// Without -m, ask for a summary, unless the output is for a program
+		// or nobody is there to answer.
+		summary := stopMessage
+		if summary == "" && !stopNoPrompt && !stopJSON && !stopStdout && !stopDryRun && stopInteractive() {
+			summary, err = promptSummary(cfg.SummaryTemplate)
+			if err != nil {
+				return err
+			}
+		}
+		// Ask for the passphrase now rather than after collecting. A dry
+		// run only prints, so it stays readable.
+		var passphrase string
+		if (stopEncrypt || config.On(cfg.Encrypt)) && !stopDryRun {
+			if strings.HasPrefix(stopPublish, "gist://") || (stopPublish == "" && strings.HasPrefix(cfg.PublishURL, "gist://")) {
+				return errors.New("an encrypted bundle can't be published to a gist")
+			}
+			passphrase, err = bundlePassphrase(true)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Work from a fresh read from here on, and hold the store's lock until
+		// the session is deleted: a note or a watcher flush made while the
+		// summary was being written is kept, and none can come in between
+		// the bundle being built and the session going away.
+		s, release, err := store.Take()
+		if err != nil {
+			if errors.Is(err, session.ErrNoSession) {
+				return noActiveSessionError()
+			}
+			return err
+		}
+		defer release()
+
+		s.StopTime = &now
+		// Close an open pause so it counts towards paused time.
+		if s.IsPaused() {
+			s.PausedIntervals[len(s.PausedIntervals)-1].End = now
+		}
+		if summary != "" {
 			s.Annotations = append(s.Annotations, session.Annotation{
 				Timestamp: now,
-				Message:   stopMessage,
+				Message:   summary,
 				IsSummary: true,
 			})
 		}
 
-		cfg := GetConfig()
 		prof := GetProfile()
 
-		// Run all collectors and merge results.
-		ctx := context.Background()
-		collectors := []collector.Collector{
-			&collector.FileCollector{
+		timeout, err := time.ParseDuration(cfg.CollectTimeout)
+		if err != nil || timeout <= 0 {
+			return fmt.Errorf("invalid collect_timeout %q in config", cfg.CollectTimeout)
+		}
+
+		// Run all collectors and merge results. Collectors share one deadline
+		// so a hung subprocess can't block stop indefinitely.
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		ctx = collector.WithLogger(ctx, logger)
+
+		// Disabled collectors are left out, with a warning so whoever reads
+		// the bundle knows the data is missing by choice.
+		var merged collector.CollectorResult
+		enabled := func(name string, flagOff bool, setting *bool) bool {
+			switch {
+			case flagOff:
+				merged.Warnings = append(merged.Warnings, fmt.Sprintf("%s collector skipped (--no-%s)", name, name))
+			case !config.Enabled(setting):
+				merged.Warnings = append(merged.Warnings, fmt.Sprintf("%s collector skipped (collect_%s is false)", name, name))
+			default:
+				return true
+			}
+			return false
+		}
+
+		var collectors []collector.Collector
+		if enabled("files", stopNoFiles, cfg.CollectFiles) {
+			cache := diffCache
+			if config.On(cfg.DiffCache) {
+				if dir, err := session.DataDir(); err == nil {
+					cache = &collector.DiffCache{Dir: filepath.Join(dir, "diffcache")}
+				}
+			}
+			// The cache holds diffs in the clear, so an encrypted bundle
+			// goes without it.
+			if stopNoDiffCache || passphrase != "" {
+				cache = nil
+			}
+			collectors = append(collectors, &collector.FileCollector{
 				WorkDir:        s.WorkDir,
 				IgnorePatterns: cfg.IgnorePatterns,
+				MaxDiffBytes:   collector.DefaultMaxDiffBytes,
+				DiffBase:       cfg.DiffBase,
+				AnnotateHunks:  config.On(cfg.AnnotateHunks),
+				SessionOnly:    stopSessionOnly,
+				DiffCache:      cache,
+				SkipGitignore:  !config.Enabled(cfg.RespectGitignore),
+			})
+		}
+		if enabled("shell", stopNoShell, cfg.CollectShell) {
+			collectors = append(collectors, &collector.ShellCollector{
+				HistoryPath:   cfg.ShellHistoryPath,
+				UsePluginLog:  prof != nil && prof.RecordCommands,
+				KeepPluginLog: keepLog,
+			})
+		}
+		if enabled("git", stopNoGit, cfg.CollectGit) {
+			collectors = append(collectors, &collector.GitCollector{
+				WorkDir:     s.WorkDir,
+				LogLimit:    cfg.GitLogLimit,
+				LogAuthor:   cfg.GitLogAuthor,
+				LogNoMerges: config.On(cfg.GitLogNoMerges),
+			})
+		}
+		if enabled("editors", stopNoEditors, cfg.CollectEditors) {
+			collectors = append(collectors, &collector.EditorCollector{})
+		}
+		collectors = append(collectors,
+			&collector.BrowserCollector{},
+			&collector.ProcessCollector{
+				WorkDir: s.WorkDir,
 			},
-			&collector.ShellCollector{
-				HistoryPath:    cfg.ShellHistoryPath,
-				UsePluginLog:   prof != nil && prof.RecordCommands,
+			&collector.TmuxCollector{},
+			&collector.EnvCollector{
+				AllowList: cfg.EnvAllowList,
 			},
-			&collector.GitCollector{
-				WorkDir: s.WorkDir,
+			&collector.ToolchainCollector{
+				Probes: cfg.ToolchainProbes,
 			},
-			&collector.EditorCollector{},
-		}
+		)
 
-		var merged collector.CollectorResult
+		var spin *progress
+		if !stopQuiet {
+			spin = startProgress("Collecting…")
+		}
+		defer spin.Stop()
 		for _, c := range collectors {
+			spin.Set("Collecting: " + c.Name() + "…")
+			logger.Debug("collector started", "collector", c.Name())
+			began := time.Now()
 			result, err := c.Collect(ctx, s)
+			logger.Debug("collector finished", "collector", c.Name(),
+				"duration", time.Since(began).Round(time.Millisecond),
+				"file_edits", len(result.FileEdits), "commands", len(result.Commands),
+				"editor_tabs", len(result.EditorTabs), "warnings", len(result.Warnings), "err", err)
 			if err != nil {
-				return fmt.Errorf("collector error: %w", err)
+				if ctx.Err() == nil {
+					return fmt.Errorf("%s collector: %w", c.Name(), err)
+				}
+				// Timed out: keep going so the bundle still gets written.
+				merged.Warnings = append(merged.Warnings,
+					fmt.Sprintf("%s collector timed out after %s", c.Name(), timeout))
+				continue
 			}
 			merged.FileEdits = append(merged.FileEdits, result.FileEdits...)
 			merged.Commands = append(merged.Commands, result.Commands...)
 			merged.EditorTabs = append(merged.EditorTabs, result.EditorTabs...)
-			merged.Warnings = append(merged.Warnings, result.Warnings...)
+			merged.BrowserTabs = append(merged.BrowserTabs, result.BrowserTabs...)
+			merged.Processes = append(merged.Processes, result.Processes...)
+			for _, w := range result.Warnings {
+				merged.Warnings = append(merged.Warnings, c.Name()+": "+w)
+			}
 			if result.GitInfo != nil {
 				merged.GitInfo = result.GitInfo
 			}
+			if result.Env != nil {
+				merged.Env = result.Env
+			}
+			if result.Toolchains != nil {
+				merged.Toolchains = result.Toolchains
+			}
+			if result.Tmux != nil {
+				merged.Tmux = result.Tmux
+			}
+		}
+
+		spin.Stop()
+
+		merged.Commands = append(slices.Clone(s.Commands), merged.Commands...)
+		if stopDedupeCommands || config.On(cfg.DedupeCommands) {
+			merged.Commands = bundle.DedupeCommands(merged.Commands)
 		}
 
 		// Build the ContextBundle.
-		duration := now.Sub(s.StartTime).Round(time.Second).String()
+		duration := (now.Sub(s.StartTime) - s.PausedDuration(now)).Round(time.Second).String()
 		author := ""
 		if prof != nil {
 			author = prof.Name
@@ -95,27 +322,40 @@ var stopCmd = &cobra.Command{
 				WorkDir:   s.WorkDir,
 				Duration:  duration,
 				Author:    author,
+
+				ProjectName: collector.ProjectName(s.WorkDir),
 			},
+			Tags:        s.Tags,
 			Annotations: s.Annotations,
 			FileEdits:   merged.FileEdits,
 			Git:         merged.GitInfo,
 			Commands:    merged.Commands,
 			EditorTabs:  merged.EditorTabs,
+			BrowserTabs: merged.BrowserTabs,
+			Processes:   merged.Processes,
+			Env:         merged.Env,
+			Toolchains:  merged.Toolchains,
+			Warnings:    merged.Warnings,
+			Tmux:        merged.Tmux,
 		}
-
-		// Select renderer based on --format flag or config DefaultFormat.
-		format := stopFormat
-		if format == "" {
-			format = cfg.DefaultFormat
+		b.SetLanguages()
+		b.SetDiffStat()
+		// Counts are taken above, so they survive dropping the diffs.
+		if stopNoDiffs || !config.Enabled(cfg.IncludeDiffs) {
+			b.OmitDiffs()
 		}
 
 		var renderer bundle.BundleRenderer
 		ext := ".md"
-		if format == "json" {
+		switch format {
+		case "json":
 			renderer = &bundle.JSONRenderer{}
 			ext = ".json"
-		} else {
-			renderer = &bundle.MarkdownRenderer{}
+		case "jsonl":
+			renderer = &bundle.JSONLRenderer{}
+			ext = ".jsonl"
+		default:
+			renderer = &bundle.MarkdownRenderer{NoEmbed: stopNoEmbed, Frontmatter: stopFrontmatter || config.On(cfg.Frontmatter)}
 		}
 
 		data, err := renderer.Render(b)
@@ -123,12 +363,80 @@ var stopCmd = &cobra.Command{
 			return fmt.Errorf("render bundle: %w", err)
 		}
 
-		// Write output file to OutputDir with name handoff-<timestamp>.md or .json.
-		filename := "handoff-" + now.Format(time.RFC3339) + ext
+		// --dry-run: preview the bundle, leaving the session untouched on
+		// disk so stop can be run for real afterwards.
+		if stopDryRun {
+			for _, w := range merged.Warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
+			if stopDryRunSummary {
+				printSummary(&b.Session, b.Counts(), b.Tags, b.Languages)
+				return nil
+			}
+			_, err := os.Stdout.Write(data)
+			return err
+		}
+
+		// Encrypt before the bundle goes anywhere, so the plaintext is never
+		// written or sent. The format's extension is kept ahead of .age for
+		// view to pick the parser by.
+		if passphrase != "" {
+			data, err = bundle.Encrypt(data, passphrase)
+			if err != nil {
+				return fmt.Errorf("encrypt bundle: %w", err)
+			}
+			ext += bundle.EncryptedExt
+		}
+
+		// Write output file to OutputDir with name handoff-<timestamp>.md or
+		// .json, or <--filename>.md with a number added if that is taken.
 		outputDir := cfg.OutputDir
 		if outputDir == "" {
 			outputDir = "."
 		}
+		filename := "handoff-" + now.Format(time.RFC3339) + ext
+		if stopFilename != "" {
+			filename = uniqueFilename(outputDir, strings.TrimSuffix(stopFilename, ext), ext)
+		}
+
+		// Publishing happens after rendering, so a failure can only add a
+		// warning: the local bundle is still written.
+		publishTo := stopPublish
+		if publishTo == "" {
+			publishTo = cfg.PublishURL
+		}
+		var publishedURL string
+		if publishTo != "" {
+			pub := publish.Bundle{Name: filename, Format: format, Data: data}
+			if passphrase != "" {
+				pub.Format = "encrypted"
+			}
+			url, err := publish.Publish(context.Background(), publishTo, pub)
+			if err != nil {
+				merged.Warnings = append(merged.Warnings, fmt.Sprintf("publish to %s failed: %v", publishTo, err))
+			} else {
+				publishedURL = url
+			}
+		}
+
+		// --stdout: print the bundle instead of writing a file. Warnings go
+		// to stderr so the stream stays clean for piping.
+		if stopStdout {
+			if _, err := os.Stdout.Write(data); err != nil {
+				return fmt.Errorf("write bundle: %w", err)
+			}
+			if err := store.Delete(); err != nil {
+				return err
+			}
+			for _, w := range merged.Warnings {
+				fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+			}
+			if publishedURL != "" {
+				fmt.Fprintf(os.Stderr, "Published: %s\n", publishedURL)
+			}
+			return nil
+		}
+
 		outputPath := filepath.Join(outputDir, filename)
 
 		if err := os.WriteFile(outputPath, data, 0644); err != nil {
@@ -144,13 +452,124 @@ var stopCmd = &cobra.Command{
 			fmt.Fprintf(os.Stderr, "warning: %s\n", w)
 		}
 
+		if stopJSON {
+			summary := stopSummary{
+				OutputPath:  outputPath,
+				Format:      format,
+				FileEdits:   len(b.FileEdits),
+				Commands:    len(b.Commands),
+				Annotations: len(b.Annotations),
+				EditorTabs:  len(b.EditorTabs),
+				Warnings:    merged.Warnings,
+
+				FilesChanged: b.Session.FilesChanged,
+				Insertions:   b.Session.Insertions,
+				Deletions:    b.Session.Deletions,
+				PublishedURL: publishedURL,
+				Encrypted:    passphrase != "",
+			}
+			if summary.Warnings == nil {
+				summary.Warnings = []string{}
+			}
+			out, err := json.MarshalIndent(summary, "", "  ")
+			if err != nil {
+				return fmt.Errorf("marshal summary: %w", err)
+			}
+			fmt.Println(string(out))
+			return nil
+		}
+
 		fmt.Printf("Session stopped. Output: %s\n", outputPath)
+		if publishedURL != "" {
+			fmt.Printf("Published: %s\n", publishedURL)
+		}
 		return nil
 	},
 }
 
+// uniqueFilename returns base+ext, or base-1+ext, base-2+ext and so on,
+// whichever is the first not already in dir.
+func uniqueFilename(dir, base, ext string) string {
+	name := base + ext
+	for n := 1; ; n++ {
+		if _, err := os.Stat(filepath.Join(dir, name)); errors.Is(err, os.ErrNotExist) {
+			return name
+		}
+		name = fmt.Sprintf("%s-%d%s", base, n, ext)
+	}
+}
+
+// promptSummary asks for the session summary. With $VISUAL or $EDITOR set,
+// the editor opens on a file pre-filled with template; otherwise a single
+// line is read from stdin. Leaving the text empty, or the template
+// unchanged, skips the summary.
+func promptSummary(template string) (string, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		fmt.Print("Summary (enter to skip): ")
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return "", err
+		}
+		return strings.TrimSpace(line), nil
+	}
+
+	f, err := os.CreateTemp("", "handoff-summary-*.md")
+	if err != nil {
+		return "", err
+	}
+	path := f.Name()
+	defer os.Remove(path)
+	_, err = f.WriteString(template)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return "", err
+	}
+
+	c := exec.Command(args[0], append(args[1:], path)...)
+	c.Stdin, c.Stdout, c.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := c.Run(); err != nil {
+		return "", fmt.Errorf("summary editor: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	text := strings.TrimSpace(string(data))
+	if text == strings.TrimSpace(template) {
+		return "", nil
+	}
+	return text, nil
+}
+
 func init() {
 	stopCmd.Flags().StringVarP(&stopMessage, "message", "m", "", "Summary annotation to include in the context bundle")
-	stopCmd.Flags().StringVar(&stopFormat, "format", "", "Output format: markdown or json (overrides config)")
+	stopCmd.Flags().StringVar(&stopFilename, "filename", "", "Name the bundle <filename>.<ext> instead of handoff-<timestamp>, adding -1, -2, ... if it exists")
+	stopCmd.Flags().StringVar(&stopFormat, "format", "", "Output format: markdown, json or jsonl (overrides config)")
+	stopCmd.Flags().BoolVar(&stopJSON, "json", false, "Print a machine-readable JSON summary instead of the human-readable line")
+	stopCmd.Flags().BoolVarP(&stopQuiet, "quiet", "q", false, "Don't show a progress spinner while collecting")
+	stopCmd.Flags().BoolVar(&stopNoPrompt, "no-prompt", false, "Don't ask for a summary when -m is not given")
+	stopCmd.Flags().BoolVar(&stopFrontmatter, "frontmatter", false, "Start Markdown output with a YAML frontmatter block (work dir, branch, duration, stop time, tags)")
+	stopCmd.Flags().BoolVar(&stopNoEmbed, "no-embed", false, "Omit the embedded data payload from Markdown output (the file can't be opened with 'handoff view')")
+	stopCmd.Flags().BoolVar(&stopStdout, "stdout", false, "Print the bundle to stdout instead of writing a file")
+	stopCmd.Flags().BoolVar(&stopDryRun, "dry-run", false, "Print the bundle without writing it or ending the session")
+	stopCmd.Flags().BoolVar(&stopDryRunSummary, "summary", false, "With --dry-run, print only a summary with counts")
+	stopCmd.Flags().BoolVar(&stopSessionOnly, "session-only", false, "Leave out diff hunks that git blame dates before the session")
+	stopCmd.Flags().BoolVar(&stopNoDiffCache, "no-diff-cache", false, "Capture every diff afresh instead of reusing cached ones")
+	stopCmd.Flags().BoolVar(&stopNoFiles, "no-files", false, "Skip collecting file edits and diffs")
+	stopCmd.Flags().BoolVar(&stopNoShell, "no-shell", false, "Skip collecting shell commands")
+	stopCmd.Flags().BoolVar(&stopNoGit, "no-git", false, "Skip collecting git state")
+	stopCmd.Flags().BoolVar(&stopNoEditors, "no-editors", false, "Skip collecting open editor tabs")
+	stopCmd.Flags().BoolVar(&stopNoDiffs, "no-diffs", false, "Leave diffs out of the bundle, keeping changed paths and line counts")
+	stopCmd.Flags().BoolVar(&stopEncrypt, "encrypt", false, "Encrypt the bundle with a passphrase from $HANDOFF_PASSPHRASE or a prompt, adding .age to its name")
+	stopCmd.Flags().BoolVar(&stopDedupeCommands, "dedupe-commands", false, "List each distinct command once, with a count of its runs")
+	stopCmd.Flags().StringVar(&stopPublish, "publish", "", "Also send the bundle to this http(s) URL, or to a GitHub gist with gist:// (overrides publish_url)")
+	addSessionNameFlag(stopCmd)
 	rootCmd.AddCommand(stopCmd)
 }
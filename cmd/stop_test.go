@@ -1,8 +1,22 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
 )
 
 // TestStopNoSessionError verifies that running "stop" when no session is active
@@ -24,3 +38,550 @@ func TestStopNoSessionError(t *testing.T) {
 		t.Errorf("expected error to contain %q, got: %q", "no active session", combined)
 	}
 }
+
+// TestStopJSONSummary verifies that "stop --json" prints a machine-readable
+// summary on stdout and still writes the bundle file.
+func TestStopJSONSummary(t *testing.T) {
+	prepareStopSession(t)
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--json", "--format", "json")
+	})
+	stopJSON, stopFormat = false, ""
+	if runErr != nil {
+		t.Fatalf("stop --json: %v", runErr)
+	}
+
+	if strings.Contains(stdout, "Session stopped.") {
+		t.Errorf("expected human-readable line to be suppressed, got:\n%s", stdout)
+	}
+	var summary stopSummary
+	if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+		t.Fatalf("stdout is not a JSON summary: %v\n%s", err, stdout)
+	}
+	if summary.Format != "json" {
+		t.Errorf("format: got %q, want %q", summary.Format, "json")
+	}
+	if summary.Annotations != 1 {
+		t.Errorf("annotations: got %d, want 1", summary.Annotations)
+	}
+	if summary.Warnings == nil {
+		t.Error("warnings: expected an array, got null")
+	}
+	if _, err := os.Stat(summary.OutputPath); err != nil {
+		t.Errorf("expected bundle at %s: %v", summary.OutputPath, err)
+	}
+}
+
+// TestStopUnknownFormat verifies that stop rejects an unknown --format
+// before collecting anything, leaving the session active.
+func TestStopUnknownFormat(t *testing.T) {
+	prepareStopSession(t)
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "stop", "--format", "yaml", "--no-prompt")
+	stopFormat, stopNoPrompt = "", false
+	if err == nil || !strings.Contains(err.Error(), `unknown format "yaml" (expected markdown, json or jsonl)`) {
+		t.Fatalf("expected an unknown format error, got %v", err)
+	}
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if _, err := store.Load(); err != nil {
+		t.Errorf("expected the session to remain, Load returned %v", err)
+	}
+}
+
+// TestStopStdout verifies that "stop --stdout" prints the rendered bundle,
+// writes no file, and still ends the session.
+func TestStopStdout(t *testing.T) {
+	outputDir := prepareStopSession(t)
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--stdout", "--format", "json")
+	})
+	stopStdout, stopFormat = false, ""
+	if runErr != nil {
+		t.Fatalf("stop --stdout: %v", runErr)
+	}
+
+	b, err := (&bundle.JSONParser{}).Parse([]byte(stdout))
+	if err != nil {
+		t.Fatalf("stdout is not a JSON bundle: %v\n%s", err, stdout)
+	}
+	if b.Session.ID != "test-id" {
+		t.Errorf("session ID: got %q, want %q", b.Session.ID, "test-id")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(outputDir, "handoff-*")); len(matches) != 0 {
+		t.Errorf("expected no bundle file, found %v", matches)
+	}
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if _, err := store.Load(); !errors.Is(err, session.ErrNoSession) {
+		t.Errorf("expected session to be deleted, Load returned %v", err)
+	}
+}
+
+// TestStopDryRun verifies that "stop --dry-run" prints the bundle but leaves
+// the session file byte-for-byte unchanged and writes no bundle.
+func TestStopDryRun(t *testing.T) {
+	outputDir := prepareStopSession(t)
+	sessionPath := filepath.Join(outputDir, "handoff", "session.json")
+	before, err := os.ReadFile(sessionPath)
+	if err != nil {
+		t.Fatalf("read session file: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--dry-run", "--summary", "-m", "preview")
+	})
+	stopDryRun, stopDryRunSummary, stopMessage = false, false, ""
+	if runErr != nil {
+		t.Fatalf("stop --dry-run: %v", runErr)
+	}
+
+	if !strings.Contains(stdout, "## Summary") || !strings.Contains(stdout, "Contents:  2 annotations") {
+		t.Errorf("expected a summary with counts, got:\n%s", stdout)
+	}
+	after, err := os.ReadFile(sessionPath)
+	if err != nil {
+		t.Fatalf("session file missing after dry run: %v", err)
+	}
+	if string(after) != string(before) {
+		t.Error("dry run modified the session file")
+	}
+	if matches, _ := filepath.Glob(filepath.Join(outputDir, "handoff-*")); len(matches) != 0 {
+		t.Errorf("expected no bundle file, found %v", matches)
+	}
+}
+
+// TestStopSkipsDisabledCollectors verifies that --no-<collector> flags and
+// collect_<collector> config switches leave a collector out and record why.
+func TestStopSkipsDisabledCollectors(t *testing.T) {
+	tmp := prepareStopSession(t)
+	cfgJSON := fmt.Sprintf(`{"output_dir": %q, "toolchain_probes": ["git --version"], "collect_editors": false}`, tmp)
+	if err := os.WriteFile(filepath.Join(tmp, ".config", "handoff", "config.json"), []byte(cfgJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--stdout", "--format", "json", "--no-shell")
+	})
+	stopStdout, stopFormat, stopNoShell = false, "", false
+	if runErr != nil {
+		t.Fatalf("stop --no-shell: %v", runErr)
+	}
+
+	b, err := (&bundle.JSONParser{}).Parse([]byte(stdout))
+	if err != nil {
+		t.Fatalf("stdout is not a JSON bundle: %v\n%s", err, stdout)
+	}
+	for _, want := range []string{
+		"shell collector skipped (--no-shell)",
+		"editors collector skipped (collect_editors is false)",
+		"git: not a git repository", // collector warnings are labeled
+	} {
+		if !slices.Contains(b.Warnings, want) {
+			t.Errorf("warnings %q: missing %q", b.Warnings, want)
+		}
+	}
+	if len(b.Commands) != 0 {
+		t.Errorf("expected no commands with --no-shell, got %v", b.Commands)
+	}
+}
+
+// prepareStopSession saves an active session in an isolated XDG/HOME and
+// routes bundle output to a temp dir, which it returns.
+func prepareStopSession(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+	t.Setenv("SHELL", "/bin/bash")
+
+	// Route the bundle into the temp dir via the global config. Probe only
+	// git: `go version` may leave a telemetry process writing under HOME.
+	cfgDir := filepath.Join(tmp, ".config", "handoff")
+	if err := os.MkdirAll(cfgDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgJSON := fmt.Sprintf(`{"output_dir": %q, "toolchain_probes": ["git --version"]}`, tmp)
+	if err := os.WriteFile(filepath.Join(cfgDir, "config.json"), []byte(cfgJSON), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	s := &session.Session{
+		ID:        "test-id",
+		StartTime: time.Now().Add(-time.Minute),
+		WorkDir:   t.TempDir(),
+		Annotations: []session.Annotation{
+			{Timestamp: time.Now(), Message: "a note"},
+		},
+	}
+	if err := store.Save(s); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	return tmp
+}
+
+// captureStdout redirects os.Stdout while fn runs and returns what was written.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	orig := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	os.Stdout = w
+
+	done := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		done <- string(data)
+	}()
+
+	fn()
+
+	w.Close()
+	os.Stdout = orig
+	return <-done
+}
+
+// TestStopPublish verifies that --publish posts the bundle and reports where
+// it went, and that a failed publish only adds a warning.
+func TestStopPublish(t *testing.T) {
+	var posted string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/fail" {
+			http.Error(w, "down", http.StatusServiceUnavailable)
+			return
+		}
+		data, _ := io.ReadAll(r.Body)
+		posted = string(data)
+		w.Header().Set("Location", "https://handoffs.example.com/1")
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer srv.Close()
+	t.Cleanup(func() { stopJSON, stopFormat, stopPublish = false, "", "" })
+
+	for _, tc := range []struct {
+		path    string
+		wantURL string
+	}{
+		{"/ok", "https://handoffs.example.com/1"},
+		{"/fail", ""},
+	} {
+		prepareStopSession(t)
+		rootCmd.ResetFlags()
+		var runErr error
+		stdout := captureStdout(t, func() {
+			_, runErr = executeCommand(rootCmd, "stop", "--json", "--format", "json", "--publish", srv.URL+tc.path)
+		})
+		if runErr != nil {
+			t.Fatalf("stop --publish %s: %v", tc.path, runErr)
+		}
+		var summary stopSummary
+		if err := json.Unmarshal([]byte(stdout), &summary); err != nil {
+			t.Fatalf("stdout is not a JSON summary: %v\n%s", err, stdout)
+		}
+		if summary.PublishedURL != tc.wantURL {
+			t.Errorf("%s: published URL %q, want %q", tc.path, summary.PublishedURL, tc.wantURL)
+		}
+		if _, err := os.Stat(summary.OutputPath); err != nil {
+			t.Errorf("%s: expected the local bundle to be written: %v", tc.path, err)
+		}
+		failed := slices.ContainsFunc(summary.Warnings, func(w string) bool { return strings.Contains(w, "publish to") })
+		if failed != (tc.wantURL == "") {
+			t.Errorf("%s: warnings %q", tc.path, summary.Warnings)
+		}
+	}
+	if _, err := (&bundle.JSONParser{}).Parse([]byte(posted)); err != nil {
+		t.Errorf("posted body is not a JSON bundle: %v", err)
+	}
+}
+
+// TestStopSummaryPrompt verifies that an interactive stop without -m opens
+// the editor on the summary template and records the result as the summary,
+// and that --no-prompt skips it.
+func TestStopSummaryPrompt(t *testing.T) {
+	interactive := stopInteractive
+	stopInteractive = func() bool { return true }
+	t.Cleanup(func() {
+		stopInteractive = interactive
+		stopFormat, stopNoPrompt = "", false
+	})
+
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"stop", "--format", "json"}, "## What I did\nFixed the parser"},
+		{[]string{"stop", "--format", "json", "--no-prompt"}, ""},
+	} {
+		tmp := prepareStopSession(t)
+		cfgJSON := fmt.Sprintf(`{"output_dir": %q, "toolchain_probes": ["git --version"], "summary_template": "## What I did\n"}`, tmp)
+		if err := os.WriteFile(filepath.Join(tmp, ".config", "handoff", "config.json"), []byte(cfgJSON), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		editor := filepath.Join(tmp, "editor.sh")
+		if err := os.WriteFile(editor, []byte("#!/bin/sh\necho 'Fixed the parser' >> \"$1\"\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("VISUAL", "")
+		t.Setenv("EDITOR", editor)
+
+		rootCmd.ResetFlags()
+		var runErr error
+		captureStdout(t, func() {
+			_, runErr = executeCommand(rootCmd, tc.args...)
+		})
+		if runErr != nil {
+			t.Fatalf("%v: %v", tc.args, runErr)
+		}
+		paths, err := findBundles(tmp, "json")
+		if err != nil || len(paths) != 1 {
+			t.Fatalf("%v: expected one bundle, got %v (%v)", tc.args, paths, err)
+		}
+		b, err := readBundle(paths[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got string
+		for _, a := range b.Annotations {
+			if a.IsSummary {
+				got = a.Message
+			}
+		}
+		if got != tc.want {
+			t.Errorf("%v: summary %q, want %q", tc.args, got, tc.want)
+		}
+	}
+}
+
+// TestStopFilename verifies that "stop --filename" names the bundle after
+// the flag and counts up past names that are already taken.
+func TestStopFilename(t *testing.T) {
+	t.Cleanup(func() { stopFormat, stopFilename = "", "" })
+	tmp := prepareStopSession(t)
+	for _, name := range []string{"feature-login.md", "feature-login-1.md"} {
+		if err := os.WriteFile(filepath.Join(tmp, name), []byte("taken"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--format", "markdown", "--filename", "feature-login")
+	})
+	if runErr != nil {
+		t.Fatalf("stop --filename: %v", runErr)
+	}
+	if _, err := readBundle(filepath.Join(tmp, "feature-login-2.md")); err != nil {
+		t.Errorf("expected the bundle at feature-login-2.md: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(tmp, "feature-login.md")); string(data) != "taken" {
+		t.Error("feature-login.md was overwritten")
+	}
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "stop", "--filename", "../escape")
+	if err == nil || !strings.Contains(err.Error(), "not a path") {
+		t.Errorf("expected an error for a path, got %v", err)
+	}
+}
+
+// TestStopNoDiffs verifies that --no-diffs drops the diffs but keeps the
+// edited paths and the session's change counts.
+func TestStopNoDiffs(t *testing.T) {
+	t.Cleanup(func() { stopFormat, stopFilename, stopNoDiffs = "", "", false })
+	tmp := prepareStopSession(t)
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	sess, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(sess.WorkDir, "secret.go"), []byte("package secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--format", "json", "--filename", "plain", "--no-diffs")
+	})
+	if runErr != nil {
+		t.Fatalf("stop --no-diffs: %v", runErr)
+	}
+	b, err := readBundle(filepath.Join(tmp, "plain.json"))
+	if err != nil {
+		t.Fatalf("readBundle: %v", err)
+	}
+	if !b.DiffsOmitted {
+		t.Error("expected DiffsOmitted to be set")
+	}
+	if len(b.FileEdits) == 0 {
+		t.Fatal("expected the edited file to be listed")
+	}
+	for _, fe := range b.FileEdits {
+		if fe.Diff != "" {
+			t.Errorf("%s still has a diff", fe.Path)
+		}
+	}
+	if b.Session.FilesChanged == 0 {
+		t.Error("expected the change counts to be kept")
+	}
+}
+
+// TestStopEncrypt verifies that --encrypt writes only the encrypted bundle,
+// which findBundles lists and readBundle opens with the right passphrase and
+// rejects with a wrong one.
+func TestStopEncrypt(t *testing.T) {
+	t.Cleanup(func() { stopFormat, stopFilename, stopEncrypt = "", "", false })
+	t.Setenv(passphraseEnv, "correct horse")
+	tmp := prepareStopSession(t)
+
+	rootCmd.ResetFlags()
+	var runErr error
+	captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "stop", "--format", "markdown", "--filename", "secret", "--encrypt")
+	})
+	if runErr != nil {
+		t.Fatalf("stop --encrypt: %v", runErr)
+	}
+	path := filepath.Join(tmp, "secret.md.age")
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("expected the bundle at secret.md.age: %v", err)
+	}
+	if bytes.Contains(data, []byte("Handoff")) {
+		t.Error("the encrypted bundle contains plaintext")
+	}
+	if _, err := os.Stat(filepath.Join(tmp, "secret.md")); !os.IsNotExist(err) {
+		t.Error("a plaintext secret.md was written")
+	}
+	for _, format := range []string{"", "markdown"} {
+		if paths, err := findBundles(tmp, format); err != nil || !slices.Contains(paths, path) {
+			t.Errorf("findBundles(%q) = %v, %v; want it to include %s", format, paths, err, path)
+		}
+	}
+	if paths, _ := findBundles(tmp, "json"); slices.Contains(paths, path) {
+		t.Error("findBundles(json) lists an encrypted markdown bundle")
+	}
+
+	b, err := readBundle(path)
+	if err != nil {
+		t.Fatalf("readBundle: %v", err)
+	}
+	if b.Session.WorkDir == "" {
+		t.Error("decrypted bundle has no session")
+	}
+	t.Setenv(passphraseEnv, "wrong")
+	if _, err := readBundle(path); !errors.Is(err, bundle.ErrBadPassphrase) {
+		t.Errorf("wrong passphrase: got %v, want ErrBadPassphrase", err)
+	}
+}
+
+// TestStopProgress verifies that stop shows collection progress on stderr
+// and erases it afterwards, and that --quiet turns it off.
+func TestStopProgress(t *testing.T) {
+	enabled := progressEnabled
+	progressEnabled = func() bool { return true }
+	t.Cleanup(func() {
+		progressEnabled = enabled
+		stopFormat, stopQuiet = "", false
+	})
+
+	for _, tc := range []struct {
+		quiet bool
+		want  bool
+	}{{false, true}, {true, false}} {
+		prepareStopSession(t)
+		args := []string{"stop", "--format", "json"}
+		if tc.quiet {
+			args = append(args, "--quiet")
+		}
+
+		orig := os.Stderr
+		r, w, err := os.Pipe()
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Stderr = w
+		rootCmd.ResetFlags()
+		var runErr error
+		captureStdout(t, func() {
+			_, runErr = executeCommand(rootCmd, args...)
+		})
+		w.Close()
+		os.Stderr = orig
+		data, _ := io.ReadAll(r)
+		if runErr != nil {
+			t.Fatalf("%v: %v", args, runErr)
+		}
+
+		stderr := string(data)
+		if got := strings.Contains(stderr, "Collecting"); got != tc.want {
+			t.Errorf("%v: progress shown = %v, want %v; stderr:\n%q", args, got, tc.want, stderr)
+		}
+		if tc.want && !strings.Contains(stderr, "\r\033[K") {
+			t.Errorf("%v: progress line not erased: %q", args, stderr)
+		}
+	}
+}
+
+// TestStopVerbose verifies that --verbose logs each collector run to
+// stderr, and that nothing is logged without it.
+func TestStopVerbose(t *testing.T) {
+	t.Cleanup(func() { stopFormat, stopFilename, verbose = "", "", false })
+	prepareStopSession(t)
+
+	rootCmd.ResetFlags()
+	addVerboseFlag(rootCmd)
+	var out string
+	var runErr error
+	captureStdout(t, func() {
+		out, runErr = executeCommand(rootCmd, "stop", "--format", "json", "--filename", "verbose", "-v")
+	})
+	if runErr != nil {
+		t.Fatalf("stop -v: %v", runErr)
+	}
+	for _, want := range []string{"msg=\"collector finished\" collector=files", "collector=git", "msg=\"scanning work dir\""} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "time=") {
+		t.Errorf("expected no timestamps in log lines:\n%s", out)
+	}
+
+	verbose = false
+	prepareStopSession(t)
+	captureStdout(t, func() {
+		out, runErr = executeCommand(rootCmd, "stop", "--format", "json", "--filename", "quiet")
+	})
+	if runErr != nil {
+		t.Fatalf("stop: %v", runErr)
+	}
+	if strings.Contains(out, "collector finished") {
+		t.Errorf("expected no log lines without --verbose:\n%s", out)
+	}
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+var tagCmd = &cobra.Command{
+	Use:   "tag <tag>...",
+	Short: "Add tags to the current tracking session",
+	Long: "Add tags to the current tracking session.\n\n" +
+		"Tags are recorded in the bundle and can be used to filter `handoff list`.",
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		tags := make([]string, 0, len(args))
+		for _, arg := range args {
+			tag, err := normalizeTag(arg)
+			if err != nil {
+				return err
+			}
+			tags = append(tags, tag)
+		}
+
+		var all []string
+		err := updateActiveSession(func(s *session.Session) error {
+			for _, tag := range tags {
+				s.AddTag(tag)
+			}
+			all = s.Tags
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Tags: %s\n", strings.Join(all, ", "))
+		return nil
+	},
+}
+
+// normalizeTag trims tag and checks it is a single non-empty word.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	if tag == "" {
+		return "", fmt.Errorf("tag must not be empty")
+	}
+	if strings.ContainsAny(tag, ", \t\n") {
+		return "", fmt.Errorf("invalid tag %q: tags cannot contain spaces or commas", tag)
+	}
+	return tag, nil
+}
+
+func init() {
+	addSessionNameFlag(tagCmd)
+	rootCmd.AddCommand(tagCmd)
+}
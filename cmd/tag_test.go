@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestStartAndTagRecordTags verifies that "start --tag" and "tag" add tags to
+// the session, ignoring duplicates.
+func TestStartAndTagRecordTags(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+	t.Cleanup(func() { startTags = nil })
+
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "start", "--tag", "bugfix", "--tag", "bugfix"); err != nil {
+		t.Fatalf("start --tag: %v", err)
+	}
+	startTags = nil
+	rootCmd.ResetFlags()
+	if _, err := executeCommand(rootCmd, "tag", "urgent", "bugfix"); err != nil {
+		t.Fatalf("tag: %v", err)
+	}
+
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	s, err := store.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := fmt.Sprint(s.Tags); got != "[bugfix urgent]" {
+		t.Errorf("tags = %s, want [bugfix urgent]", got)
+	}
+}
+
+// TestTagRejectsInvalidTag verifies that tags with spaces are rejected.
+func TestTagRejectsInvalidTag(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "tag", "two words")
+	if err == nil || !strings.Contains(err.Error(), "invalid tag") {
+		t.Errorf("expected invalid tag error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/collector"
+)
+
+// verifyRepoDir is the --dir flag of verify-repo.
+var verifyRepoDir string
+
+// verifyRepoGit runs git for verify-repo; nil runs the real git. Tests
+// replace it.
+var verifyRepoGit collector.GitRunner
+
+var verifyRepoCmd = &cobra.Command{
+	Use:   "verify-repo <bundle>",
+	Short: "Check that the local checkout matches the one a bundle was made from",
+	Long: "Compare the branch and commit recorded in a bundle with the repository in\n" +
+		"the current directory (or --dir), and check that every file the bundle\n" +
+		"lists as edited is still there. Edited paths are looked up relative to the\n" +
+		"bundle's work dir, so the checkout may live anywhere.\n\n" +
+		"Exits non-zero if anything does not match.",
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		b, err := readBundle(args[0])
+		if err != nil {
+			return err
+		}
+		dir := verifyRepoDir
+		if dir == "" {
+			dir = "."
+		}
+
+		failed := 0
+		report := func(ok bool, name, detail string) {
+			mark := "✓"
+			if !ok {
+				mark = "✗"
+				failed++
+			}
+			fmt.Printf("  %s %-8s %s\n", mark, name, detail)
+		}
+
+		if b.Git == nil {
+			fmt.Printf("  - %-8s %s\n", "Git", "not recorded in the bundle")
+		} else {
+			git := &collector.GitCollector{WorkDir: dir, Runner: verifyRepoGit}
+			branch, commit, err := git.Head(context.Background())
+			switch {
+			case errors.Is(err, collector.ErrNotGitRepository):
+				report(false, "Git", fmt.Sprintf("%s is not a git repository", dir))
+			case err != nil:
+				return fmt.Errorf("read git state of %s: %w", dir, err)
+			default:
+				if branch == b.Git.Branch {
+					report(true, "Branch", branch)
+				} else {
+					report(false, "Branch", fmt.Sprintf("%s, bundle has %s", branch, b.Git.Branch))
+				}
+				if commit == b.Git.HeadCommit {
+					report(true, "Commit", shortCommit(commit))
+				} else {
+					report(false, "Commit", fmt.Sprintf("%s, bundle has %s", shortCommit(commit), shortCommit(b.Git.HeadCommit)))
+				}
+			}
+		}
+
+		var missing []string
+		seen := make(map[string]bool, len(b.FileEdits))
+		for _, fe := range b.FileEdits {
+			path := localEditPath(fe.Path, b.Session.WorkDir, dir)
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			if _, err := os.Stat(path); err != nil {
+				missing = append(missing, path)
+			}
+		}
+		switch {
+		case len(seen) == 0:
+			fmt.Printf("  - %-8s %s\n", "Files", "no edited files in the bundle")
+		case len(missing) == 0:
+			report(true, "Files", fmt.Sprintf("all %d edited files present", len(seen)))
+		default:
+			report(false, "Files", fmt.Sprintf("%d of %d edited files missing", len(missing), len(seen)))
+			for _, path := range missing {
+				fmt.Printf("      → %s\n", path)
+			}
+		}
+
+		if failed > 0 {
+			return fmt.Errorf("%d check(s) failed", failed)
+		}
+		return nil
+	},
+}
+
+// localEditPath maps a path recorded in a bundle made in bundleWorkDir to
+// the same file under dir. Paths outside bundleWorkDir are left as they are.
+func localEditPath(path, bundleWorkDir, dir string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.Join(dir, path)
+	}
+	if bundleWorkDir == "" {
+		return path
+	}
+	rel, err := filepath.Rel(bundleWorkDir, path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.Join(dir, rel)
+}
+
+// shortCommit abbreviates a commit hash for display.
+func shortCommit(commit string) string {
+	if len(commit) > 12 {
+		return commit[:12]
+	}
+	return commit
+}
+
+func init() {
+	verifyRepoCmd.Flags().StringVar(&verifyRepoDir, "dir", "", "Repository to check (default: the current directory)")
+	rootCmd.AddCommand(verifyRepoCmd)
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestVerifyRepo verifies that verify-repo passes when the checkout matches
+// the bundle, looking edited files up under --dir rather than the bundle's
+// work dir, and reports each mismatch and fails otherwise.
+func TestVerifyRepo(t *testing.T) {
+	tmp := setupOpenDir(t)
+	checkout := t.TempDir()
+	if err := os.WriteFile(filepath.Join(checkout, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	branch, commit := "feature", "0123456789abcdef0123456789abcdef01234567"
+	verifyRepoGit = func(ctx context.Context, workDir string, args ...string) (string, error) {
+		if strings.Join(args, " ") == "rev-parse --abbrev-ref HEAD" {
+			return branch + "\n", nil
+		}
+		return commit + "\n", nil
+	}
+	t.Cleanup(func() { verifyRepoGit, verifyRepoDir = nil, "" })
+
+	write := func(edits ...string) string {
+		b := &bundle.ContextBundle{
+			Session: bundle.SessionMeta{ID: "s", WorkDir: "/work/proj"},
+			Git:     &bundle.GitInfo{Branch: "feature", HeadCommit: "0123456789abcdef0123456789abcdef01234567"},
+		}
+		for _, p := range edits {
+			b.FileEdits = append(b.FileEdits, session.FileEdit{Path: p, Timestamp: time.Now()})
+		}
+		data, err := (&bundle.JSONRenderer{}).Render(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(tmp, "bundle.json")
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	rootCmd.ResetFlags()
+	path := write("/work/proj/main.go")
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "verify-repo", path, "--dir", checkout)
+	})
+	if runErr != nil {
+		t.Fatalf("verify-repo on a matching checkout: %v\n%s", runErr, stdout)
+	}
+	for _, want := range []string{"✓ Branch   feature", "✓ Commit   0123456789ab", "✓ Files    all 1 edited files present"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+
+	branch, commit = "main", "fedcba9876543210fedcba9876543210fedcba98"
+	path = write("/work/proj/main.go", "/work/proj/gone.go")
+	stdout = captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "verify-repo", path, "--dir", checkout)
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "3 check(s) failed") {
+		t.Errorf("expected 3 failed checks, got %v", runErr)
+	}
+	for _, want := range []string{
+		"✗ Branch   main, bundle has feature",
+		"✗ Commit   fedcba987654, bundle has 0123456789ab",
+		"✗ Files    1 of 2 edited files missing",
+		"→ " + filepath.Join(checkout, "gone.go"),
+	} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("output missing %q:\n%s", want, stdout)
+		}
+	}
+}
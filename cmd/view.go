@@ -3,15 +3,27 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
 	"github.com/fakeyudi/handoff/internal/tui"
 )
 
 var plainOutput bool
+var summaryOnly bool
+var viewRaw bool
+var viewTheme string
+var viewSectionNames []string
+var viewTZ string
+
+// viewLoc is the zone view and open show times in, from resolveTimeZone;
+// nil shows them as recorded.
+var viewLoc *time.Location
 
 var viewCmd = &cobra.Command{
 	Use:   "view <file>",
@@ -19,49 +31,146 @@ var viewCmd = &cobra.Command{
 	Args:  cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		path := args[0]
-
-		data, err := os.ReadFile(path)
+		loc, err := resolveTimeZone()
 		if err != nil {
-			if os.IsNotExist(err) {
-				return fmt.Errorf("file not found: %s", path)
-			}
 			return err
 		}
+		viewLoc = loc
 
-		var parser bundle.BundleParser
-		switch strings.ToLower(filepath.Ext(path)) {
-		case ".json":
-			parser = &bundle.JSONParser{}
-		default:
-			parser = &bundle.MarkdownParser{}
+		if summaryOnly {
+			meta, counts, err := readBundleHeader(path)
+			if err != nil {
+				return err
+			}
+			printSummary(meta, counts, nil, nil)
+			return nil
 		}
 
-		b, err := parser.Parse(data)
+		b, err := readBundle(path)
 		if err != nil {
 			return err
 		}
 
+		if len(viewSectionNames) > 0 {
+			return printSections(b, viewSectionNames)
+		}
 		if plainOutput {
 			printBundle(b)
 			return nil
 		}
-		return tui.Run(b, path)
+		theme, err := resolveTheme()
+		if err != nil {
+			return err
+		}
+		save, signed, err := bundleSaver(path, b)
+		if err != nil {
+			return err
+		}
+		return tui.Run(b, path, tui.Options{Raw: viewRaw, Theme: theme, ClusterWindow: clusterWindow(), Location: loc,
+			Save: save, Signed: signed})
 	},
 }
 
+// resolveTheme picks the viewer theme: --theme, then the profile's theme,
+// then nocolor when NO_COLOR is set, then the default.
+func resolveTheme() (tui.Theme, error) {
+	name := viewTheme
+	if name == "" {
+		if p := GetProfile(); p != nil && p.Theme != "" {
+			name = p.Theme
+		} else if os.Getenv("NO_COLOR") != "" {
+			name = "nocolor"
+		} else {
+			name = tui.DefaultTheme
+		}
+	}
+	return tui.LookupTheme(name)
+}
+
+// resolveTimeZone picks the zone the viewer shows times in: --tz, then the
+// profile's time zone. With neither, it returns nil and times are shown as
+// recorded.
+func resolveTimeZone() (*time.Location, error) {
+	name := viewTZ
+	if name == "" {
+		if p := GetProfile(); p != nil {
+			name = p.TimeZone
+		}
+	}
+	switch strings.ToLower(name) {
+	case "":
+		return nil, nil
+	case "local":
+		return time.Local, nil
+	case "utc":
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("unknown time zone %q (want an IANA name such as Europe/Berlin, local or utc)", name)
+	}
+	return loc, nil
+}
+
+// viewTime converts t to the zone chosen with --tz.
+func viewTime(t time.Time) time.Time {
+	if viewLoc == nil {
+		return t
+	}
+	return t.In(viewLoc)
+}
+
+// clusterWindow returns the timeline_cluster setting; config loading has
+// already checked that it parses.
+func clusterWindow() time.Duration {
+	d, _ := time.ParseDuration(GetConfig().TimelineCluster)
+	return d
+}
+
+// viewSections are the sections of printBundle that --section can pick, in
+// the order they are printed.
+var viewSections = []struct {
+	name  string
+	print func(*bundle.ContextBundle)
+}{
+	{"summary", func(b *bundle.ContextBundle) { printSummary(&b.Session, b.Counts(), b.Tags, b.Languages) }},
+	{"annotations", printAnnotations},
+	{"fileedits", printFileEdits},
+	{"git", printGit},
+	{"commands", printCommands},
+	{"editortabs", printEditorTabs},
+}
+
 // printBundle writes a plain-text summary to stdout.
 func printBundle(b *bundle.ContextBundle) {
-	fmt.Println("## Summary")
-	fmt.Printf("  Work dir:  %s\n", b.Session.WorkDir)
-	fmt.Printf("  Started:   %s\n", b.Session.StartTime.Format("2006-01-02 15:04:05 MST"))
-	fmt.Printf("  Stopped:   %s\n", b.Session.StopTime.Format("2006-01-02 15:04:05 MST"))
-	fmt.Printf("  Duration:  %s\n", b.Session.Duration)
-	if b.Git != nil {
-		fmt.Printf("  Branch:    %s\n", b.Git.Branch)
-		fmt.Printf("  Commit:    %s\n", b.Git.HeadCommit)
+	for _, s := range viewSections {
+		s.print(b)
 	}
-	fmt.Println()
+	printExtras(b)
+}
 
+// printSections writes only the named sections, in printBundle's order
+// whatever the order of names.
+func printSections(b *bundle.ContextBundle, names []string) error {
+	known := make([]string, len(viewSections))
+	for i, s := range viewSections {
+		known[i] = s.name
+	}
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown section %q (want one of: %s)", name, strings.Join(known, ", "))
+		}
+	}
+	for _, s := range viewSections {
+		if slices.Contains(names, s.name) {
+			s.print(b)
+		}
+	}
+	return nil
+}
+
+// printAnnotations writes the Annotations section.
+func printAnnotations(b *bundle.ContextBundle) {
 	fmt.Println("## Annotations")
 	if len(b.Annotations) == 0 {
 		fmt.Println("  (none)")
@@ -71,56 +180,109 @@ func printBundle(b *bundle.ContextBundle) {
 			if a.IsSummary {
 				kind = "summary"
 			}
-			fmt.Printf("  [%s] (%s) %s\n", a.Timestamp.Format("2006-01-02 15:04:05"), kind, a.Message)
+			if a.Level != "" && a.Level != session.LevelInfo {
+				kind += ", " + a.Level
+			}
+			msg, more, _ := strings.Cut(strings.TrimRight(a.Message, "\n"), "\n")
+			if more != "" {
+				msg += "\n" + indent(more, "    ")
+			}
+			fmt.Printf("  [%s] (%s) %s", viewTime(a.Timestamp).Format("2006-01-02 15:04:05"), kind, msg)
+			if loc := a.Location(b.Session.WorkDir); loc != "" {
+				fmt.Printf(" — %s", loc)
+			}
+			fmt.Println()
+			if a.LogExcerpt != "" {
+				fmt.Println(indent(strings.TrimRight(a.LogExcerpt, "\n"), "      "))
+			}
 		}
 	}
 	fmt.Println()
+}
 
+// printFileEdits writes the File Edits section.
+func printFileEdits(b *bundle.ContextBundle) {
 	fmt.Println("## File Edits")
 	if len(b.FileEdits) == 0 {
 		fmt.Println("  (none)")
 	} else {
 		for _, fe := range b.FileEdits {
-			fmt.Printf("  %s  (%s)\n", fe.Path, fe.Timestamp.Format("2006-01-02 15:04:05"))
+			fmt.Printf("  %s  (%s)\n", fe.Path, viewTime(fe.Timestamp).Format("2006-01-02 15:04:05"))
 		}
 	}
 	fmt.Println()
+}
 
+// printGit writes the Git Changes section.
+func printGit(b *bundle.ContextBundle) {
 	fmt.Println("## Git Changes")
 	if b.Git == nil {
 		fmt.Println("  (not a git repository or git data unavailable)")
 	} else {
-		if b.Git.Diff != "" {
+		switch {
+		case b.DiffsOmitted:
+			fmt.Println("  Diffs: (omitted)")
+		case b.Git.Diff != "":
 			fmt.Println("  ### Unstaged")
 			fmt.Println(indent(b.Git.Diff, "  "))
-		} else {
+		default:
 			fmt.Println("  Unstaged: (none)")
 		}
-		if b.Git.StagedDiff != "" {
+		switch {
+		case b.DiffsOmitted:
+		case b.Git.StagedDiff != "":
 			fmt.Println("  ### Staged")
 			fmt.Println(indent(b.Git.StagedDiff, "  "))
-		} else {
+		default:
 			fmt.Println("  Staged: (none)")
 		}
+		if len(b.Git.UntrackedFiles) > 0 {
+			fmt.Println("  ### Untracked")
+			for _, f := range b.Git.UntrackedFiles {
+				fmt.Printf("    %s\n", f)
+			}
+			if b.Git.UntrackedDiff != "" {
+				fmt.Println(indent(b.Git.UntrackedDiff, "  "))
+			}
+		}
+		if len(b.Git.Stashes) > 0 {
+			fmt.Println("  ### Stashes")
+			for _, s := range b.Git.Stashes {
+				fmt.Printf("    %s\n", s)
+			}
+		}
 		if len(b.Git.RecentLog) > 0 {
 			fmt.Println("  ### Recent Commits")
 			for _, line := range b.Git.RecentLog {
 				fmt.Printf("    %s\n", line)
 			}
+			if n := b.Git.RecentLogOmitted; n > 0 {
+				fmt.Printf("    ... and %d more\n", n)
+			}
 		}
 	}
 	fmt.Println()
+}
 
+// printCommands writes the Terminal Commands section.
+func printCommands(b *bundle.ContextBundle) {
 	fmt.Println("## Terminal Commands")
 	if len(b.Commands) == 0 {
 		fmt.Println("  (none)")
 	} else {
 		for i, c := range b.Commands {
-			fmt.Printf("  %d. %s\n", i+1, c.Raw)
+			fmt.Printf("  %d. %s", i+1, c.Label())
+			if c.Failed() {
+				fmt.Printf("  (exit %d)", *c.ExitCode)
+			}
+			fmt.Println()
 		}
 	}
 	fmt.Println()
+}
 
+// printEditorTabs writes the Editor Tabs section.
+func printEditorTabs(b *bundle.ContextBundle) {
 	fmt.Println("## Editor Tabs")
 	if len(b.EditorTabs) == 0 {
 		fmt.Println("  (none)")
@@ -132,6 +294,105 @@ func printBundle(b *bundle.ContextBundle) {
 	fmt.Println()
 }
 
+// printExtras writes the sections only some bundles have, from Browser Tabs
+// to Warnings, skipping the empty ones.
+func printExtras(b *bundle.ContextBundle) {
+	if len(b.BrowserTabs) > 0 {
+		fmt.Println("## Browser Tabs")
+		for _, t := range b.BrowserTabs {
+			if t.Title != "" {
+				fmt.Printf("  %s — %s\n", t.Title, t.URL)
+			} else {
+				fmt.Printf("  %s\n", t.URL)
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(b.Env) > 0 {
+		fmt.Println("## Environment")
+		keys := make([]string, 0, len(b.Env))
+		for k := range b.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %s=%s\n", k, b.Env[k])
+		}
+		fmt.Println()
+	}
+
+	if len(b.Toolchains) > 0 {
+		fmt.Println("## Toolchain")
+		probes := make([]string, 0, len(b.Toolchains))
+		for p := range b.Toolchains {
+			probes = append(probes, p)
+		}
+		sort.Strings(probes)
+		for _, p := range probes {
+			fmt.Printf("  %s: %s\n", p, b.Toolchains[p])
+		}
+		fmt.Println()
+	}
+
+	if b.Tmux != nil && len(b.Tmux.Windows) > 0 {
+		fmt.Printf("## Tmux Layout (session %s)\n", b.Tmux.Session)
+		for _, w := range b.Tmux.Windows {
+			fmt.Printf("  %d: %s  (%d panes)\n", w.Index, w.Name, len(w.Panes))
+			for _, p := range w.Panes {
+				fmt.Printf("      %s  %s\n", p.Command, p.Dir)
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(b.Processes) > 0 {
+		fmt.Println("## Running Processes")
+		for _, p := range b.Processes {
+			fmt.Printf("  %s\n", p)
+		}
+		fmt.Println()
+	}
+
+	if len(b.Warnings) > 0 {
+		fmt.Println("## Warnings")
+		for _, w := range b.Warnings {
+			fmt.Printf("  %s\n", w)
+		}
+		fmt.Println()
+	}
+}
+
+// printSummary writes the Summary section. It only needs the bundle header,
+// so "view --summary" can print it without decoding diffs (and without tags
+// or languages).
+func printSummary(meta *bundle.SessionMeta, counts bundle.Counts, tags []string, languages map[string]int) {
+	fmt.Println("## Summary")
+	if meta.ProjectName != "" {
+		fmt.Printf("  Project:   %s\n", meta.ProjectName)
+	}
+	fmt.Printf("  Work dir:  %s\n", meta.WorkDir)
+	fmt.Printf("  Started:   %s\n", viewTime(meta.StartTime).Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("  Stopped:   %s\n", viewTime(meta.StopTime).Format("2006-01-02 15:04:05 MST"))
+	fmt.Printf("  Duration:  %s\n", meta.Duration)
+	if line := meta.DiffStatLine(); line != "" {
+		fmt.Printf("  Changes:   %s\n", line)
+	}
+	if len(tags) > 0 {
+		fmt.Printf("  Tags:      %s\n", strings.Join(tags, ", "))
+	}
+	if len(languages) > 0 {
+		fmt.Printf("  Languages: %s\n", bundle.FormatLanguages(bundle.LanguageBreakdown(languages)))
+	}
+	if counts.HasGit {
+		fmt.Printf("  Branch:    %s\n", counts.Branch)
+		fmt.Printf("  Commit:    %s\n", counts.HeadCommit)
+	}
+	fmt.Printf("  Contents:  %d annotations, %d file edits, %d commands, %d editor tabs\n",
+		counts.Annotations, counts.FileEdits, counts.Commands, counts.EditorTabs)
+	fmt.Println()
+}
+
 func indent(s, prefix string) string {
 	lines := strings.Split(s, "\n")
 	for i, line := range lines {
@@ -144,5 +405,10 @@ func indent(s, prefix string) string {
 
 func init() {
 	viewCmd.Flags().BoolVar(&plainOutput, "plain", false, "plain text output instead of TUI")
+	viewCmd.Flags().BoolVar(&viewRaw, "raw", false, "show annotation text as written instead of rendering its Markdown")
+	viewCmd.Flags().BoolVar(&summaryOnly, "summary", false, "print only the summary section (fast for large bundles)")
+	viewCmd.Flags().StringSliceVar(&viewSectionNames, "section", nil, "print only this section as plain text: summary, annotations, fileedits, git, commands or editortabs (repeatable)")
+	viewCmd.Flags().StringVar(&viewTZ, "tz", "", "show times in this zone: an IANA name such as Europe/Berlin, local or utc (default from profile, else as recorded)")
+	viewCmd.Flags().StringVar(&viewTheme, "theme", "", "color theme: dark, light or nocolor (default from profile, or nocolor if NO_COLOR is set)")
 	rootCmd.AddCommand(viewCmd)
 }
@@ -156,6 +156,24 @@ func TestViewInvalidBundle(t *testing.T) {
 	}
 }
 
+// TestViewRejectsJSONL verifies that "view" refuses a JSON Lines export with
+// a message saying why.
+func TestViewRejectsJSONL(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+
+	path := filepath.Join(tmp, "handoff.jsonl")
+	if err := os.WriteFile(path, []byte(`{"type":"session"}`+"\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "view", path)
+	if err == nil || !strings.Contains(err.Error(), "JSON Lines export and cannot be read back") {
+		t.Errorf("expected a JSON Lines error, got %v", err)
+	}
+}
+
 // Feature: handoff, Property 12: View section order
 func TestViewSectionOrder(t *testing.T) {
 	// The required section order as printed by printBundle.
@@ -199,3 +217,215 @@ func TestViewSectionOrder(t *testing.T) {
 		}
 	})
 }
+
+// TestViewSummaryOnly verifies that "view --summary" prints the summary with
+// collection counts and skips the other sections.
+func TestViewSummaryOnly(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	path := filepath.Join(tmp, "bundle.json")
+	writeOpenBundle(t, tmp, "bundle.json", "/work/summary", time.Now())
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "view", "--summary", path)
+	})
+	summaryOnly = false
+	if runErr != nil {
+		t.Fatalf("view --summary: %v", runErr)
+	}
+	if !strings.Contains(stdout, "Work dir:  /work/summary") || !strings.Contains(stdout, "Contents:  0 annotations") {
+		t.Errorf("expected summary with counts, got:\n%s", stdout)
+	}
+	if strings.Contains(stdout, "## Annotations") {
+		t.Errorf("expected only the summary section, got:\n%s", stdout)
+	}
+}
+
+// TestViewSection verifies that --section prints just the chosen sections,
+// in the usual order whatever order they were given in, and rejects unknown
+// names.
+func TestViewSection(t *testing.T) {
+	t.Cleanup(func() { viewSectionNames = nil })
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	path := filepath.Join(tmp, "bundle.json")
+	writeOpenBundle(t, tmp, "bundle.json", "/work/section", time.Now())
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "view", "--section", "commands", "--section", "annotations", path)
+	})
+	if runErr != nil {
+		t.Fatalf("view --section: %v", runErr)
+	}
+	ann, cmds := strings.Index(stdout, "## Annotations"), strings.Index(stdout, "## Terminal Commands")
+	if ann == -1 || cmds == -1 || ann > cmds {
+		t.Errorf("expected Annotations then Terminal Commands, got:\n%s", stdout)
+	}
+	for _, other := range []string{"## Summary", "## File Edits", "## Git Changes", "## Editor Tabs"} {
+		if strings.Contains(stdout, other) {
+			t.Errorf("unexpected %s in:\n%s", other, stdout)
+		}
+	}
+
+	viewSectionNames = nil
+	_, err := executeCommand(rootCmd, "view", "--section", "diffs", path)
+	if err == nil || !strings.Contains(err.Error(), "unknown section") {
+		t.Errorf("expected an unknown-section error, got %v", err)
+	}
+}
+
+// TestViewTimeZone verifies that --tz shows times converted to that zone,
+// and that an unknown zone is an error.
+func TestViewTimeZone(t *testing.T) {
+	t.Cleanup(func() { viewTZ, viewLoc, summaryOnly = "", nil, false })
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	start := time.Date(2026, 3, 2, 9, 30, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+	b := &bundle.ContextBundle{Session: bundle.SessionMeta{WorkDir: "/work/tz", StartTime: start, StopTime: start.Add(time.Hour)}}
+	data, err := (&bundle.JSONRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(tmp, "bundle.json")
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	rootCmd.ResetFlags()
+	var runErr error
+	stdout := captureStdout(t, func() {
+		_, runErr = executeCommand(rootCmd, "view", "--summary", "--tz", "utc", path)
+	})
+	if runErr != nil {
+		t.Fatalf("view --tz utc: %v", runErr)
+	}
+	if !strings.Contains(stdout, "Started:   2026-03-02 07:30:00 UTC") {
+		t.Errorf("expected the start time in UTC, got:\n%s", stdout)
+	}
+
+	_, err = executeCommand(rootCmd, "view", "--summary", "--tz", "Mars/Olympus", path)
+	if err == nil || !strings.Contains(err.Error(), "unknown time zone") {
+		t.Errorf("expected an unknown-zone error, got %v", err)
+	}
+}
+
+// TestBundleSaver verifies that the viewer writes an edited bundle back in
+// its file's format and layout, reports a signature, and drops it on save.
+func TestBundleSaver(t *testing.T) {
+	dir := t.TempDir()
+	key := []byte("s3cret")
+	b := &bundle.ContextBundle{Session: bundle.SessionMeta{ID: "edit", WorkDir: "/work/edit"}}
+
+	md, err := (&bundle.MarkdownRenderer{Frontmatter: true}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mdPath := filepath.Join(dir, "bundle.md")
+	if err := os.WriteFile(mdPath, bundle.SignMarkdown(md, key), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	jsonData, err := (&bundle.JSONRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed := *b
+	if signed.Signature, err = bundle.SignJSON(jsonData, key); err != nil {
+		t.Fatal(err)
+	}
+	if jsonData, err = (&bundle.JSONRenderer{}).Render(&signed); err != nil {
+		t.Fatal(err)
+	}
+	jsonPath := filepath.Join(dir, "bundle.json")
+	if err := os.WriteFile(jsonPath, jsonData, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{mdPath, jsonPath} {
+		got, err := readBundle(path)
+		if err != nil {
+			t.Fatalf("readBundle %s: %v", path, err)
+		}
+		save, isSigned, err := bundleSaver(path, got)
+		if err != nil {
+			t.Fatalf("bundleSaver %s: %v", path, err)
+		}
+		if !isSigned {
+			t.Errorf("%s: expected the signature to be reported", path)
+		}
+		got.Annotations = append(got.Annotations, session.Annotation{Message: "added in the viewer", Timestamp: time.Now()})
+		if err := save(got); err != nil {
+			t.Fatalf("save %s: %v", path, err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved, err := readBundle(path)
+		if err != nil {
+			t.Fatalf("re-read %s: %v", path, err)
+		}
+		if len(saved.Annotations) != 1 || saved.Signature != "" || bundle.MarkdownSignature(data) != "" {
+			t.Errorf("%s: got annotations %+v, signature %q:\n%s", path, saved.Annotations, saved.Signature, data)
+		}
+		if path == mdPath && !strings.HasPrefix(string(data), "---\n") {
+			t.Errorf("%s: frontmatter dropped:\n%s", path, data)
+		}
+		if path == jsonPath && !strings.HasPrefix(string(data), "{") {
+			t.Errorf("%s: not written as JSON:\n%s", path, data)
+		}
+	}
+}
+
+// TestBundleSaverEncrypted verifies that the viewer writes an encrypted
+// bundle back encrypted with the same passphrase, in the format under .age.
+func TestBundleSaverEncrypted(t *testing.T) {
+	t.Setenv(passphraseEnv, "pass")
+	b := &bundle.ContextBundle{Session: bundle.SessionMeta{ID: "enc", WorkDir: "/work/enc"}}
+	data, err := (&bundle.JSONRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := bundle.Encrypt(data, "pass")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "bundle.json"+bundle.EncryptedExt)
+	if err := os.WriteFile(path, enc, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readBundle(path)
+	if err != nil {
+		t.Fatalf("readBundle: %v", err)
+	}
+	save, _, err := bundleSaver(path, got)
+	if err != nil {
+		t.Fatalf("bundleSaver: %v", err)
+	}
+	got.Annotations = append(got.Annotations, session.Annotation{Message: "added in the viewer", Timestamp: time.Now()})
+	if err := save(got); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(written), "added in the viewer") {
+		t.Fatalf("bundle written in plaintext:\n%s", written)
+	}
+	plain, err := bundle.Decrypt(written, "pass")
+	if err != nil {
+		t.Fatalf("Decrypt: %v", err)
+	}
+	if !strings.HasPrefix(string(plain), "{") || !strings.Contains(string(plain), "added in the viewer") {
+		t.Errorf("decrypted bundle is not the edited JSON:\n%s", plain)
+	}
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+var watchShowIgnored bool
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Run the file watcher in the foreground, printing each edit it records",
+	Long: "Watch the active session's work dir in this terminal until Ctrl-C (or until\n" +
+		"the session is stopped), recording edits into the session like the\n" +
+		"background watcher does and printing each one as it is saved. Use\n" +
+		"--show-ignored to also see the changes the ignore patterns leave out.",
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+		var workDir string
+		rel := func(path string) string {
+			if r, err := filepath.Rel(workDir, path); err == nil {
+				return r
+			}
+			return path
+		}
+
+		opts := collector.WatchOptions{
+			OnEdit: func(path string, at time.Time) {
+				fmt.Fprintf(out, "%s  %s\n", at.Format("15:04:05"), rel(path))
+			},
+		}
+		if watchShowIgnored {
+			opts.OnIgnored = func(path string) {
+				fmt.Fprintf(out, "%s  %s (ignored)\n", time.Now().Format("15:04:05"), rel(path))
+			}
+		}
+		return watchSession(func(s *session.Session) {
+			workDir = s.WorkDir
+			cmd.Printf("Watching %s (Ctrl-C to stop)\n", s.WorkDir)
+			if s.WatcherPID != 0 && processAlive(s.WatcherPID) {
+				cmd.Printf("The background watcher (pid %d) is recording edits too.\n", s.WatcherPID)
+			}
+		}, opts)
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchShowIgnored, "show-ignored", false, "Also print changes to ignored files")
+	addSessionNameFlag(watchCmd)
+	rootCmd.AddCommand(watchCmd)
+}
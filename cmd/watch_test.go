@@ -0,0 +1,42 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestWatchNoSession verifies that "watch" fails when there is no active
+// session to record into.
+func TestWatchNoSession(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	t.Setenv("HOME", tmp)
+
+	rootCmd.ResetFlags()
+	_, err := executeCommand(rootCmd, "watch")
+	if err == nil || !strings.Contains(err.Error(), "no active session") {
+		t.Errorf("expected a no-active-session error, got %v", err)
+	}
+}
+
+// TestWatcherArgs verifies that the background watcher is given the
+// session name and the global config and logging flags start ran with.
+func TestWatcherArgs(t *testing.T) {
+	t.Cleanup(func() { sessionName, configPath, noProjectConfig, verbose = "", "", false, false })
+	if got := strings.Join(watcherArgs(), " "); got != "_watch" {
+		t.Errorf("without flags: got %q, want %q", got, "_watch")
+	}
+
+	t.Chdir(t.TempDir())
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sessionName, configPath, noProjectConfig, verbose = "api", "ci.json", true, true
+	want := "_watch --name api --config " + filepath.Join(cwd, "ci.json") + " --no-project-config --verbose"
+	if got := strings.Join(watcherArgs(), " "); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
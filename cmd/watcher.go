@@ -0,0 +1,129 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/fakeyudi/handoff/internal/collector"
+	"github.com/fakeyudi/handoff/internal/config"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// watcherPollInterval is how often the background watcher checks that its
+// session is still active.
+const watcherPollInterval = 2 * time.Second
+
+// launchWatcher starts the background watcher for the --name session and
+// returns its process ID. It is a variable so tests can stub it out.
+var launchWatcher = spawnWatcher
+
+// watcherCmd is the background file watcher started by `handoff start`. It
+// records edits into the session until the session is stopped.
+var watcherCmd = &cobra.Command{
+	Use:    "_watch",
+	Short:  "Run the background file watcher for a session",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return watchSession(nil, collector.WatchOptions{})
+	},
+}
+
+// watchSession runs the file watcher on the --name session's work dir until
+// it is interrupted or the session is stopped. started, if set, is called
+// with the session once it is loaded. The ignore settings in opts come from
+// the config.
+func watchSession(started func(*session.Session), opts collector.WatchOptions) error {
+	store, err := openSessionStore()
+	if err != nil {
+		return err
+	}
+	s, err := store.Load()
+	if err != nil {
+		if errors.Is(err, session.ErrNoSession) {
+			return noActiveSessionError()
+		}
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	// Exit once the session is stopped (or replaced by a new one).
+	go func() {
+		ticker := time.NewTicker(watcherPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			cur, err := store.Load()
+			if errors.Is(err, session.ErrNoSession) || (err == nil && cur.ID != s.ID) {
+				cancel()
+				return
+			}
+		}
+	}()
+
+	if started != nil {
+		started(s)
+	}
+	cfg := GetConfig()
+	opts.SkipGitignore = !config.Enabled(cfg.RespectGitignore)
+	return collector.Watch(ctx, s.WorkDir, store, cfg.IgnorePatterns, opts)
+}
+
+// spawnWatcher starts `handoff _watch` as a detached process, so it outlives
+// the start command and the terminal it ran in.
+func spawnWatcher() (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	c := exec.Command(exe, watcherArgs()...)
+	c.SysProcAttr = detachedProcAttr()
+	if err := c.Start(); err != nil {
+		return 0, fmt.Errorf("start file watcher: %w", err)
+	}
+	pid := c.Process.Pid
+	return pid, c.Process.Release()
+}
+
+// watcherArgs returns the arguments for the `handoff _watch` child: the
+// session's --name and the global flags start was given, so the watcher
+// reads the same config as start and stop.
+func watcherArgs() []string {
+	args := []string{"_watch"}
+	if sessionName != "" {
+		args = append(args, "--name", sessionName)
+	}
+	if configPath != "" {
+		path := configPath
+		if abs, err := filepath.Abs(path); err == nil {
+			path = abs
+		}
+		args = append(args, "--config", path)
+	}
+	if noProjectConfig {
+		args = append(args, "--no-project-config")
+	}
+	if verbose {
+		args = append(args, "--verbose")
+	}
+	return args
+}
+
+func init() {
+	addSessionNameFlag(watcherCmd)
+	rootCmd.AddCommand(watcherCmd)
+}
@@ -8,12 +8,67 @@ import (
 
 // ContextBundle is the complete, renderable representation of a handoff.
 type ContextBundle struct {
-	Session     SessionMeta          `json:"session"`
-	Annotations []session.Annotation `json:"annotations"`
-	FileEdits   []session.FileEdit   `json:"file_edits"`
-	Git         *GitInfo             `json:"git,omitempty"`
-	Commands    []Command            `json:"commands"`
-	EditorTabs  []string             `json:"editor_tabs"`
+	SchemaVersion int                  `json:"schema_version"` // set by the renderers; see SchemaVersion
+	Session       SessionMeta          `json:"session"`
+	Tags          []string             `json:"tags,omitempty"`
+	Annotations   []session.Annotation `json:"annotations"`
+	FileEdits     []session.FileEdit   `json:"file_edits"`
+	Git           *GitInfo             `json:"git,omitempty"`
+	Commands      []Command            `json:"commands"`
+	EditorTabs    []string             `json:"editor_tabs"`
+	Processes     []string             `json:"processes,omitempty"`  // running processes tied to the work dir
+	Env           map[string]string    `json:"env,omitempty"`        // allow-listed env vars, sensitive values redacted
+	Toolchains    map[string]string    `json:"toolchains,omitempty"` // probe command → version output
+	BrowserTabs   []BrowserTab         `json:"browser_tabs,omitempty"`
+	Warnings      []string             `json:"warnings,omitempty"` // non-fatal collector issues
+
+	// Languages counts edited files by extension (".go" → 12), and
+	// PrimaryLanguage names the language with the most; see SetLanguages.
+	Languages       map[string]int `json:"languages,omitempty"`
+	PrimaryLanguage string         `json:"primary_language,omitempty"`
+
+	// Tmux is the tmux layout the session ran in; nil outside tmux.
+	Tmux *TmuxLayout `json:"tmux,omitempty"`
+
+	// DiffsOmitted is set by OmitDiffs: the bundle was made without diffs,
+	// so empty ones don't mean nothing changed.
+	DiffsOmitted bool `json:"diffs_omitted,omitempty"`
+
+	// Signature is set by `handoff sign` on JSON bundles; Markdown bundles
+	// keep theirs in a handoff-signature comment instead. See SignJSON.
+	Signature string `json:"signature,omitempty"`
+}
+
+// BrowserTab is a web page open in the browser at session stop.
+type BrowserTab struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// TmuxLayout describes the windows and panes of a tmux session, enough to
+// recreate it with `handoff restore-tmux`.
+type TmuxLayout struct {
+	Session string       `json:"session"`
+	Windows []TmuxWindow `json:"windows"`
+}
+
+// TmuxWindow is one tmux window. Layout is tmux's layout string, which
+// select-layout accepts to restore the pane sizes.
+type TmuxWindow struct {
+	Index  int        `json:"index"`
+	Name   string     `json:"name"`
+	Layout string     `json:"layout"`
+	Active bool       `json:"active,omitempty"`
+	Panes  []TmuxPane `json:"panes"`
+}
+
+// TmuxPane is one pane of a tmux window: the program running in it and its
+// working directory.
+type TmuxPane struct {
+	Index   int    `json:"index"`
+	Command string `json:"command"`
+	Dir     string `json:"dir"`
+	Active  bool   `json:"active,omitempty"`
 }
 
 // SessionMeta holds summary metadata about the session for the bundle.
@@ -24,6 +79,15 @@ type SessionMeta struct {
 	WorkDir   string    `json:"work_dir"`
 	Duration  string    `json:"duration"` // human-readable, e.g. "2h15m"
 	Author    string    `json:"author,omitempty"`
+	// ProjectName says what the work dir holds: its README's title, its
+	// package or module name, or else its base name.
+	ProjectName string `json:"project_name,omitempty"`
+
+	// Size of the session's changes, from the captured diffs; see
+	// ContextBundle.SetDiffStat.
+	FilesChanged int `json:"files_changed,omitempty"`
+	Insertions   int `json:"insertions,omitempty"`
+	Deletions    int `json:"deletions,omitempty"`
 }
 
 // GitInfo holds git repository state captured at session stop.
@@ -33,10 +97,55 @@ type GitInfo struct {
 	Diff       string   `json:"diff"`
 	StagedDiff string   `json:"staged_diff"`
 	RecentLog  []string `json:"recent_log"` // commits during session window
+	// RecentLogOmitted counts the commits left out of RecentLog by the
+	// git_log_limit cap.
+	RecentLogOmitted int `json:"recent_log_omitted,omitempty"`
+	// UntrackedFiles lists new files git doesn't know about yet, relative to
+	// the work dir; UntrackedDiff shows each of them as an addition.
+	UntrackedFiles []string `json:"untracked_files,omitempty"`
+	UntrackedDiff  string   `json:"untracked_diff,omitempty"`
+	// Stashes lists `git stash list`, newest first, e.g.
+	// "stash@{0}: WIP on main: 1a2b3c4 fix login".
+	Stashes []string `json:"stashes,omitempty"`
 }
 
-// Command represents a single terminal command from shell history.
-type Command struct {
-	Raw       string    `json:"raw"`
-	Timestamp time.Time `json:"timestamp"` // zero if shell doesn't record timestamps
+// Command is a single terminal command from shell history.
+type Command = session.Command
+
+// LastFailed returns the most recent command that exited non-zero, or nil.
+func LastFailed(cmds []Command) *Command {
+	for i := len(cmds) - 1; i >= 0; i-- {
+		if cmds[i].Failed() {
+			return &cmds[i]
+		}
+	}
+	return nil
+}
+
+
+// DedupeCommands collapses repeated runs of the same command line into one
+// entry at the position of its first run, counting the runs.
+func DedupeCommands(cmds []Command) []Command {
+	var out []Command
+	index := make(map[string]int)
+	for _, c := range cmds {
+		i, ok := index[c.Raw]
+		if !ok {
+			index[c.Raw] = len(out)
+			out = append(out, c)
+			continue
+		}
+		d := &out[i]
+		if d.Count == 0 {
+			d.Count = 1
+		}
+		d.Count++
+		if c.Timestamp.After(d.LastTimestamp) {
+			d.LastTimestamp = c.Timestamp
+		}
+		if c.ExitCode != nil {
+			d.ExitCode = c.ExitCode
+		}
+	}
+	return out
 }
@@ -0,0 +1,44 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDedupeCommands verifies that repeated command lines collapse into the
+// entry of their first run, with a count and the last run's timestamp.
+func TestDedupeCommands(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return t0.Add(time.Duration(m) * time.Minute) }
+
+	got := DedupeCommands([]Command{
+		{Raw: "make test", Timestamp: at(1)},
+		{Raw: "vim main.go", Timestamp: at(2)},
+		{Raw: "make test", Timestamp: at(3)},
+		{Raw: "make test", Timestamp: at(5)},
+	})
+	want := []Command{
+		{Raw: "make test", Timestamp: at(1), Count: 3, LastTimestamp: at(5)},
+		{Raw: "vim main.go", Timestamp: at(2)},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d commands, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("command %d: got %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	md, err := (&MarkdownRenderer{}).Render(&ContextBundle{Commands: got})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "| 1 | "+at(1).Format("2006-01-02 15:04:05")+" | `make test` ×3 |\n") {
+		t.Errorf("expected counted command in Markdown, got:\n%s", md)
+	}
+	if !strings.Contains(string(md), "| 2 | "+at(2).Format("2006-01-02 15:04:05")+" | `vim main.go` |\n") {
+		t.Errorf("expected single run without a count, got:\n%s", md)
+	}
+}
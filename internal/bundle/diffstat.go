@@ -0,0 +1,201 @@
+package bundle
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// FileDiffStat is the per-file line count summary of a unified diff, as
+// shown by `git diff --stat`.
+type FileDiffStat struct {
+	Path    string // "old => new" for renames
+	Added   int
+	Removed int
+	Binary  bool
+}
+
+// ParseDiffStat computes per-file added/removed line counts from a unified
+// diff. It understands git's extended headers (renames, binary markers) as
+// well as plain "---/+++" diffs such as the /dev/null fallback. Files appear
+// in the order they occur in the diff.
+func ParseDiffStat(diff string) []FileDiffStat {
+	var stats []*FileDiffStat
+	var cur *FileDiffStat
+	var renameFrom string
+	oldLeft, newLeft := 0, 0 // lines remaining in the current hunk
+
+	start := func(path string) {
+		cur = &FileDiffStat{Path: path}
+		stats = append(stats, cur)
+		renameFrom = ""
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		// Inside a hunk, the header's line counts say exactly how many lines
+		// belong to it, so content like "--- x" is never mistaken for a header.
+		if oldLeft > 0 || newLeft > 0 {
+			switch {
+			case strings.HasPrefix(line, "+"):
+				cur.Added++
+				newLeft--
+			case strings.HasPrefix(line, "-"):
+				cur.Removed++
+				oldLeft--
+			case strings.HasPrefix(line, `\`):
+				// "\ No newline at end of file"
+			default:
+				oldLeft--
+				newLeft--
+			}
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			start(gitDiffPath(line))
+		case strings.HasPrefix(line, "rename from "):
+			renameFrom = strings.TrimPrefix(line, "rename from ")
+		case strings.HasPrefix(line, "rename to ") && cur != nil:
+			cur.Path = renameFrom + " => " + strings.TrimPrefix(line, "rename to ")
+		case strings.HasPrefix(line, "Binary files "), strings.HasPrefix(line, "GIT binary patch"):
+			if cur != nil {
+				cur.Binary = true
+			}
+		case strings.HasPrefix(line, "--- "):
+			// A plain diff without a "diff --git" line starts a new file here.
+			if cur == nil || cur.Added > 0 || cur.Removed > 0 {
+				start(stripDiffPrefix(strings.TrimPrefix(line, "--- ")))
+			}
+		case strings.HasPrefix(line, "+++ "):
+			if p := stripDiffPrefix(strings.TrimPrefix(line, "+++ ")); p != "/dev/null" && cur != nil && renameFrom == "" {
+				cur.Path = p
+			}
+		case strings.HasPrefix(line, "@@ ") && cur != nil:
+			oldLeft, newLeft = parseHunkCounts(line)
+		}
+	}
+
+	result := make([]FileDiffStat, len(stats))
+	for i, st := range stats {
+		result[i] = *st
+	}
+	return result
+}
+
+// gitDiffPath extracts the new-side path from a "diff --git a/x b/y" line.
+func gitDiffPath(line string) string {
+	rest := strings.TrimPrefix(line, "diff --git ")
+	if i := strings.LastIndex(rest, " b/"); i >= 0 {
+		return rest[i+3:]
+	}
+	return stripDiffPrefix(rest)
+}
+
+// stripDiffPrefix removes the a/ or b/ prefix git adds to paths, along with
+// any trailing tab-separated timestamp from plain diff headers.
+func stripDiffPrefix(p string) string {
+	if i := strings.Index(p, "\t"); i >= 0 {
+		p = p[:i]
+	}
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		return p[2:]
+	}
+	return p
+}
+
+// parseHunkCounts returns the old and new line counts from a hunk header
+// such as "@@ -1,5 +1,7 @@". An omitted count means 1.
+func parseHunkCounts(header string) (int, int) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 {
+		return 0, 0
+	}
+	return rangeCount(fields[1]), rangeCount(fields[2])
+}
+
+func rangeCount(r string) int {
+	r = strings.TrimLeft(r, "-+")
+	_, count, found := strings.Cut(r, ",")
+	if !found {
+		return 1
+	}
+	n, err := strconv.Atoi(count)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// MergeDiffStats combines the per-file stats of several diffs, summing the
+// counts for files that appear in more than one (e.g. staged and unstaged).
+func MergeDiffStats(diffs ...string) []FileDiffStat {
+	var merged []FileDiffStat
+	index := make(map[string]int)
+	for _, d := range diffs {
+		for _, st := range ParseDiffStat(d) {
+			i, ok := index[st.Path]
+			if !ok {
+				index[st.Path] = len(merged)
+				merged = append(merged, st)
+				continue
+			}
+			merged[i].Added += st.Added
+			merged[i].Removed += st.Removed
+			merged[i].Binary = merged[i].Binary || st.Binary
+		}
+	}
+	return merged
+}
+
+// SetDiffStat fills in the session's FilesChanged, Insertions and Deletions
+// from the captured diffs. The per-file diffs of the file edits are used
+// when there are any; otherwise the git section's diffs are.
+func (b *ContextBundle) SetDiffStat() {
+	var diffs []string
+	for _, fe := range b.FileEdits {
+		if fe.Diff != "" {
+			diffs = append(diffs, fe.Diff)
+		}
+	}
+	if len(diffs) == 0 && b.Git != nil {
+		diffs = []string{b.Git.StagedDiff, b.Git.Diff, b.Git.UntrackedDiff}
+	}
+
+	s := &b.Session
+	s.FilesChanged, s.Insertions, s.Deletions = 0, 0, 0
+	for _, st := range MergeDiffStats(diffs...) {
+		if st.Added == 0 && st.Removed == 0 && !st.Binary {
+			continue
+		}
+		s.FilesChanged++
+		s.Insertions += st.Added
+		s.Deletions += st.Removed
+	}
+}
+
+// OmitDiffs removes every diff from the bundle, for sharing which files
+// changed without the code itself. Call it after SetDiffStat so the
+// session's change counts are kept.
+func (b *ContextBundle) OmitDiffs() {
+	for i := range b.FileEdits {
+		b.FileEdits[i].Diff = ""
+	}
+	if b.Git != nil {
+		b.Git.Diff, b.Git.StagedDiff, b.Git.UntrackedDiff = "", "", ""
+	}
+	b.DiffsOmitted = true
+}
+
+// DiffStatLine summarizes the session's diff stat, e.g.
+// "12 files changed, +340 −88". It is empty when no files changed.
+func (s *SessionMeta) DiffStatLine() string {
+	if s.FilesChanged == 0 {
+		return ""
+	}
+	files := "files"
+	if s.FilesChanged == 1 {
+		files = "file"
+	}
+	return fmt.Sprintf("%d %s changed, +%d −%d", s.FilesChanged, files, s.Insertions, s.Deletions)
+}
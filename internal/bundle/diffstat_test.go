@@ -0,0 +1,93 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestParseDiffStat covers modified, renamed, binary and /dev/null fallback
+// diffs, including a removed line that looks like a file header.
+func TestParseDiffStat(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
+-// old
+--- not a header
++// new
++// newer
++import "fmt"
+diff --git a/old.txt b/new.txt
+similarity index 90%
+rename from old.txt
+rename to new.txt
+index 3333333..4444444 100644
+--- a/old.txt
++++ b/new.txt
+@@ -1 +1 @@
+-a
++b
+diff --git a/logo.png b/logo.png
+index 5555555..6666666 100644
+Binary files a/logo.png and b/logo.png differ
+`
+	got := ParseDiffStat(diff)
+	want := []FileDiffStat{
+		{Path: "main.go", Added: 3, Removed: 2},
+		{Path: "old.txt => new.txt", Added: 1, Removed: 1},
+		{Path: "logo.png", Binary: true},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(got), len(want), got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("stats[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+
+	fallback := "--- /dev/null\n+++ /repo/notes.txt\n@@ -0,0 +1,2 @@\n+one\n+two"
+	got = ParseDiffStat(fallback)
+	if len(got) != 1 || got[0] != (FileDiffStat{Path: "/repo/notes.txt", Added: 2}) {
+		t.Errorf("fallback diff stats = %+v", got)
+	}
+}
+
+// TestSetDiffStat verifies that the session totals come from the file edit
+// diffs, git and fallback alike, and fall back to the git section when no
+// edit has a diff.
+func TestSetDiffStat(t *testing.T) {
+	b := &ContextBundle{
+		FileEdits: []session.FileEdit{
+			{Path: "/repo/main.go", Diff: "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n-old\n+new\n same"},
+			{Path: "/repo/notes.txt", Diff: "--- /dev/null\n+++ /repo/notes.txt\n@@ -0,0 +1,2 @@\n+one\n+two"},
+			{Path: "/repo/unchanged.go"},
+		},
+		Git: &GitInfo{Diff: "diff --git a/x b/x\n--- a/x\n+++ b/x\n@@ -1 +1 @@\n-a\n+b"},
+	}
+	b.SetDiffStat()
+	if s := b.Session; s.FilesChanged != 2 || s.Insertions != 3 || s.Deletions != 1 {
+		t.Errorf("from file edits: got %d files +%d -%d, want 2 files +3 -1", s.FilesChanged, s.Insertions, s.Deletions)
+	}
+	if got, want := b.Session.DiffStatLine(), "2 files changed, +3 −1"; got != want {
+		t.Errorf("DiffStatLine() = %q, want %q", got, want)
+	}
+
+	md, err := (&MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(md), "- Changes: 2 files changed, +3 −1\n") {
+		t.Errorf("expected the diff stat in the Markdown summary, got:\n%s", md)
+	}
+
+	b.FileEdits = nil
+	b.SetDiffStat()
+	if got, want := b.Session.DiffStatLine(), "1 file changed, +1 −1"; got != want {
+		t.Errorf("from git: DiffStatLine() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,71 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"filippo.io/age"
+)
+
+// EncryptedExt is appended to the file name of an encrypted bundle, after
+// its format's own extension: "handoff-….md.age". The files are ordinary
+// passphrase-encrypted age files, so `age -d` opens them too.
+const EncryptedExt = ".age"
+
+// ageHeader starts every age file.
+const ageHeader = "age-encryption.org/v1\n"
+
+// ErrBadPassphrase is returned by Decrypt when the passphrase is wrong or the
+// data was tampered with.
+var ErrBadPassphrase = errors.New("wrong passphrase, or the bundle is corrupt")
+
+// Encrypt seals a rendered bundle with passphrase, using age's scrypt
+// recipient.
+func Encrypt(plaintext []byte, passphrase string) ([]byte, error) {
+	if passphrase == "" {
+		return nil, errors.New("empty passphrase")
+	}
+	recipient, err := age.NewScryptRecipient(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	var out bytes.Buffer
+	w, err := age.Encrypt(&out, recipient)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	if _, err := w.Write(plaintext); err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return nil, fmt.Errorf("encrypt: %w", err)
+	}
+	return out.Bytes(), nil
+}
+
+// Decrypt opens data sealed by Encrypt.
+func Decrypt(data []byte, passphrase string) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return nil, errors.New("not an encrypted handoff bundle")
+	}
+	identity, err := age.NewScryptIdentity(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	r, err := age.Decrypt(bytes.NewReader(data), identity)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	plaintext, err := io.ReadAll(r)
+	if err != nil {
+		return nil, ErrBadPassphrase
+	}
+	return plaintext, nil
+}
+
+// IsEncrypted reports whether data is an encrypted bundle.
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(ageHeader))
+}
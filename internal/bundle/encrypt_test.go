@@ -0,0 +1,40 @@
+package bundle
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestEncryptRoundTrip verifies that Decrypt recovers what Encrypt sealed,
+// that the plaintext doesn't appear in the output, and that a wrong
+// passphrase or a flipped byte is rejected.
+func TestEncryptRoundTrip(t *testing.T) {
+	plaintext := []byte("# Handoff\n\nsecret diff contents\n")
+	sealed, err := Encrypt(plaintext, "correct horse")
+	if err != nil {
+		t.Fatalf("Encrypt: %v", err)
+	}
+	if !IsEncrypted(sealed) || IsEncrypted(plaintext) {
+		t.Error("IsEncrypted does not tell sealed and plain bundles apart")
+	}
+	if bytes.Contains(sealed, []byte("secret")) {
+		t.Error("plaintext visible in the encrypted bundle")
+	}
+
+	got, err := Decrypt(sealed, "correct horse")
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Decrypt: got %q, %v", got, err)
+	}
+	if _, err := Decrypt(sealed, "wrong"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("wrong passphrase: got %v, want ErrBadPassphrase", err)
+	}
+	tampered := bytes.Clone(sealed)
+	tampered[len(tampered)-1] ^= 1
+	if _, err := Decrypt(tampered, "correct horse"); !errors.Is(err, ErrBadPassphrase) {
+		t.Errorf("tampered bundle: got %v, want ErrBadPassphrase", err)
+	}
+	if _, err := Encrypt(plaintext, ""); err == nil {
+		t.Error("expected an error for an empty passphrase")
+	}
+}
@@ -0,0 +1,175 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// HeaderParser reads just the session metadata and collection sizes of a
+// bundle, without materializing diffs and other large fields.
+type HeaderParser interface {
+	ParseHeader(data []byte) (*SessionMeta, Counts, error)
+}
+
+// Counts holds the number of entries in each of a bundle's collections.
+type Counts struct {
+	Annotations int
+	FileEdits   int
+	Commands    int
+	EditorTabs  int
+	BrowserTabs int
+	Processes   int
+	Warnings    int
+	// HasGit reports whether the bundle has a git section; Branch and
+	// HeadCommit are copied from it so summaries don't need to decode diffs.
+	HasGit     bool
+	Branch     string
+	HeadCommit string
+}
+
+// Counts returns the collection sizes of b.
+func (b *ContextBundle) Counts() Counts {
+	c := Counts{
+		Annotations: len(b.Annotations),
+		FileEdits:   len(b.FileEdits),
+		Commands:    len(b.Commands),
+		EditorTabs:  len(b.EditorTabs),
+		BrowserTabs: len(b.BrowserTabs),
+		Processes:   len(b.Processes),
+		Warnings:    len(b.Warnings),
+	}
+	if b.Git != nil {
+		c.HasGit, c.Branch, c.HeadCommit = true, b.Git.Branch, b.Git.HeadCommit
+	}
+	return c
+}
+
+// ParseHeader decodes the session metadata and collection lengths of a JSON
+// bundle by streaming its top-level keys. Array elements are counted without
+// being decoded, and only the branch and head commit are read from git.
+func (p *JSONParser) ParseHeader(data []byte) (*SessionMeta, Counts, error) {
+	meta, counts, version, err := parseHeader(data)
+	if err != nil {
+		return nil, Counts{}, fmt.Errorf("failed to parse JSON bundle: %w", err)
+	}
+	if version > SchemaVersion {
+		return nil, Counts{}, newerSchemaError(version)
+	}
+	return meta, counts, nil
+}
+
+// ParseHeader is the Markdown counterpart of JSONParser.ParseHeader; it
+// decodes the embedded payload and streams it the same way.
+func (p *MarkdownParser) ParseHeader(data []byte) (*SessionMeta, Counts, error) {
+	jsonBytes, version, err := embeddedJSON(data)
+	if err != nil {
+		return nil, Counts{}, err
+	}
+	if version > SchemaVersion {
+		return nil, Counts{}, newerSchemaError(version)
+	}
+	meta, counts, _, err := parseHeader(jsonBytes)
+	if err != nil {
+		return nil, Counts{}, fmt.Errorf("not a valid handoff bundle: failed to parse embedded JSON: %w", err)
+	}
+	return meta, counts, nil
+}
+
+// countedKeys maps the JSON keys of ContextBundle's array fields to the
+// Counts field that receives their length.
+var countedKeys = map[string]func(*Counts) *int{
+	"annotations":  func(c *Counts) *int { return &c.Annotations },
+	"file_edits":   func(c *Counts) *int { return &c.FileEdits },
+	"commands":     func(c *Counts) *int { return &c.Commands },
+	"editor_tabs":  func(c *Counts) *int { return &c.EditorTabs },
+	"browser_tabs": func(c *Counts) *int { return &c.BrowserTabs },
+	"processes":    func(c *Counts) *int { return &c.Processes },
+	"warnings":     func(c *Counts) *int { return &c.Warnings },
+}
+
+// parseHeader streams the top-level object of a JSON bundle.
+func parseHeader(data []byte) (*SessionMeta, Counts, int, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if err := expectDelim(dec, '{'); err != nil {
+		return nil, Counts{}, 0, err
+	}
+
+	var meta SessionMeta
+	var counts Counts
+	var version int
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, Counts{}, 0, err
+		}
+		key, _ := tok.(string)
+
+		switch key {
+		case "session":
+			err = dec.Decode(&meta)
+		case "schema_version":
+			err = dec.Decode(&version)
+		case "git":
+			// Decoding into a struct without the diff fields skips them
+			// without allocating their contents.
+			var git *struct {
+				Branch     string `json:"branch"`
+				HeadCommit string `json:"head_commit"`
+			}
+			if err = dec.Decode(&git); err == nil && git != nil {
+				counts.HasGit, counts.Branch, counts.HeadCommit = true, git.Branch, git.HeadCommit
+			}
+		default:
+			if field, ok := countedKeys[key]; ok {
+				*field(&counts), err = countArray(dec)
+			} else {
+				err = dec.Decode(&skipValue{})
+			}
+		}
+		if err != nil {
+			return nil, Counts{}, 0, fmt.Errorf("%s: %w", key, err)
+		}
+	}
+	return &meta, counts, version, nil
+}
+
+// skipValue discards any JSON value it is decoded into.
+type skipValue struct{}
+
+func (*skipValue) UnmarshalJSON([]byte) error { return nil }
+
+// countArray consumes a JSON array (or null) from dec and returns its length.
+func countArray(dec *json.Decoder) (int, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return 0, err
+	}
+	if tok == nil {
+		return 0, nil
+	}
+	if d, ok := tok.(json.Delim); !ok || d != '[' {
+		return 0, fmt.Errorf("expected array, got %v", tok)
+	}
+	n := 0
+	for dec.More() {
+		if err := dec.Decode(&skipValue{}); err != nil {
+			return 0, err
+		}
+		n++
+	}
+	_, err = dec.Token() // closing ]
+	return n, err
+}
+
+// expectDelim consumes the next token from dec and checks it is want.
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if d, ok := tok.(json.Delim); !ok || d != want {
+		return fmt.Errorf("expected %q, got %v", want, tok)
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package bundle
+
+import (
+	"bytes"
+	"encoding/json"
+	"time"
+)
+
+// JSONLRenderer renders a ContextBundle as JSON Lines for log ingestion: a
+// session line followed by one typed event per annotation, file edit and
+// command. The output is flattened and cannot be parsed back into a bundle.
+type JSONLRenderer struct{}
+
+// jsonlSession is the first line of a JSON Lines bundle.
+type jsonlSession struct {
+	Type          string `json:"type"` // always "session"
+	SchemaVersion int    `json:"schema_version"`
+	SessionMeta
+	Tags   []string `json:"tags,omitempty"`
+	Branch string   `json:"branch,omitempty"`
+}
+
+// jsonlEvent is one annotation, file edit or command. Only the fields of
+// its type are set.
+type jsonlEvent struct {
+	Type string    `json:"type"` // "annotation", "file_edit" or "command"
+	TS   time.Time `json:"ts,omitzero"`
+
+	// annotation
+	Message   string `json:"message,omitempty"`
+	IsSummary bool   `json:"is_summary,omitempty"`
+	Level     string `json:"level,omitempty"`
+	Line      int    `json:"line,omitempty"`
+
+	// annotation (anchor) and file_edit
+	Path string `json:"path,omitempty"`
+	Diff string `json:"diff,omitempty"`
+
+	// command
+	Raw      string    `json:"raw,omitempty"`
+	Count    int       `json:"count,omitempty"`
+	LastTS   time.Time `json:"last_ts,omitzero"`
+	ExitCode *int      `json:"exit_code,omitempty"`
+}
+
+func (r *JSONLRenderer) Render(bundle *ContextBundle) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf) // Encode ends each value with a newline
+
+	head := jsonlSession{Type: "session", SchemaVersion: SchemaVersion, SessionMeta: bundle.Session, Tags: bundle.Tags}
+	if bundle.Git != nil {
+		head.Branch = bundle.Git.Branch
+	}
+	if err := enc.Encode(head); err != nil {
+		return nil, err
+	}
+
+	var events []jsonlEvent
+	for _, a := range bundle.Annotations {
+		events = append(events, jsonlEvent{Type: "annotation", TS: a.Timestamp, Message: a.Message,
+			IsSummary: a.IsSummary, Level: a.Level, Path: a.Path, Line: a.Line})
+	}
+	for _, fe := range bundle.FileEdits {
+		events = append(events, jsonlEvent{Type: "file_edit", TS: fe.Timestamp, Path: fe.Path, Diff: fe.Diff})
+	}
+	for _, c := range bundle.Commands {
+		events = append(events, jsonlEvent{Type: "command", TS: c.Timestamp, Raw: c.Raw,
+			Count: c.Count, LastTS: c.LastTimestamp, ExitCode: c.ExitCode})
+	}
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return nil, err
+		}
+	}
+	return buf.Bytes(), nil
+}
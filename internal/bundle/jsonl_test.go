@@ -0,0 +1,52 @@
+package bundle
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestJSONLRender verifies that a JSON Lines bundle starts with the session
+// line and has one typed event per annotation, file edit and command.
+func TestJSONLRender(t *testing.T) {
+	ts := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	b := &ContextBundle{
+		Session:     SessionMeta{ID: "s1", WorkDir: "/repo", Duration: "1h0m0s"},
+		Tags:        []string{"bugfix"},
+		Git:         &GitInfo{Branch: "main"},
+		Annotations: []session.Annotation{{Timestamp: ts, Message: "look here", Level: session.LevelWarn}},
+		FileEdits:   []session.FileEdit{{Path: "/repo/main.go", Timestamp: ts, Diff: "+x"}},
+		Commands:    []Command{{Raw: "make test", Timestamp: ts, Count: 2, LastTimestamp: ts.Add(time.Minute)}},
+	}
+
+	data, err := (&JSONLRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if len(lines) != 4 {
+		t.Fatalf("got %d lines, want 4:\n%s", len(lines), data)
+	}
+
+	var head map[string]any
+	if err := json.Unmarshal([]byte(lines[0]), &head); err != nil {
+		t.Fatalf("session line: %v", err)
+	}
+	if head["type"] != "session" || head["work_dir"] != "/repo" || head["branch"] != "main" {
+		t.Errorf("session line = %s", lines[0])
+	}
+
+	want := []string{
+		`{"type":"annotation","ts":"2026-03-01T09:00:00Z","message":"look here","level":"warn"}`,
+		`{"type":"file_edit","ts":"2026-03-01T09:00:00Z","path":"/repo/main.go","diff":"+x"}`,
+		`{"type":"command","ts":"2026-03-01T09:00:00Z","raw":"make test","count":2,"last_ts":"2026-03-01T09:01:00Z"}`,
+	}
+	for i, w := range want {
+		if lines[i+1] != w {
+			t.Errorf("line %d:\n got %s\nwant %s", i+2, lines[i+1], w)
+		}
+	}
+}
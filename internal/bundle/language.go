@@ -0,0 +1,127 @@
+package bundle
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// languageNames maps lowercased file extensions to the language they hold.
+// Extensions not listed here are reported as-is, without the dot.
+var languageNames = map[string]string{
+	".go":    "Go",
+	".ts":    "TypeScript",
+	".tsx":   "TypeScript",
+	".js":    "JavaScript",
+	".jsx":   "JavaScript",
+	".mjs":   "JavaScript",
+	".cjs":   "JavaScript",
+	".py":    "Python",
+	".rb":    "Ruby",
+	".rs":    "Rust",
+	".java":  "Java",
+	".kt":    "Kotlin",
+	".swift": "Swift",
+	".c":     "C",
+	".h":     "C",
+	".cc":    "C++",
+	".cpp":   "C++",
+	".hpp":   "C++",
+	".cs":    "C#",
+	".php":   "PHP",
+	".sh":    "Shell",
+	".bash":  "Shell",
+	".zsh":   "Shell",
+	".ps1":   "PowerShell",
+	".sql":   "SQL",
+	".html":  "HTML",
+	".css":   "CSS",
+	".scss":  "SCSS",
+	".md":    "Markdown",
+	".json":  "JSON",
+	".yaml":  "YAML",
+	".yml":   "YAML",
+	".toml":  "TOML",
+	".xml":   "XML",
+	".proto": "Protocol Buffers",
+}
+
+// LanguageCount is the number of edited files in one language.
+type LanguageCount struct {
+	Name  string
+	Files int
+}
+
+// TallyLanguages counts file edits by extension (e.g. ".go" → 12). The
+// extension is taken from the base name, as the ignore matcher does, and
+// lowercased. Files without an extension are not counted. Ignored files never
+// reach the bundle, so they aren't counted either.
+func TallyLanguages(edits []session.FileEdit) map[string]int {
+	var langs map[string]int
+	for _, fe := range edits {
+		ext := strings.ToLower(filepath.Ext(filepath.Base(fe.Path)))
+		if ext == "" || ext == "." {
+			continue
+		}
+		if langs == nil {
+			langs = make(map[string]int)
+		}
+		langs[ext]++
+	}
+	return langs
+}
+
+// LanguageName returns the language for a file extension such as ".go".
+func LanguageName(ext string) string {
+	if name, ok := languageNames[strings.ToLower(ext)]; ok {
+		return name
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// LanguageBreakdown groups an extension tally by language, most files first
+// (ties in name order).
+func LanguageBreakdown(langs map[string]int) []LanguageCount {
+	byName := make(map[string]int)
+	for ext, n := range langs {
+		byName[LanguageName(ext)] += n
+	}
+	counts := make([]LanguageCount, 0, len(byName))
+	for name, n := range byName {
+		counts = append(counts, LanguageCount{Name: name, Files: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Files != counts[j].Files {
+			return counts[i].Files > counts[j].Files
+		}
+		return counts[i].Name < counts[j].Name
+	})
+	return counts
+}
+
+// PrimaryLanguage returns the language with the most edited files, or "" if
+// the tally is empty.
+func PrimaryLanguage(langs map[string]int) string {
+	if counts := LanguageBreakdown(langs); len(counts) > 0 {
+		return counts[0].Name
+	}
+	return ""
+}
+
+// FormatLanguages renders a breakdown as "Go (12), YAML (3)".
+func FormatLanguages(counts []LanguageCount) string {
+	parts := make([]string, len(counts))
+	for i, c := range counts {
+		parts[i] = fmt.Sprintf("%s (%d)", c.Name, c.Files)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// SetLanguages fills in Languages and PrimaryLanguage from the file edits.
+func (b *ContextBundle) SetLanguages() {
+	b.Languages = TallyLanguages(b.FileEdits)
+	b.PrimaryLanguage = PrimaryLanguage(b.Languages)
+}
@@ -0,0 +1,52 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestLanguageTally verifies that edits are counted by lowercased extension,
+// grouped by language, and that the summary line lists the most-edited
+// language first.
+func TestLanguageTally(t *testing.T) {
+	b := &ContextBundle{
+		FileEdits: []session.FileEdit{
+			{Path: "/w/main.go"},
+			{Path: "/w/cmd/root.go"},
+			{Path: "/w/README.MD"},
+			{Path: "/w/deploy.yaml"},
+			{Path: "/w/ci.yml"},
+			{Path: "/w/Makefile"},
+			{Path: "/w/.github/x.v1.proto"},
+		},
+	}
+	b.SetLanguages()
+
+	want := map[string]int{".go": 2, ".md": 1, ".yaml": 1, ".yml": 1, ".proto": 1}
+	if len(b.Languages) != len(want) {
+		t.Fatalf("Languages = %v, want %v", b.Languages, want)
+	}
+	for ext, n := range want {
+		if b.Languages[ext] != n {
+			t.Errorf("Languages[%q] = %d, want %d", ext, b.Languages[ext], n)
+		}
+	}
+	if b.PrimaryLanguage != "Go" {
+		t.Errorf("PrimaryLanguage = %q, want Go", b.PrimaryLanguage)
+	}
+
+	got := FormatLanguages(LanguageBreakdown(b.Languages))
+	if wantLine := "Go (2), YAML (2), Markdown (1), Protocol Buffers (1)"; got != wantLine {
+		t.Errorf("FormatLanguages = %q, want %q", got, wantLine)
+	}
+
+	out, err := (&MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(string(out), "- Languages: Go (2), YAML (2)") {
+		t.Errorf("Markdown summary is missing the languages line:\n%s", out)
+	}
+}
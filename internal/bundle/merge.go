@@ -0,0 +1,139 @@
+package bundle
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// Merge combines bundles from the same piece of work, e.g. one per machine
+// when pair-programming, into a single bundle:
+//
+//   - file edits are unioned by path, keeping the latest edit of each file;
+//   - annotations and commands are concatenated and sorted by time;
+//   - the session spans the earliest start to the latest stop;
+//   - the git section comes from the first bundle that has one.
+//
+// It returns the merged bundle and any conflicts found, which are also
+// recorded in the merged bundle's warnings.
+func Merge(bundles []*ContextBundle) (*ContextBundle, []string) {
+	merged := &ContextBundle{}
+	if len(bundles) == 0 {
+		return merged, nil
+	}
+
+	var conflicts []string
+	var ids []string
+	latestEdit := make(map[string]session.FileEdit)
+
+	for i, b := range bundles {
+		ids = append(ids, b.Session.ID)
+		if i == 0 {
+			merged.Session = b.Session
+		} else {
+			if b.Session.StartTime.Before(merged.Session.StartTime) {
+				merged.Session.StartTime = b.Session.StartTime
+			}
+			if b.Session.StopTime.After(merged.Session.StopTime) {
+				merged.Session.StopTime = b.Session.StopTime
+			}
+		}
+
+		for _, fe := range b.FileEdits {
+			if cur, ok := latestEdit[fe.Path]; !ok || fe.Timestamp.After(cur.Timestamp) {
+				latestEdit[fe.Path] = fe
+			}
+		}
+		merged.Annotations = append(merged.Annotations, b.Annotations...)
+		merged.Commands = append(merged.Commands, b.Commands...)
+		merged.Tags = appendUnique(merged.Tags, b.Tags...)
+		merged.EditorTabs = appendUnique(merged.EditorTabs, b.EditorTabs...)
+		merged.Processes = appendUnique(merged.Processes, b.Processes...)
+		merged.Warnings = append(merged.Warnings, b.Warnings...)
+		for _, tab := range b.BrowserTabs {
+			if !slices.Contains(merged.BrowserTabs, tab) {
+				merged.BrowserTabs = append(merged.BrowserTabs, tab)
+			}
+		}
+		merged.Env = mergeMaps(merged.Env, b.Env)
+		merged.Toolchains = mergeMaps(merged.Toolchains, b.Toolchains)
+		if merged.Tmux == nil {
+			merged.Tmux = b.Tmux
+		}
+
+		if b.Git == nil {
+			continue
+		}
+		if merged.Git == nil {
+			merged.Git = b.Git
+		} else if b.Git.Branch != merged.Git.Branch || b.Git.HeadCommit != merged.Git.HeadCommit {
+			conflicts = append(conflicts, fmt.Sprintf(
+				"git state differs between bundles: keeping %s@%s, ignoring %s@%s from session %s",
+				merged.Git.Branch, shortHash(merged.Git.HeadCommit),
+				b.Git.Branch, shortHash(b.Git.HeadCommit), b.Session.ID))
+		}
+	}
+
+	merged.Session.ID = strings.Join(ids, "+")
+	merged.Session.Duration = merged.Session.StopTime.Sub(merged.Session.StartTime).Round(time.Second).String()
+
+	for _, fe := range latestEdit {
+		merged.FileEdits = append(merged.FileEdits, fe)
+	}
+	sort.Slice(merged.FileEdits, func(i, j int) bool {
+		a, b := merged.FileEdits[i], merged.FileEdits[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.Path < b.Path
+	})
+	sort.SliceStable(merged.Annotations, func(i, j int) bool {
+		return merged.Annotations[i].Timestamp.Before(merged.Annotations[j].Timestamp)
+	})
+	sort.SliceStable(merged.Commands, func(i, j int) bool {
+		return merged.Commands[i].Timestamp.Before(merged.Commands[j].Timestamp)
+	})
+
+	merged.SetLanguages()
+	merged.SetDiffStat()
+	merged.Warnings = append(merged.Warnings, conflicts...)
+	return merged, conflicts
+}
+
+// appendUnique appends the items of add not already in list.
+func appendUnique(list []string, add ...string) []string {
+	for _, s := range add {
+		if !slices.Contains(list, s) {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+// mergeMaps copies src into dst, keeping dst's value for keys in both.
+func mergeMaps(dst, src map[string]string) map[string]string {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[string]string, len(src))
+	}
+	for k, v := range src {
+		if _, ok := dst[k]; !ok {
+			dst[k] = v
+		}
+	}
+	return dst
+}
+
+// shortHash abbreviates a commit hash for messages.
+func shortHash(h string) string {
+	if len(h) > 7 {
+		return h[:7]
+	}
+	return h
+}
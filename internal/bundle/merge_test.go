@@ -0,0 +1,67 @@
+package bundle
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestMerge verifies that merging unions file edits by path, interleaves
+// annotations and commands by time, spans the combined session window, and
+// reports differing git state.
+func TestMerge(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	at := func(m int) time.Time { return t0.Add(time.Duration(m) * time.Minute) }
+
+	a := &ContextBundle{
+		Session:     SessionMeta{ID: "a", StartTime: at(10), StopTime: at(60), WorkDir: "/w"},
+		Annotations: []session.Annotation{{Timestamp: at(20), Message: "a1"}},
+		FileEdits: []session.FileEdit{
+			{Path: "/w/main.go", Timestamp: at(30), Diff: "old"},
+			{Path: "/w/a.go", Timestamp: at(15)},
+		},
+		Commands:   []Command{{Raw: "go test", Timestamp: at(25)}},
+		EditorTabs: []string{"/w/main.go"},
+		Git:        &GitInfo{Branch: "main", HeadCommit: "1111111aaaa"},
+	}
+	b := &ContextBundle{
+		Session:     SessionMeta{ID: "b", StartTime: at(0), StopTime: at(45), WorkDir: "/w"},
+		Annotations: []session.Annotation{{Timestamp: at(5), Message: "b1"}},
+		FileEdits:   []session.FileEdit{{Path: "/w/main.go", Timestamp: at(40), Diff: "new"}},
+		Commands:    []Command{{Raw: "make", Timestamp: at(22)}},
+		EditorTabs:  []string{"/w/main.go", "/w/b.go"},
+		Git:         &GitInfo{Branch: "feature", HeadCommit: "2222222bbbb"},
+	}
+
+	m, conflicts := Merge([]*ContextBundle{a, b})
+
+	if !m.Session.StartTime.Equal(at(0)) || !m.Session.StopTime.Equal(at(60)) {
+		t.Errorf("session window = %v..%v, want %v..%v", m.Session.StartTime, m.Session.StopTime, at(0), at(60))
+	}
+	if m.Session.Duration != "1h0m0s" {
+		t.Errorf("duration = %q, want %q", m.Session.Duration, "1h0m0s")
+	}
+	if len(m.FileEdits) != 2 || m.FileEdits[1].Path != "/w/main.go" || m.FileEdits[1].Diff != "new" {
+		t.Errorf("file edits = %+v, want a.go then the newer main.go", m.FileEdits)
+	}
+	if len(m.Annotations) != 2 || m.Annotations[0].Message != "b1" {
+		t.Errorf("annotations not sorted by time: %+v", m.Annotations)
+	}
+	if len(m.Commands) != 2 || m.Commands[0].Raw != "make" {
+		t.Errorf("commands not sorted by time: %+v", m.Commands)
+	}
+	if len(m.EditorTabs) != 2 {
+		t.Errorf("editor tabs = %v, want 2 unique tabs", m.EditorTabs)
+	}
+	if m.Git != a.Git {
+		t.Errorf("expected git section from the first bundle, got %+v", m.Git)
+	}
+	if len(conflicts) != 1 || !strings.Contains(conflicts[0], "git state differs") {
+		t.Errorf("expected one git conflict, got %v", conflicts)
+	}
+	if len(m.Warnings) != 1 {
+		t.Errorf("expected the conflict in the bundle warnings, got %v", m.Warnings)
+	}
+}
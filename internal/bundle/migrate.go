@@ -0,0 +1,42 @@
+package bundle
+
+import "fmt"
+
+// SchemaVersion is the bundle format version written by this build.
+//
+// Version history:
+//   - 1: original format; JSON bundles carry no schema_version field.
+//   - 2: schema_version recorded in the JSON and the Markdown sentinel.
+const SchemaVersion = 2
+
+// migrations upgrade a bundle from the keyed version to the next one.
+var migrations = map[int]func(*ContextBundle){
+	1: migrateV1ToV2,
+}
+
+// migrate upgrades b from version to SchemaVersion. A version of 0 means the
+// bundle predates versioning and is treated as version 1.
+func migrate(b *ContextBundle, version int) error {
+	if version == 0 {
+		version = 1
+	}
+	if version > SchemaVersion {
+		return newerSchemaError(version)
+	}
+	for v := version; v < SchemaVersion; v++ {
+		migrations[v](b)
+	}
+	b.SchemaVersion = SchemaVersion
+	return nil
+}
+
+// migrateV1ToV2 upgrades a version 1 bundle. The v2 layout only adds the
+// schema_version field, so the payload itself needs no changes.
+func migrateV1ToV2(b *ContextBundle) {
+	b.SchemaVersion = 2
+}
+
+// newerSchemaError reports a bundle written by a newer handoff.
+func newerSchemaError(version int) error {
+	return fmt.Errorf("bundle schema version %d is newer than this handoff supports (%d); upgrade handoff to read it", version, SchemaVersion)
+}
@@ -1,9 +1,14 @@
 package bundle
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
+	"regexp"
+	"strconv"
 	"strings"
 )
 
@@ -20,46 +25,115 @@ func (p *JSONParser) Parse(data []byte) (*ContextBundle, error) {
 	if err := json.Unmarshal(data, &bundle); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON bundle: %w", err)
 	}
+	if err := migrate(&bundle, bundle.SchemaVersion); err != nil {
+		return nil, err
+	}
 	return &bundle, nil
 }
 
+// encodingSentinel matches the Markdown payload encoding comment.
+var encodingSentinel = regexp.MustCompile(`<!-- handoff-encoding: (\S+) -->`)
+
+// versionSentinel matches the Markdown version comment and captures the
+// schema version.
+var versionSentinel = regexp.MustCompile(`<!-- handoff-bundle-version: (\d+) -->`)
+
 // MarkdownParser parses a Markdown-rendered ContextBundle by extracting the
 // embedded base64 JSON payload from the sentinel comments.
 type MarkdownParser struct{}
 
 func (p *MarkdownParser) Parse(data []byte) (*ContextBundle, error) {
-	content := string(data)
+	jsonBytes, version, err := embeddedJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	// Unmarshal the JSON into a ContextBundle.
+	var bundle ContextBundle
+	if err := json.Unmarshal(jsonBytes, &bundle); err != nil {
+		return nil, fmt.Errorf("not a valid handoff bundle: failed to parse embedded JSON: %w", err)
+	}
+	if err := migrate(&bundle, version); err != nil {
+		return nil, err
+	}
+
+	return &bundle, nil
+}
+
+// stripFrontmatter removes a leading "---"-delimited YAML block, as written
+// by MarkdownRenderer.Frontmatter, so nothing in it is mistaken for the
+// sentinel comments.
+func stripFrontmatter(content string) string {
+	if !strings.HasPrefix(content, "---\n") {
+		return content
+	}
+	end := strings.Index(content[4:], "\n---\n")
+	if end == -1 {
+		return content
+	}
+	return content[4+end+5:]
+}
+
+// embeddedJSON extracts and decodes the JSON payload of a Markdown bundle,
+// returning it along with the schema version from the sentinel.
+func embeddedJSON(data []byte) ([]byte, int, error) {
+	content := stripFrontmatter(string(data))
 
 	// Require the version sentinel.
-	if !strings.Contains(content, "<!-- handoff-bundle-version: 1 -->") {
-		return nil, fmt.Errorf("not a valid handoff bundle: missing version sentinel")
+	loc := versionSentinel.FindStringSubmatchIndex(content)
+	if loc == nil {
+		return nil, 0, fmt.Errorf("not a valid handoff bundle: missing version sentinel")
+	}
+	m := content[loc[2]:loc[3]]
+	version, err := strconv.Atoi(m)
+	if err != nil || version < 1 {
+		return nil, 0, fmt.Errorf("not a valid handoff bundle: bad version sentinel %q", m)
+	}
+
+	// The payload comments follow the version sentinel up to the first blank
+	// line. Only they are looked at: the body may quote them, e.g. in a diff
+	// of a change to this renderer.
+	header := content[loc[0]:]
+	if end := strings.Index(header, "\n\n"); end != -1 {
+		header = header[:end]
 	}
 
 	// Extract the base64 payload from <!-- handoff-data: <base64> -->.
 	const prefix = "<!-- handoff-data: "
 	const suffix = " -->"
-	start := strings.Index(content, prefix)
+	start := strings.Index(header, prefix)
 	if start == -1 {
-		return nil, fmt.Errorf("not a valid handoff bundle: missing data payload")
+		return nil, 0, fmt.Errorf("not a valid handoff bundle: missing data payload")
 	}
 	start += len(prefix)
-	end := strings.Index(content[start:], suffix)
+	end := strings.Index(header[start:], suffix)
 	if end == -1 {
-		return nil, fmt.Errorf("not a valid handoff bundle: malformed data payload")
+		return nil, 0, fmt.Errorf("not a valid handoff bundle: malformed data payload")
+	}
+	encoded := header[start : start+end]
+	if encoded == "none" { // the NoEmbed form, noEmbedMarker
+		return nil, 0, fmt.Errorf("bundle was exported without embedded data (--no-embed) and cannot be parsed")
 	}
-	encoded := content[start : start+end]
 
 	// Base64-decode the payload.
-	jsonBytes, err := base64.StdEncoding.DecodeString(encoded)
+	payload, err := base64.StdEncoding.DecodeString(encoded)
 	if err != nil {
-		return nil, fmt.Errorf("not a valid handoff bundle: corrupted base64 payload: %w", err)
+		return nil, 0, fmt.Errorf("not a valid handoff bundle: corrupted base64 payload: %w", err)
 	}
 
-	// Unmarshal the JSON into a ContextBundle.
-	var bundle ContextBundle
-	if err := json.Unmarshal(jsonBytes, &bundle); err != nil {
-		return nil, fmt.Errorf("not a valid handoff bundle: failed to parse embedded JSON: %w", err)
+	// Without an encoding comment the payload is plain JSON (older releases).
+	if m := encodingSentinel.FindStringSubmatch(header); m != nil {
+		if m[1] != encodingGzipBase64 {
+			return nil, 0, fmt.Errorf("not a valid handoff bundle: unsupported payload encoding %q", m[1])
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, 0, fmt.Errorf("not a valid handoff bundle: corrupted gzip payload: %w", err)
+		}
+		payload, err = io.ReadAll(zr)
+		if err != nil {
+			return nil, 0, fmt.Errorf("not a valid handoff bundle: corrupted gzip payload: %w", err)
+		}
 	}
-
-	return &bundle, nil
+	return payload, version, nil
 }
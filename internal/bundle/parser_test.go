@@ -2,6 +2,7 @@ package bundle
 
 import (
 	"encoding/base64"
+	"fmt"
 	"strings"
 	"testing"
 )
@@ -82,6 +83,74 @@ func TestMarkdownParser_ValidBase64ButInvalidJSON(t *testing.T) {
 	}
 }
 
+// TestMarkdownParser_PlainPayloadWithoutEncoding verifies that payloads from
+// older releases, which have no encoding comment, are read as plain base64
+// JSON.
+func TestMarkdownParser_PlainPayloadWithoutEncoding(t *testing.T) {
+	payload := base64.StdEncoding.EncodeToString([]byte(`{"session": {"id": "legacy"}}`))
+	content := "<!-- handoff-bundle-version: 2 -->\n<!-- handoff-data: " + payload + " -->\n\n# Handoff\n"
+
+	b, err := (&MarkdownParser{}).Parse([]byte(content))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if b.Session.ID != "legacy" {
+		t.Errorf("session ID = %q, want %q", b.Session.ID, "legacy")
+	}
+}
+
+// TestMarkdownRenderer_CompressesPayload verifies that the payload is
+// announced as gzip+base64 and that an unknown encoding is rejected.
+// TestMarkdownParser_SentinelsInBody verifies that payload comments quoted
+// in the body, here in a diff, are not taken for the bundle's own.
+func TestMarkdownParser_SentinelsInBody(t *testing.T) {
+	diff := "+\tsb.WriteString(\"<!-- handoff-data: none -->\")\n" +
+		"+<!-- handoff-encoding: bogus -->\n"
+	b := &ContextBundle{
+		Session: SessionMeta{ID: "quoted"},
+		Git:     &GitInfo{Branch: "main", Diff: diff},
+	}
+	data, err := (&MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&MarkdownParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if got.Git == nil || got.Git.Diff != diff {
+		t.Errorf("diff not round-tripped: %+v", got.Git)
+	}
+
+	data, err = (&MarkdownRenderer{NoEmbed: true}).Render(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (&MarkdownParser{}).Parse(data); err == nil || !strings.Contains(err.Error(), "--no-embed") {
+		t.Errorf("expected the --no-embed error, got %v", err)
+	}
+}
+
+func TestMarkdownRenderer_CompressesPayload(t *testing.T) {
+	b := &ContextBundle{Git: &GitInfo{Diff: strings.Repeat("+ repeated diff line\n", 1000)}}
+	md, err := (&MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(string(md), "<!-- handoff-encoding: gzip+base64 -->") {
+		t.Fatalf("expected an encoding comment, got:\n%.300s", md)
+	}
+	if len(md) > 2*len(b.Git.Diff) {
+		t.Errorf("rendered %d bytes for a %d-byte diff; payload doesn't look compressed", len(md), len(b.Git.Diff))
+	}
+
+	unknown := strings.Replace(string(md), "gzip+base64", "zstd+base64", 1)
+	_, err = (&MarkdownParser{}).Parse([]byte(unknown))
+	if err == nil || !strings.Contains(err.Error(), "unsupported payload encoding") {
+		t.Errorf("expected unsupported encoding error, got %v", err)
+	}
+}
+
 func TestJSONParser_MalformedJSON(t *testing.T) {
 	p := &JSONParser{}
 
@@ -107,3 +176,43 @@ func TestJSONParser_MalformedJSON(t *testing.T) {
 		})
 	}
 }
+
+// TestJSONParser_MigratesV1Bundle verifies that a pre-versioning JSON bundle
+// (no schema_version field) still parses and is upgraded to SchemaVersion.
+func TestJSONParser_MigratesV1Bundle(t *testing.T) {
+	v1 := `{
+  "session": {"id": "old", "start_time": "2026-02-19T15:00:00Z", "stop_time": "2026-02-19T17:30:00Z", "work_dir": "/repo", "duration": "2h30m0s"},
+  "annotations": [{"timestamp": "2026-02-19T16:00:00Z", "message": "still works", "is_summary": false}],
+  "file_edits": [],
+  "commands": [],
+  "editor_tabs": []
+}`
+	b, err := (&JSONParser{}).Parse([]byte(v1))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if b.SchemaVersion != SchemaVersion {
+		t.Errorf("SchemaVersion = %d, want %d", b.SchemaVersion, SchemaVersion)
+	}
+	if b.Session.ID != "old" || len(b.Annotations) != 1 || b.Annotations[0].Message != "still works" {
+		t.Errorf("unexpected bundle contents: %+v", b)
+	}
+}
+
+// TestParsers_RejectNewerSchemaVersion verifies that bundles written by a
+// newer handoff are rejected with a clear message.
+func TestParsers_RejectNewerSchemaVersion(t *testing.T) {
+	newer := SchemaVersion + 1
+
+	_, err := (&JSONParser{}).Parse([]byte(fmt.Sprintf(`{"schema_version": %d}`, newer)))
+	if err == nil || !strings.Contains(err.Error(), "newer than this handoff supports") {
+		t.Errorf("JSONParser: expected newer-version error, got %v", err)
+	}
+
+	payload := base64.StdEncoding.EncodeToString([]byte("{}"))
+	md := fmt.Sprintf("<!-- handoff-bundle-version: %d -->\n<!-- handoff-data: %s -->\n", newer, payload)
+	_, err = (&MarkdownParser{}).Parse([]byte(md))
+	if err == nil || !strings.Contains(err.Error(), "newer than this handoff supports") {
+		t.Errorf("MarkdownParser: expected newer-version error, got %v", err)
+	}
+}
@@ -1,10 +1,17 @@
 package bundle
 
 import (
+	"bytes"
+	"compress/gzip"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
 )
 
 // BundleRenderer serializes a ContextBundle to bytes.
@@ -16,45 +23,146 @@ type BundleRenderer interface {
 type JSONRenderer struct{}
 
 func (r *JSONRenderer) Render(bundle *ContextBundle) ([]byte, error) {
-	return json.MarshalIndent(bundle, "", "  ")
+	return json.MarshalIndent(stampVersion(bundle), "", "  ")
 }
 
-// MarkdownRenderer renders a ContextBundle as human-readable Markdown with
-// an embedded base64 JSON payload for lossless round-trip parsing.
-type MarkdownRenderer struct{}
+// stampVersion returns a shallow copy of bundle tagged with the current
+// SchemaVersion, leaving the caller's value untouched.
+func stampVersion(bundle *ContextBundle) *ContextBundle {
+	out := *bundle
+	out.SchemaVersion = SchemaVersion
+	return &out
+}
 
-func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
-	// Marshal bundle to JSON and base64-encode it for the embedded payload.
-	jsonBytes, err := json.Marshal(bundle)
-	if err != nil {
-		return nil, fmt.Errorf("marshal bundle: %w", err)
+// noEmbedMarker replaces the data payload in bundles rendered with NoEmbed,
+// so the parser can tell them apart from damaged files.
+const noEmbedMarker = "<!-- handoff-data: none -->"
+
+// encodingGzipBase64 is announced by the handoff-encoding comment when the
+// data payload is gzip-compressed JSON, base64-encoded. Without the comment
+// the payload is plain base64 JSON, as written by older releases.
+const encodingGzipBase64 = "gzip+base64"
+
+// writeFrontmatter writes the YAML frontmatter block for b. Strings are
+// double-quoted; Go's escapes are a subset of YAML's.
+func writeFrontmatter(sb *strings.Builder, b *ContextBundle) {
+	sb.WriteString("---\n")
+	if b.Session.ProjectName != "" {
+		fmt.Fprintf(sb, "project: %s\n", strconv.Quote(b.Session.ProjectName))
 	}
-	encoded := base64.StdEncoding.EncodeToString(jsonBytes)
+	fmt.Fprintf(sb, "workdir: %s\n", strconv.Quote(b.Session.WorkDir))
+	if b.Git != nil {
+		fmt.Fprintf(sb, "branch: %s\n", strconv.Quote(b.Git.Branch))
+	}
+	fmt.Fprintf(sb, "duration: %s\n", strconv.Quote(b.Session.Duration))
+	fmt.Fprintf(sb, "stopped_at: %s\n", b.Session.StopTime.Format(time.RFC3339))
+	tags := make([]string, len(b.Tags))
+	for i, tag := range b.Tags {
+		tags[i] = strconv.Quote(tag)
+	}
+	fmt.Fprintf(sb, "tags: [%s]\n", strings.Join(tags, ", "))
+	sb.WriteString("---\n\n")
+}
+
+// linkTextEscaper escapes characters that would end Markdown link text early.
+var linkTextEscaper = strings.NewReplacer("[", `\[`, "]", `\]`)
+
+// tableCellEscaper escapes pipes, which end a table cell even inside a code
+// span, and newlines, which end the row.
+var tableCellEscaper = strings.NewReplacer("|", `\|`, "\n", " ")
 
+// MarkdownRenderer renders a ContextBundle as human-readable Markdown with
+// an embedded, compressed JSON payload for lossless round-trip parsing.
+type MarkdownRenderer struct {
+	// NoEmbed omits the base64 payload. The output is plain Markdown that
+	// renders cleanly in wikis but cannot be parsed back into a bundle.
+	NoEmbed bool
+	// Frontmatter prepends a YAML block with the work dir, branch,
+	// duration, stop time and tags, for docs tooling that indexes on it.
+	Frontmatter bool
+}
+
+func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
+	bundle = stampVersion(bundle)
 	var sb strings.Builder
 
+	if r.Frontmatter {
+		writeFrontmatter(&sb, bundle)
+	}
+
 	// Sentinel and embedded payload.
-	sb.WriteString("<!-- handoff-bundle-version: 1 -->\n")
-	fmt.Fprintf(&sb, "<!-- handoff-data: %s -->\n\n", encoded)
+	fmt.Fprintf(&sb, "<!-- handoff-bundle-version: %d -->\n", SchemaVersion)
+	if r.NoEmbed {
+		sb.WriteString(noEmbedMarker + "\n\n")
+	} else {
+		// Marshal bundle to JSON and base64-encode it for the embedded payload.
+		jsonBytes, err := json.Marshal(bundle)
+		if err != nil {
+			return nil, fmt.Errorf("marshal bundle: %w", err)
+		}
+		var gz bytes.Buffer
+		zw := gzip.NewWriter(&gz)
+		if _, err := zw.Write(jsonBytes); err != nil {
+			return nil, fmt.Errorf("compress bundle: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("compress bundle: %w", err)
+		}
+		encoded := base64.StdEncoding.EncodeToString(gz.Bytes())
+		fmt.Fprintf(&sb, "<!-- handoff-encoding: %s -->\n", encodingGzipBase64)
+		fmt.Fprintf(&sb, "<!-- handoff-data: %s -->\n\n", encoded)
+	}
 
-	// Title.
+	// Title, led by the project name when the bundle has one.
+	where := bundle.Session.WorkDir
+	if name := bundle.Session.ProjectName; name != "" {
+		where = fmt.Sprintf("%s (%s)", name, where)
+	}
 	fmt.Fprintf(&sb, "# Handoff — %s — %s\n\n",
-		bundle.Session.WorkDir,
+		where,
 		bundle.Session.StopTime.Format("2006-01-02 15:04:05 MST"),
 	)
 
 	// ## Summary
 	sb.WriteString("## Summary\n\n")
 	fmt.Fprintf(&sb, "- Duration: %s\n", bundle.Session.Duration)
+	if line := bundle.Session.DiffStatLine(); line != "" {
+		fmt.Fprintf(&sb, "- Changes: %s\n", line)
+	}
 	if bundle.Session.Author != "" {
 		fmt.Fprintf(&sb, "- Author: %s\n", bundle.Session.Author)
 	}
+	if len(bundle.Tags) > 0 {
+		fmt.Fprintf(&sb, "- Tags: %s\n", strings.Join(bundle.Tags, ", "))
+	}
+	if len(bundle.Languages) > 0 {
+		fmt.Fprintf(&sb, "- Languages: %s\n", FormatLanguages(LanguageBreakdown(bundle.Languages)))
+	}
 	if bundle.Git != nil {
 		fmt.Fprintf(&sb, "- Branch: %s\n", bundle.Git.Branch)
 		fmt.Fprintf(&sb, "- Head commit: %s\n", bundle.Git.HeadCommit)
 	}
 	sb.WriteString("\n")
 
+	// Blockers are called out under the summary so they aren't missed.
+	var blockers []string
+	for _, a := range bundle.Annotations {
+		if a.IsBlocker() {
+			line := listItemText(a.Message, ">   ")
+			if loc := a.Location(bundle.Session.WorkDir); loc != "" {
+				line += " — `" + loc + "`"
+			}
+			blockers = append(blockers, line)
+		}
+	}
+	if len(blockers) > 0 {
+		sb.WriteString("> **Blockers**\n>\n")
+		for _, line := range blockers {
+			fmt.Fprintf(&sb, "> - %s\n", line)
+		}
+		sb.WriteString("\n")
+	}
+
 	// ## Annotations
 	sb.WriteString("## Annotations\n\n")
 	if len(bundle.Annotations) == 0 {
@@ -65,11 +173,25 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 			if a.IsSummary {
 				kind = "summary"
 			}
-			fmt.Fprintf(&sb, "- [%s] (%s) %s\n",
+			if a.Level != "" && a.Level != session.LevelInfo {
+				kind += ", " + a.Level
+			}
+			fmt.Fprintf(&sb, "- [%s] (%s) %s",
 				a.Timestamp.Format("2006-01-02 15:04:05"),
 				kind,
-				a.Message,
+				listItemText(a.Message, "  "),
 			)
+			if loc := a.Location(bundle.Session.WorkDir); loc != "" {
+				fmt.Fprintf(&sb, " — `%s`", loc)
+			}
+			sb.WriteString("\n")
+			if a.LogExcerpt != "" {
+				sb.WriteString("\n  ```text\n")
+				for _, line := range strings.Split(strings.TrimRight(a.LogExcerpt, "\n"), "\n") {
+					sb.WriteString("  " + line + "\n")
+				}
+				sb.WriteString("  ```\n\n")
+			}
 		}
 	}
 	sb.WriteString("\n")
@@ -96,7 +218,9 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 		sb.WriteString("_Not a git repository or git data unavailable._\n")
 	} else {
 		sb.WriteString("### Unstaged\n\n")
-		if bundle.Git.Diff == "" {
+		if bundle.DiffsOmitted {
+			sb.WriteString("_Diff omitted._\n")
+		} else if bundle.Git.Diff == "" {
 			sb.WriteString("_No unstaged changes._\n")
 		} else {
 			sb.WriteString("```diff\n")
@@ -109,7 +233,9 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 		sb.WriteString("\n")
 
 		sb.WriteString("### Staged\n\n")
-		if bundle.Git.StagedDiff == "" {
+		if bundle.DiffsOmitted {
+			sb.WriteString("_Diff omitted._\n")
+		} else if bundle.Git.StagedDiff == "" {
 			sb.WriteString("_No staged changes._\n")
 		} else {
 			sb.WriteString("```diff\n")
@@ -121,6 +247,30 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 		}
 		sb.WriteString("\n")
 
+		if len(bundle.Git.UntrackedFiles) > 0 {
+			sb.WriteString("### Untracked\n\n")
+			for _, f := range bundle.Git.UntrackedFiles {
+				fmt.Fprintf(&sb, "- `%s`\n", f)
+			}
+			if bundle.Git.UntrackedDiff != "" {
+				sb.WriteString("\n```diff\n")
+				sb.WriteString(bundle.Git.UntrackedDiff)
+				if !strings.HasSuffix(bundle.Git.UntrackedDiff, "\n") {
+					sb.WriteString("\n")
+				}
+				sb.WriteString("```\n")
+			}
+			sb.WriteString("\n")
+		}
+
+		if len(bundle.Git.Stashes) > 0 {
+			sb.WriteString("### Stashes\n\n")
+			for _, s := range bundle.Git.Stashes {
+				fmt.Fprintf(&sb, "- `%s`\n", s)
+			}
+			sb.WriteString("\n")
+		}
+
 		sb.WriteString("### Recent Commits\n\n")
 		if len(bundle.Git.RecentLog) == 0 {
 			sb.WriteString("_No recent commits._\n")
@@ -128,6 +278,9 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 			for _, line := range bundle.Git.RecentLog {
 				fmt.Fprintf(&sb, "- %s\n", line)
 			}
+			if n := bundle.Git.RecentLogOmitted; n > 0 {
+				fmt.Fprintf(&sb, "- _... and %d more_\n", n)
+			}
 		}
 	}
 	sb.WriteString("\n")
@@ -137,8 +290,21 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 	if len(bundle.Commands) == 0 {
 		sb.WriteString("_No terminal commands recorded._\n")
 	} else {
+		sb.WriteString("| # | Time | Command |\n")
+		sb.WriteString("|---|------|---------|\n")
 		for i, cmd := range bundle.Commands {
-			fmt.Fprintf(&sb, "%d. `%s`\n", i+1, cmd.Raw)
+			when := ""
+			if !cmd.Timestamp.IsZero() {
+				when = cmd.Timestamp.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(&sb, "| %d | %s | `%s`", i+1, when, tableCellEscaper.Replace(cmd.Raw))
+			if cmd.Count > 1 {
+				fmt.Fprintf(&sb, " ×%d", cmd.Count)
+			}
+			if cmd.Failed() {
+				fmt.Fprintf(&sb, " — exit %d", *cmd.ExitCode)
+			}
+			sb.WriteString(" |\n")
 		}
 	}
 	sb.WriteString("\n")
@@ -154,5 +320,92 @@ func (r *MarkdownRenderer) Render(bundle *ContextBundle) ([]byte, error) {
 	}
 	sb.WriteString("\n")
 
+	// ## Browser Tabs (only when any were found)
+	if len(bundle.BrowserTabs) > 0 {
+		sb.WriteString("## Browser Tabs\n\n")
+		for _, t := range bundle.BrowserTabs {
+			title := t.Title
+			if title == "" {
+				title = t.URL
+			}
+			fmt.Fprintf(&sb, "- [%s](%s)\n", linkTextEscaper.Replace(title), t.URL)
+		}
+		sb.WriteString("\n")
+	}
+
+	// ## Environment (only when variables were captured)
+	if len(bundle.Env) > 0 {
+		sb.WriteString("## Environment\n\n")
+		for _, k := range sortedKeys(bundle.Env) {
+			fmt.Fprintf(&sb, "- `%s=%s`\n", k, bundle.Env[k])
+		}
+		sb.WriteString("\n")
+	}
+
+	// ## Toolchain (only when probes produced output)
+	if len(bundle.Toolchains) > 0 {
+		sb.WriteString("## Toolchain\n\n")
+		for _, probe := range sortedKeys(bundle.Toolchains) {
+			fmt.Fprintf(&sb, "- `%s`: %s\n", probe, bundle.Toolchains[probe])
+		}
+		sb.WriteString("\n")
+	}
+
+	// ## Tmux Layout (only when run inside tmux)
+	if bundle.Tmux != nil && len(bundle.Tmux.Windows) > 0 {
+		sb.WriteString("## Tmux Layout\n\n")
+		fmt.Fprintf(&sb, "Session `%s` — recreate it with `handoff restore-tmux`.\n\n", bundle.Tmux.Session)
+		for _, w := range bundle.Tmux.Windows {
+			fmt.Fprintf(&sb, "- Window %d: **%s** (layout `%s`)\n", w.Index, w.Name, w.Layout)
+			for _, p := range w.Panes {
+				fmt.Fprintf(&sb, "  - Pane %d: `%s` in `%s`\n", p.Index, p.Command, p.Dir)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	// ## Running Processes (only when any were found)
+	if len(bundle.Processes) > 0 {
+		sb.WriteString("## Running Processes\n\n")
+		for _, p := range bundle.Processes {
+			fmt.Fprintf(&sb, "- `%s`\n", p)
+		}
+		sb.WriteString("\n")
+	}
+
+	// ## Warnings (only when collectors reported any)
+	if len(bundle.Warnings) > 0 {
+		sb.WriteString("## Warnings\n\n")
+		for _, w := range bundle.Warnings {
+			fmt.Fprintf(&sb, "- %s\n", w)
+		}
+		sb.WriteString("\n")
+	}
+
 	return []byte(sb.String()), nil
 }
+
+// sortedKeys returns the keys of m in sorted order for stable rendering.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// listItemText indents the lines of a multi-line message after the first by
+// prefix, so they stay inside the message's list item instead of, say, a
+// templated summary's "## Next steps" becoming a heading of the bundle.
+func listItemText(msg, prefix string) string {
+	lines := strings.Split(strings.TrimRight(msg, "\n"), "\n")
+	for i := 1; i < len(lines); i++ {
+		if lines[i] == "" {
+			lines[i] = strings.TrimRight(prefix, " ")
+		} else {
+			lines[i] = prefix + lines[i]
+		}
+	}
+	return strings.Join(lines, "\n")
+}
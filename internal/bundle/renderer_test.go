@@ -217,11 +217,11 @@ func TestJSONBundleRoundTrip(t *testing.T) {
 
 // Feature: handoff, Property 9: Markdown bundle round-trip
 func TestMarkdownBundleRoundTrip(t *testing.T) {
-	renderer := &bundle.MarkdownRenderer{}
 	parser := &bundle.MarkdownParser{}
 
 	rapid.Check(t, func(t *rapid.T) {
 		original := generateBundle(t)
+		renderer := &bundle.MarkdownRenderer{Frontmatter: rapid.Bool().Draw(t, "frontmatter")}
 
 		data, err := renderer.Render(original)
 		if err != nil {
@@ -288,3 +288,300 @@ func TestMarkdownBundleRoundTrip(t *testing.T) {
 		}
 	})
 }
+
+// TestWarningsRenderedAndRoundTripped verifies that collector warnings are
+// rendered as a "## Warnings" section and survive a Markdown round-trip.
+func TestWarningsRenderedAndRoundTripped(t *testing.T) {
+	b := &bundle.ContextBundle{
+		Session:  bundle.SessionMeta{ID: "warn", WorkDir: "/repo"},
+		Warnings: []string{"shell history has no timestamps", "not a git repository"},
+	}
+
+	data, err := (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	md := string(data)
+	if !strings.Contains(md, "## Warnings") {
+		t.Errorf("expected Markdown to contain a Warnings section, got:\n%s", md)
+	}
+	for _, w := range b.Warnings {
+		if !strings.Contains(md, "- "+w) {
+			t.Errorf("expected Markdown to list warning %q", w)
+		}
+	}
+
+	got, err := (&bundle.MarkdownParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("MarkdownParser.Parse: %v", err)
+	}
+	if len(got.Warnings) != len(b.Warnings) {
+		t.Fatalf("Warnings length mismatch: got %d, want %d", len(got.Warnings), len(b.Warnings))
+	}
+	for i := range b.Warnings {
+		if got.Warnings[i] != b.Warnings[i] {
+			t.Errorf("Warnings[%d] mismatch: got %q, want %q", i, got.Warnings[i], b.Warnings[i])
+		}
+	}
+
+	// Bundles without warnings omit the section entirely.
+	b.Warnings = nil
+	data, err = (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	if strings.Contains(string(data), "## Warnings") {
+		t.Error("expected no Warnings section when there are no warnings")
+	}
+}
+
+// TestMarkdownNoEmbed verifies that NoEmbed drops the base64 payload and that
+// the parser reports such files clearly instead of as corrupt.
+func TestMarkdownNoEmbed(t *testing.T) {
+	b := &bundle.ContextBundle{
+		Session:     bundle.SessionMeta{ID: "plain", WorkDir: "/repo"},
+		Annotations: []session.Annotation{{Message: "wiki friendly"}},
+	}
+
+	data, err := (&bundle.MarkdownRenderer{NoEmbed: true}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	md := string(data)
+	if strings.Contains(md, "<!-- handoff-data: ") && !strings.Contains(md, "<!-- handoff-data: none -->") {
+		t.Errorf("expected no embedded payload, got:\n%s", md)
+	}
+	if !strings.Contains(md, "wiki friendly") {
+		t.Errorf("expected human-readable content to be kept, got:\n%s", md)
+	}
+
+	_, err = (&bundle.MarkdownParser{}).Parse(data)
+	if err == nil {
+		t.Fatal("expected an error parsing a bundle without embedded data")
+	}
+	if !strings.Contains(err.Error(), "bundle was exported without embedded data") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestMarkdownFrontmatter verifies that the YAML frontmatter comes first and
+// that the parser skips it, even when a value looks like a sentinel comment.
+func TestMarkdownFrontmatter(t *testing.T) {
+	stop := time.Date(2026, 2, 19, 17, 30, 0, 0, time.UTC)
+	b := &bundle.ContextBundle{
+		Session: bundle.SessionMeta{ID: "fm", WorkDir: "/repo", ProjectName: "Invoice Service", Duration: "2h15m0s", StopTime: stop},
+		Git:     &bundle.GitInfo{Branch: "main"},
+		Tags:    []string{"bugfix", "<!-- handoff-data: bogus -->"},
+	}
+
+	data, err := (&bundle.MarkdownRenderer{Frontmatter: true}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	want := "---\n" +
+		"project: \"Invoice Service\"\n" +
+		"workdir: \"/repo\"\n" +
+		"branch: \"main\"\n" +
+		"duration: \"2h15m0s\"\n" +
+		"stopped_at: 2026-02-19T17:30:00Z\n" +
+		"tags: [\"bugfix\", \"<!-- handoff-data: bogus -->\"]\n" +
+		"---\n\n<!-- handoff-bundle-version: "
+	if !strings.HasPrefix(string(data), want) {
+		t.Errorf("expected frontmatter before the sentinel, got:\n%s", data)
+	}
+	if !strings.Contains(string(data), "# Handoff — Invoice Service (/repo) — ") {
+		t.Errorf("expected the project name in the title, got:\n%s", data)
+	}
+
+	got, err := (&bundle.MarkdownParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("MarkdownParser.Parse: %v", err)
+	}
+	if len(got.Tags) != 2 || got.Tags[1] != b.Tags[1] {
+		t.Errorf("tags: got %q, want %q", got.Tags, b.Tags)
+	}
+}
+
+// TestParseHeaderMatchesParse verifies that ParseHeader reports the same
+// metadata and collection sizes as a full Parse, for both formats.
+func TestParseHeaderMatchesParse(t *testing.T) {
+	formats := []struct {
+		name     string
+		renderer bundle.BundleRenderer
+		parser   interface {
+			bundle.BundleParser
+			bundle.HeaderParser
+		}
+	}{
+		{"json", &bundle.JSONRenderer{}, &bundle.JSONParser{}},
+		{"markdown", &bundle.MarkdownRenderer{}, &bundle.MarkdownParser{}},
+	}
+
+	rapid.Check(t, func(rt *rapid.T) {
+		b := generateBundle(rt)
+		b.Warnings = rapid.SliceOf(rapid.StringN(1, 20, -1)).Draw(rt, "warnings")
+		if rapid.Bool().Draw(rt, "no_git") {
+			b.Git = nil
+		}
+
+		for _, f := range formats {
+			data, err := f.renderer.Render(b)
+			if err != nil {
+				rt.Fatalf("%s: Render: %v", f.name, err)
+			}
+			full, err := f.parser.Parse(data)
+			if err != nil {
+				rt.Fatalf("%s: Parse: %v", f.name, err)
+			}
+			meta, counts, err := f.parser.ParseHeader(data)
+			if err != nil {
+				rt.Fatalf("%s: ParseHeader: %v", f.name, err)
+			}
+			if *meta != full.Session {
+				rt.Errorf("%s: meta = %+v, want %+v", f.name, *meta, full.Session)
+			}
+			if counts != full.Counts() {
+				rt.Errorf("%s: counts = %+v, want %+v", f.name, counts, full.Counts())
+			}
+		}
+	})
+}
+
+// TestTagsRoundTrip verifies that tags survive both renderer/parser pairs and
+// appear in the Markdown summary.
+func TestTagsRoundTrip(t *testing.T) {
+	b := &bundle.ContextBundle{Tags: []string{"bugfix", "urgent"}}
+
+	md, err := (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("Markdown Render: %v", err)
+	}
+	if !strings.Contains(string(md), "- Tags: bugfix, urgent") {
+		t.Errorf("expected tags in the Markdown summary, got:\n%s", md)
+	}
+	fromMD, err := (&bundle.MarkdownParser{}).Parse(md)
+	if err != nil {
+		t.Fatalf("Markdown Parse: %v", err)
+	}
+
+	js, err := (&bundle.JSONRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("JSON Render: %v", err)
+	}
+	fromJSON, err := (&bundle.JSONParser{}).Parse(js)
+	if err != nil {
+		t.Fatalf("JSON Parse: %v", err)
+	}
+
+	for name, got := range map[string]*bundle.ContextBundle{"markdown": fromMD, "json": fromJSON} {
+		if strings.Join(got.Tags, ",") != "bugfix,urgent" {
+			t.Errorf("%s: tags = %v, want [bugfix urgent]", name, got.Tags)
+		}
+	}
+}
+
+// TestBlockersCallout verifies that blocker annotations are called out under
+// the Markdown summary and labelled in the annotation list.
+func TestBlockersCallout(t *testing.T) {
+	b := &bundle.ContextBundle{
+		Session: bundle.SessionMeta{ID: "blk", WorkDir: "/repo"},
+		Annotations: []session.Annotation{
+			{Message: "just a note"},
+			{Message: "db migration pending", Level: session.LevelBlocker, Path: "/repo/db/schema.sql"},
+		},
+	}
+	data, err := (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	md := string(data)
+	callout := "> **Blockers**\n>\n> - db migration pending — `db/schema.sql`\n"
+	if !strings.Contains(md, callout) {
+		t.Errorf("expected Blockers callout, got:\n%s", md)
+	}
+	if strings.Index(md, callout) > strings.Index(md, "## Annotations") {
+		t.Error("expected the callout before the Annotations section")
+	}
+	if !strings.Contains(md, "(note, blocker) db migration pending") {
+		t.Errorf("expected the blocker to be labelled in the list, got:\n%s", md)
+	}
+
+	b.Annotations = b.Annotations[:1]
+	if data, err = (&bundle.MarkdownRenderer{}).Render(b); err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	if strings.Contains(string(data), "Blockers") {
+		t.Error("expected no callout without blockers")
+	}
+}
+
+// TestMultilineSummary verifies that a templated summary's lines stay in
+// its list item, so its "##" lines don't become sections of the bundle, and
+// that the summary still round-trips as written.
+func TestMultilineSummary(t *testing.T) {
+	summary := "## What I did\nFixed the parser\n\n## Next steps\nShip it"
+	b := &bundle.ContextBundle{
+		Session:     bundle.SessionMeta{ID: "sum", WorkDir: "/repo"},
+		Annotations: []session.Annotation{{Message: summary, IsSummary: true}},
+	}
+	data, err := (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	md := string(data)
+	item := "(summary) ## What I did\n  Fixed the parser\n\n  ## Next steps\n  Ship it\n"
+	if !strings.Contains(md, item) {
+		t.Errorf("expected the summary indented under its list item, got:\n%s", md)
+	}
+	for _, line := range strings.Split(md, "\n") {
+		if line == "## What I did" || line == "## Next steps" {
+			t.Errorf("summary line %q became a heading:\n%s", line, md)
+		}
+	}
+
+	got, err := (&bundle.MarkdownParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("MarkdownParser.Parse: %v", err)
+	}
+	if len(got.Annotations) != 1 || got.Annotations[0].Message != summary {
+		t.Errorf("summary did not round-trip: %+v", got.Annotations)
+	}
+	meta, counts, err := (&bundle.MarkdownParser{}).ParseHeader(data)
+	if err != nil || meta.ID != "sum" || counts.Annotations != 1 {
+		t.Errorf("ParseHeader: got %+v, %+v, %v", meta, counts, err)
+	}
+}
+
+// TestCommandsTable verifies that commands render as a table with their
+// time, a blank time cell when the shell recorded none, and pipes escaped,
+// while the embedded payload keeps the command as it was.
+func TestCommandsTable(t *testing.T) {
+	at := time.Date(2026, 2, 19, 17, 30, 0, 0, time.UTC)
+	b := &bundle.ContextBundle{
+		Session: bundle.SessionMeta{ID: "cmds", WorkDir: "/repo"},
+		Commands: []bundle.Command{
+			{Raw: "go test ./...", Timestamp: at},
+			{Raw: "ps aux | grep handoff"},
+		},
+	}
+	data, err := (&bundle.MarkdownRenderer{}).Render(b)
+	if err != nil {
+		t.Fatalf("MarkdownRenderer.Render: %v", err)
+	}
+	md := string(data)
+	table := "| # | Time | Command |\n" +
+		"|---|------|---------|\n" +
+		"| 1 | 2026-02-19 17:30:00 | `go test ./...` |\n" +
+		"| 2 |  | `ps aux \\| grep handoff` |\n"
+	if !strings.Contains(md, table) {
+		t.Errorf("expected commands table, got:\n%s", md)
+	}
+
+	got, err := (&bundle.MarkdownParser{}).Parse(data)
+	if err != nil {
+		t.Fatalf("MarkdownParser.Parse: %v", err)
+	}
+	if len(got.Commands) != 2 || got.Commands[1].Raw != "ps aux | grep handoff" {
+		t.Errorf("expected the payload to keep the raw commands, got %+v", got.Commands)
+	}
+}
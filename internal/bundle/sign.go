@@ -0,0 +1,137 @@
+package bundle
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// signaturePrefix names the algorithm of a bundle signature.
+const signaturePrefix = "hmac-sha256:"
+
+// ErrUnsigned is returned by VerifyJSON and VerifyMarkdown for a bundle
+// with no signature.
+var ErrUnsigned = errors.New("bundle is not signed")
+
+// ErrBadSignature is returned by VerifyJSON and VerifyMarkdown when the
+// signature doesn't match the bundle's contents.
+var ErrBadSignature = errors.New("signature does not match: the bundle was modified or signed with a different key")
+
+// signatureLine matches the Markdown signature comment on the last line of
+// a file.
+var signatureLine = regexp.MustCompile(`(?:^|\n)<!-- handoff-signature: (\S+) -->\n?$`)
+
+// sign returns the HMAC-SHA256 signature of content.
+func sign(content, key []byte) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write(content)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks sig against content.
+func verify(content []byte, sig string, key []byte) error {
+	if sig == "" {
+		return ErrUnsigned
+	}
+	if !strings.HasPrefix(sig, signaturePrefix) {
+		return fmt.Errorf("unsupported signature %q (want %s...)", sig, signaturePrefix)
+	}
+	if !hmac.Equal([]byte(sig), []byte(sign(content, key))) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// SignJSON returns the signature of a JSON bundle, to be stored in its
+// signature field. It covers the file's canonical form: its top-level
+// fields other than signature, each compacted, with the keys sorted. The
+// signature so survives reformatting but not a change to the data, and
+// doesn't depend on how a given release decodes the bundle.
+func SignJSON(data, key []byte) (string, error) {
+	canonical, _, err := canonicalJSON(data)
+	if err != nil {
+		return "", err
+	}
+	return sign(canonical, key), nil
+}
+
+// VerifyJSON checks the signature field of a JSON bundle against its
+// canonical form; see SignJSON.
+func VerifyJSON(data, key []byte) error {
+	canonical, sig, err := canonicalJSON(data)
+	if err != nil {
+		return err
+	}
+	return verify(canonical, sig, key)
+}
+
+// canonicalJSON returns the canonical form of a JSON bundle along with its
+// signature field.
+func canonicalJSON(data []byte) ([]byte, string, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, "", fmt.Errorf("failed to parse JSON bundle: %w", err)
+	}
+	var sig string
+	if raw, ok := fields["signature"]; ok {
+		if err := json.Unmarshal(raw, &sig); err != nil {
+			return nil, "", fmt.Errorf("bad signature field: %w", err)
+		}
+		delete(fields, "signature")
+	}
+	// Marshal sorts the keys and compacts each raw value.
+	canonical, err := json.Marshal(fields)
+	if err != nil {
+		return nil, "", fmt.Errorf("marshal bundle: %w", err)
+	}
+	return canonical, sig, nil
+}
+
+// SignMarkdown returns a Markdown bundle with a handoff-signature comment on
+// its last line, replacing the one there already. The signature covers
+// every byte before that line, the visible text as well as the payload.
+func SignMarkdown(data, key []byte) []byte {
+	content, _ := markdownSigned(data)
+	var buf bytes.Buffer
+	buf.Write(content)
+	if len(content) > 0 && !bytes.HasSuffix(content, []byte("\n")) {
+		buf.WriteByte('\n')
+	}
+	fmt.Fprintf(&buf, "<!-- handoff-signature: %s -->\n", sign(buf.Bytes(), key))
+	return buf.Bytes()
+}
+
+// VerifyMarkdown checks the signature on the last line of a Markdown bundle
+// against the rest of the file; see SignMarkdown.
+func VerifyMarkdown(data, key []byte) error {
+	content, sig := markdownSigned(data)
+	return verify(content, sig, key)
+}
+
+// MarkdownSignature returns the signature on the last line of a Markdown
+// bundle, or "" if there is none.
+func MarkdownSignature(data []byte) string {
+	_, sig := markdownSigned(data)
+	return sig
+}
+
+// markdownSigned splits a Markdown bundle into the part its signature
+// covers and the signature, which is only read from the last line. A
+// signature comment anywhere else, e.g. quoted in a diff, is content.
+func markdownSigned(data []byte) (content []byte, sig string) {
+	loc := signatureLine.FindSubmatchIndex(data)
+	if loc == nil {
+		return data, ""
+	}
+	end := loc[0]
+	if data[end] == '\n' {
+		end++ // the newline ends the signed content's last line
+	}
+	return data[:end], string(data[loc[2]:loc[3]])
+}
@@ -0,0 +1,116 @@
+package bundle_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"pgregory.net/rapid"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// TestSignatureSurvivesReformatting verifies that a signed JSON bundle still
+// verifies after its whitespace changes, and fails once its data does.
+func TestSignatureSurvivesReformatting(t *testing.T) {
+	key := []byte("secret")
+
+	rapid.Check(t, func(t *rapid.T) {
+		b := generateBundle(t)
+		data, err := (&bundle.JSONRenderer{}).Render(b)
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if b.Signature, err = bundle.SignJSON(data, key); err != nil {
+			t.Fatalf("SignJSON: %v", err)
+		}
+		signed, err := (&bundle.JSONRenderer{}).Render(b)
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, signed); err != nil {
+			t.Fatalf("Compact: %v", err)
+		}
+		if err := bundle.VerifyJSON(compact.Bytes(), key); err != nil {
+			t.Fatalf("reformatted bundle: %v", err)
+		}
+		if err := bundle.VerifyJSON(signed, []byte("other")); !errors.Is(err, bundle.ErrBadSignature) {
+			t.Fatalf("wrong key: got %v, want ErrBadSignature", err)
+		}
+
+		b.Annotations[0].Message += "!"
+		modified, err := (&bundle.JSONRenderer{}).Render(b)
+		if err != nil {
+			t.Fatalf("Render: %v", err)
+		}
+		if err := bundle.VerifyJSON(modified, key); !errors.Is(err, bundle.ErrBadSignature) {
+			t.Fatalf("modified bundle: got %v, want ErrBadSignature", err)
+		}
+	})
+}
+
+// TestJSONSignatureIgnoresDecoding verifies that a JSON signature covers the
+// file's own fields, so it holds for fields this release doesn't know and
+// for an older schema_version, but not when either is changed.
+func TestJSONSignatureIgnoresDecoding(t *testing.T) {
+	key := []byte("secret")
+	data := []byte(`{"schema_version": 1, "session": {"id": "old"}, "added_later": [1, 2]}`)
+	sig, err := bundle.SignJSON(data, key)
+	if err != nil {
+		t.Fatalf("SignJSON: %v", err)
+	}
+	signed := bytes.Replace(data, []byte(`{"schema_version"`), []byte(`{"signature": "`+sig+`", "schema_version"`), 1)
+	if err := bundle.VerifyJSON(signed, key); err != nil {
+		t.Errorf("VerifyJSON: %v", err)
+	}
+	for old, changed := range map[string]string{`"schema_version": 1`: `"schema_version": 2`, `[1, 2]`: `[1, 3]`} {
+		if err := bundle.VerifyJSON(bytes.Replace(signed, []byte(old), []byte(changed), 1), key); !errors.Is(err, bundle.ErrBadSignature) {
+			t.Errorf("%s: got %v, want ErrBadSignature", changed, err)
+		}
+	}
+	if err := bundle.VerifyJSON(data, key); !errors.Is(err, bundle.ErrUnsigned) {
+		t.Errorf("unsigned: got %v, want ErrUnsigned", err)
+	}
+}
+
+// TestMarkdownSignature verifies that the signature comment is appended once,
+// replaced on re-signing, covers the visible text, and is only read from the
+// last line.
+func TestMarkdownSignature(t *testing.T) {
+	key := []byte("secret")
+	data := []byte("<!-- handoff-bundle-version: 3 -->\n# Handoff\n")
+
+	once := bundle.SignMarkdown(data, key)
+	sig := bundle.MarkdownSignature(once)
+	if !strings.HasPrefix(sig, "hmac-sha256:") {
+		t.Fatalf("MarkdownSignature: got %q", sig)
+	}
+	if err := bundle.VerifyMarkdown(once, key); err != nil {
+		t.Errorf("VerifyMarkdown: %v", err)
+	}
+	twice := bundle.SignMarkdown(once, key)
+	if want := string(data) + "<!-- handoff-signature: " + sig + " -->\n"; string(twice) != want {
+		t.Errorf("re-signed:\ngot  %q\nwant %q", twice, want)
+	}
+
+	edited := bytes.Replace(once, []byte("# Handoff"), []byte("# Handoff (edited)"), 1)
+	if err := bundle.VerifyMarkdown(edited, key); !errors.Is(err, bundle.ErrBadSignature) {
+		t.Errorf("edited body: got %v, want ErrBadSignature", err)
+	}
+
+	// A signature line quoted in a diff is content, not the signature.
+	quoted := []byte("<!-- handoff-bundle-version: 3 -->\n```diff\n<!-- handoff-signature: " + sig + " -->\n```\n")
+	if got := bundle.MarkdownSignature(quoted); got != "" {
+		t.Errorf("quoted signature read as the bundle's: %q", got)
+	}
+	if err := bundle.VerifyMarkdown(quoted, key); !errors.Is(err, bundle.ErrUnsigned) {
+		t.Errorf("quoted signature: got %v, want ErrUnsigned", err)
+	}
+	if err := bundle.VerifyMarkdown(bundle.SignMarkdown(quoted, key), key); err != nil {
+		t.Errorf("signed bundle quoting a signature: %v", err)
+	}
+}
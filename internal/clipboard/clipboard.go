@@ -0,0 +1,83 @@
+// Package clipboard reads and writes the system clipboard by shelling out to
+// the platform's clipboard tools.
+package clipboard
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// copyCommands lists the copy commands tried on each platform, in order
+// of preference. Each reads the text to copy from stdin.
+var copyCommands = map[string][][]string{
+	"darwin":  {{"pbcopy"}},
+	"windows": {{"clip.exe"}},
+	"linux": {
+		{"wl-copy"},
+		{"xclip", "-selection", "clipboard"},
+		{"xsel", "--clipboard", "--input"},
+		{"clip.exe"}, // WSL
+	},
+}
+
+// pasteCommands lists the paste commands tried on each platform, in the same
+// order as copyCommands. Each writes the clipboard contents to stdout.
+var pasteCommands = map[string][][]string{
+	"darwin":  {{"pbpaste"}},
+	"windows": {{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}},
+	"linux": {
+		{"wl-paste", "--no-newline"},
+		{"xclip", "-selection", "clipboard", "-out"},
+		{"xsel", "--clipboard", "--output"},
+		{"powershell.exe", "-NoProfile", "-Command", "Get-Clipboard"}, // WSL
+	},
+}
+
+// Copy writes text to the system clipboard using the first available
+// platform tool.
+func Copy(text string) error {
+	path, args, err := findTool(copyCommands)
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(path, args...)
+	cmd.Stdin = strings.NewReader(text)
+	return cmd.Run()
+}
+
+// Paste returns the contents of the system clipboard using the first
+// available platform tool.
+func Paste() (string, error) {
+	path, args, err := findTool(pasteCommands)
+	if err != nil {
+		return "", err
+	}
+	out, err := exec.Command(path, args...).Output()
+	if err != nil {
+		return "", err
+	}
+	if strings.EqualFold(filepath.Base(path), "powershell.exe") {
+		// Get-Clipboard uses CRLF line endings and adds a trailing newline.
+		out = []byte(strings.TrimSuffix(strings.ReplaceAll(string(out), "\r\n", "\n"), "\n"))
+	}
+	return string(out), nil
+}
+
+// findTool returns the path and arguments of the first command in
+// commands[GOOS] that is installed.
+func findTool(commands map[string][][]string) (string, []string, error) {
+	candidates := commands[runtime.GOOS]
+	if runtime.GOOS == "linux" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		candidates = candidates[1:] // wl-copy/wl-paste need a Wayland session
+	}
+	for _, args := range candidates {
+		if path, err := exec.LookPath(args[0]); err == nil {
+			return path, args[1:], nil
+		}
+	}
+	return "", nil, errors.New("no clipboard tool found (install pbcopy, xclip, xsel or wl-copy)")
+}
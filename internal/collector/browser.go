@@ -0,0 +1,425 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// BrowserCollector captures the tabs open in Chrome/Chromium and Firefox by
+// reading their session files. Like EditorCollector it is best-effort:
+// missing or unreadable browser data produces warnings, never errors.
+type BrowserCollector struct {
+	// Home overrides the user's home directory (used in tests).
+	Home string
+}
+
+// browserReader collects tabs from one browser family.
+type browserReader func(home string) (tabs []bundle.BrowserTab, warnings []string)
+
+// Name implements Collector.
+func (bc *BrowserCollector) Name() string { return "browser" }
+
+// Collect implements Collector.
+func (bc *BrowserCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	home := bc.Home
+	if home == "" {
+		h, err := os.UserHomeDir()
+		if err != nil {
+			return CollectorResult{
+				Warnings: []string{fmt.Sprintf("browser tab collection skipped: cannot determine home dir: %v", err)},
+			}, nil
+		}
+		home = h
+	}
+
+	seen := make(map[string]bool)
+	var allTabs []bundle.BrowserTab
+	var allWarnings []string
+	for _, reader := range []browserReader{collectChromium, collectFirefox} {
+		if err := ctx.Err(); err != nil {
+			return CollectorResult{}, err
+		}
+		tabs, warnings := reader(home)
+		allWarnings = append(allWarnings, warnings...)
+		for _, t := range tabs {
+			if !seen[t.URL] {
+				seen[t.URL] = true
+				allTabs = append(allTabs, t)
+			}
+		}
+	}
+
+	if len(allTabs) == 0 && len(allWarnings) == 0 {
+		allWarnings = append(allWarnings, "no browser session data found")
+	}
+	return CollectorResult{BrowserTabs: allTabs, Warnings: allWarnings}, nil
+}
+
+// isWebURL reports whether u is worth recording (skips new-tab and settings pages).
+func isWebURL(u string) bool {
+	return strings.HasPrefix(u, "http://") || strings.HasPrefix(u, "https://")
+}
+
+// ── Chrome / Chromium ───
+
+// chromiumBrowsers lists each browser's user data directory, relative to the
+// platform's config root.
+var chromiumBrowsers = []struct {
+	name                   string
+	linux, darwin, windows string
+}{
+	{"Chrome", "google-chrome", filepath.Join("Google", "Chrome"), filepath.Join("Google", "Chrome", "User Data")},
+	{"Chromium", "chromium", "Chromium", filepath.Join("Chromium", "User Data")},
+}
+
+func chromiumUserDataDir(home, linux, darwin, windows string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", darwin)
+	case "windows":
+		return filepath.Join(os.Getenv("LOCALAPPDATA"), windows)
+	default:
+		return filepath.Join(home, ".config", linux)
+	}
+}
+
+func collectChromium(home string) ([]bundle.BrowserTab, []string) {
+	var tabs []bundle.BrowserTab
+	var warnings []string
+	for _, b := range chromiumBrowsers {
+		userData := chromiumUserDataDir(home, b.linux, b.darwin, b.windows)
+		sessionsDir := filepath.Join(userData, "Default", "Sessions")
+		path, err := newestFile(sessionsDir, "Session_")
+		if err != nil {
+			if !os.IsNotExist(err) {
+				warnings = append(warnings, fmt.Sprintf("%s session data unavailable (%s): %v", b.name, sessionsDir, err))
+			}
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s session data unavailable (%s): %v", b.name, path, err))
+			continue
+		}
+		found, err := parseSNSS(data)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to parse %s session (%s): %v", b.name, path, err))
+			continue
+		}
+		tabs = append(tabs, found...)
+	}
+	return tabs, warnings
+}
+
+// newestFile returns the lexically greatest file in dir starting with prefix.
+// Chromium suffixes session files with a timestamp, so that's the newest.
+func newestFile(dir, prefix string) (string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", err
+	}
+	var names []string
+	for _, e := range entries {
+		if !e.IsDir() && strings.HasPrefix(e.Name(), prefix) {
+			names = append(names, e.Name())
+		}
+	}
+	if len(names) == 0 {
+		return "", os.ErrNotExist
+	}
+	sort.Strings(names)
+	return filepath.Join(dir, names[len(names)-1]), nil
+}
+
+// SNSS command IDs used by Chromium's session service.
+const (
+	snssUpdateTabNavigation        = 6
+	snssSetSelectedNavigationIndex = 7
+	snssTabClosed                  = 16
+)
+
+// parseSNSS extracts the current URL and title of each open tab from a
+// Chromium SNSS session file: a "SNSS" header followed by records of
+// (uint16 size, uint8 command id, payload).
+func parseSNSS(data []byte) ([]bundle.BrowserTab, error) {
+	if len(data) < 8 || string(data[:4]) != "SNSS" {
+		return nil, errors.New("not an SNSS file")
+	}
+
+	type nav struct {
+		url, title string
+	}
+	var order []int32
+	navs := make(map[int32]map[int32]nav)
+	lastNav := make(map[int32]int32)
+	selected := make(map[int32]int32)
+	closed := make(map[int32]bool)
+
+	for i := 8; i+2 <= len(data); {
+		size := int(binary.LittleEndian.Uint16(data[i:]))
+		i += 2
+		if size == 0 || i+size > len(data) {
+			break // truncated trailing record (file still being written)
+		}
+		id, payload := data[i], data[i+1:i+size]
+		i += size
+
+		switch id {
+		case snssUpdateTabNavigation:
+			p := pickleReader{buf: payload}
+			p.skip(4) // pickle header: payload size
+			tab, index := p.int32(), p.int32()
+			url, title := p.string(), p.string16()
+			if p.err {
+				continue
+			}
+			if navs[tab] == nil {
+				navs[tab] = make(map[int32]nav)
+				order = append(order, tab)
+			}
+			navs[tab][index] = nav{url: url, title: title}
+			lastNav[tab] = index
+		case snssSetSelectedNavigationIndex:
+			if len(payload) >= 8 {
+				tab := int32(binary.LittleEndian.Uint32(payload))
+				selected[tab] = int32(binary.LittleEndian.Uint32(payload[4:]))
+			}
+		case snssTabClosed:
+			if len(payload) >= 4 {
+				closed[int32(binary.LittleEndian.Uint32(payload))] = true
+			}
+		}
+	}
+
+	var tabs []bundle.BrowserTab
+	for _, tab := range order {
+		if closed[tab] {
+			continue
+		}
+		index, ok := selected[tab]
+		if _, has := navs[tab][index]; !ok || !has {
+			index = lastNav[tab]
+		}
+		n := navs[tab][index]
+		if isWebURL(n.url) {
+			tabs = append(tabs, bundle.BrowserTab{URL: n.url, Title: n.title})
+		}
+	}
+	return tabs, nil
+}
+
+// pickleReader reads fields from a Chromium base::Pickle payload. Fields are
+// 4-byte aligned; any out-of-bounds read sets err.
+type pickleReader struct {
+	buf []byte
+	pos int
+	err bool
+}
+
+func (p *pickleReader) skip(n int) {
+	if p.pos+n > len(p.buf) {
+		p.err = true
+		return
+	}
+	p.pos = min(p.pos+(n+3)&^3, len(p.buf))
+}
+
+func (p *pickleReader) int32() int32 {
+	if p.err || p.pos+4 > len(p.buf) {
+		p.err = true
+		return 0
+	}
+	v := int32(binary.LittleEndian.Uint32(p.buf[p.pos:]))
+	p.pos += 4
+	return v
+}
+
+func (p *pickleReader) string() string {
+	n := int(p.int32())
+	if p.err || n < 0 || p.pos+n > len(p.buf) {
+		p.err = true
+		return ""
+	}
+	s := string(p.buf[p.pos : p.pos+n])
+	p.skip(n)
+	return s
+}
+
+func (p *pickleReader) string16() string {
+	n := int(p.int32())
+	if p.err || n < 0 || p.pos+2*n > len(p.buf) {
+		p.err = true
+		return ""
+	}
+	units := make([]uint16, n)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16(p.buf[p.pos+2*i:])
+	}
+	p.skip(2 * n)
+	return string(utf16.Decode(units))
+}
+
+// ── Firefox ────
+
+func firefoxProfilesDir(home string) string {
+	switch runtime.GOOS {
+	case "darwin":
+		return filepath.Join(home, "Library", "Application Support", "Firefox", "Profiles")
+	case "windows":
+		return filepath.Join(os.Getenv("APPDATA"), "Mozilla", "Firefox", "Profiles")
+	default:
+		return filepath.Join(home, ".mozilla", "firefox")
+	}
+}
+
+func collectFirefox(home string) ([]bundle.BrowserTab, []string) {
+	profilesDir := firefoxProfilesDir(home)
+	entries, err := os.ReadDir(profilesDir)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			return nil, []string{fmt.Sprintf("Firefox profiles unavailable (%s): %v", profilesDir, err)}
+		}
+		return nil, nil
+	}
+
+	var tabs []bundle.BrowserTab
+	var warnings []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		path := filepath.Join(profilesDir, e.Name(), "sessionstore-backups", "recovery.jsonlz4")
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // profile not in use
+		}
+		found, err := parseFirefoxSession(data)
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("failed to parse Firefox session (%s): %v", path, err))
+			continue
+		}
+		tabs = append(tabs, found...)
+	}
+	return tabs, warnings
+}
+
+// parseFirefoxSession decodes a mozlz4-compressed sessionstore file and
+// returns the current entry of every open tab.
+func parseFirefoxSession(data []byte) ([]bundle.BrowserTab, error) {
+	const magic = "mozLz40\x00"
+	if len(data) < len(magic)+4 || string(data[:len(magic)]) != magic {
+		return nil, errors.New("not a mozlz4 file")
+	}
+	size := binary.LittleEndian.Uint32(data[len(magic):])
+	raw, err := decodeLZ4Block(data[len(magic)+4:], int(size))
+	if err != nil {
+		return nil, err
+	}
+
+	var state struct {
+		Windows []struct {
+			Tabs []struct {
+				Index   int `json:"index"` // 1-based into Entries
+				Entries []struct {
+					URL   string `json:"url"`
+					Title string `json:"title"`
+				} `json:"entries"`
+			} `json:"tabs"`
+		} `json:"windows"`
+	}
+	if err := json.Unmarshal(raw, &state); err != nil {
+		return nil, fmt.Errorf("parse session JSON: %w", err)
+	}
+
+	var tabs []bundle.BrowserTab
+	for _, w := range state.Windows {
+		for _, t := range w.Tabs {
+			if len(t.Entries) == 0 {
+				continue
+			}
+			i := t.Index - 1
+			if i < 0 || i >= len(t.Entries) {
+				i = len(t.Entries) - 1
+			}
+			entry := t.Entries[i]
+			if isWebURL(entry.URL) {
+				tabs = append(tabs, bundle.BrowserTab{URL: entry.URL, Title: entry.Title})
+			}
+		}
+	}
+	return tabs, nil
+}
+
+// decodeLZ4Block decompresses a raw LZ4 block (no frame header) whose
+// decompressed length is size.
+func decodeLZ4Block(src []byte, size int) ([]byte, error) {
+	errCorrupt := errors.New("corrupt lz4 data")
+	dst := make([]byte, 0, size)
+
+	// readLen extends a 4-bit length with 255-valued continuation bytes.
+	readLen := func(i *int, n int) (int, bool) {
+		if n != 15 {
+			return n, true
+		}
+		for {
+			if *i >= len(src) {
+				return 0, false
+			}
+			b := src[*i]
+			*i++
+			n += int(b)
+			if b != 255 {
+				return n, true
+			}
+		}
+	}
+
+	for i := 0; i < len(src); {
+		token := src[i]
+		i++
+
+		lit, ok := readLen(&i, int(token>>4))
+		if !ok || i+lit > len(src) {
+			return nil, errCorrupt
+		}
+		dst = append(dst, src[i:i+lit]...)
+		i += lit
+		if i == len(src) {
+			break // the last sequence has literals only
+		}
+
+		if i+2 > len(src) {
+			return nil, errCorrupt
+		}
+		offset := int(src[i]) | int(src[i+1])<<8
+		i += 2
+		if offset == 0 || offset > len(dst) {
+			return nil, errCorrupt
+		}
+		match, ok := readLen(&i, int(token&15))
+		if !ok {
+			return nil, errCorrupt
+		}
+		match += 4
+		start := len(dst) - offset
+		for k := 0; k < match; k++ { // byte-wise: matches may overlap
+			dst = append(dst, dst[start+k])
+		}
+	}
+	if len(dst) != size {
+		return nil, errCorrupt
+	}
+	return dst, nil
+}
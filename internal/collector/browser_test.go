@@ -0,0 +1,133 @@
+package collector
+
+import (
+	"context"
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// snssNavigation builds an UpdateTabNavigation record payload.
+func snssNavigation(tab, index int32, url, title string) []byte {
+	var body []byte
+	put32 := func(v int32) { body = binary.LittleEndian.AppendUint32(body, uint32(v)) }
+	pad := func() {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+	}
+	put32(tab)
+	put32(index)
+	put32(int32(len(url)))
+	body = append(body, url...)
+	pad()
+	units := utf16.Encode([]rune(title))
+	put32(int32(len(units)))
+	for _, u := range units {
+		body = binary.LittleEndian.AppendUint16(body, u)
+	}
+	pad()
+	return append(binary.LittleEndian.AppendUint32(nil, uint32(len(body))), body...)
+}
+
+// snssFile assembles records into an SNSS file.
+func snssFile(records ...[]byte) []byte {
+	data := []byte("SNSS")
+	data = binary.LittleEndian.AppendUint32(data, 3)
+	for _, r := range records {
+		data = binary.LittleEndian.AppendUint16(data, uint16(len(r)))
+		data = append(data, r...)
+	}
+	return data
+}
+
+// mozLZ4 wraps data in a mozlz4 container using a literal-only LZ4 block.
+func mozLZ4(data []byte) []byte {
+	out := append([]byte("mozLz40\x00"), binary.LittleEndian.AppendUint32(nil, uint32(len(data)))...)
+	out = append(out, 0xF0)
+	for n := len(data) - 15; ; n -= 255 {
+		if n < 255 {
+			out = append(out, byte(n))
+			break
+		}
+		out = append(out, 255)
+	}
+	return append(out, data...)
+}
+
+// TestParseSNSS verifies that the selected navigation of each open tab is
+// returned, closed tabs are dropped, and non-web pages are skipped.
+func TestParseSNSS(t *testing.T) {
+	closeTab := binary.LittleEndian.AppendUint32(nil, 3)
+	selectFirst := binary.LittleEndian.AppendUint32(binary.LittleEndian.AppendUint32(nil, 1), 0)
+	data := snssFile(
+		append([]byte{snssUpdateTabNavigation}, snssNavigation(1, 0, "https://go.dev/doc", "Docs")...),
+		append([]byte{snssUpdateTabNavigation}, snssNavigation(1, 1, "https://go.dev/blog", "Blog")...),
+		append([]byte{snssSetSelectedNavigationIndex}, selectFirst...),
+		append([]byte{snssUpdateTabNavigation}, snssNavigation(2, 0, "chrome://newtab/", "New Tab")...),
+		append([]byte{snssUpdateTabNavigation}, snssNavigation(3, 0, "https://closed.example", "Gone")...),
+		append([]byte{snssTabClosed}, closeTab...),
+	)
+
+	tabs, err := parseSNSS(data)
+	if err != nil {
+		t.Fatalf("parseSNSS: %v", err)
+	}
+	want := []bundle.BrowserTab{{URL: "https://go.dev/doc", Title: "Docs"}}
+	if len(tabs) != len(want) || tabs[0] != want[0] {
+		t.Errorf("tabs = %+v, want %+v", tabs, want)
+	}
+}
+
+// TestBrowserCollectorFirefox verifies that tabs are read from a Firefox
+// profile's recovery.jsonlz4 under the given home directory.
+func TestBrowserCollectorFirefox(t *testing.T) {
+	home := t.TempDir()
+	dir := filepath.Join(firefoxProfilesDir(home), "abc.default", "sessionstore-backups")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	state := `{"windows":[{"tabs":[
+		{"index":2,"entries":[{"url":"https://old.example","title":"Old"},{"url":"https://staging.example/app","title":"Staging"}]},
+		{"index":1,"entries":[{"url":"about:preferences","title":"Settings"}]}
+	]}]}`
+	if err := os.WriteFile(filepath.Join(dir, "recovery.jsonlz4"), mozLZ4([]byte(state)), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	bc := &BrowserCollector{Home: home}
+	result, err := bc.Collect(context.Background(), &session.Session{StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	want := bundle.BrowserTab{URL: "https://staging.example/app", Title: "Staging"}
+	if len(result.BrowserTabs) != 1 || result.BrowserTabs[0] != want {
+		t.Errorf("BrowserTabs = %+v, want [%+v]", result.BrowserTabs, want)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+}
+
+// TestBrowserCollectorNoData verifies that a home without browser data yields
+// a warning rather than an error.
+func TestBrowserCollectorNoData(t *testing.T) {
+	bc := &BrowserCollector{Home: t.TempDir()}
+	result, err := bc.Collect(context.Background(), &session.Session{StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	if len(result.BrowserTabs) != 0 {
+		t.Errorf("expected no tabs, got %+v", result.BrowserTabs)
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "no browser session data") {
+		t.Errorf("expected a no-data warning, got %v", result.Warnings)
+	}
+}
@@ -2,6 +2,7 @@ package collector
 
 import (
 	"context"
+	"log/slog"
 
 	"github.com/fakeyudi/handoff/internal/bundle"
 	"github.com/fakeyudi/handoff/internal/session"
@@ -9,6 +10,9 @@ import (
 
 // Collector gathers one category of developer activity data.
 type Collector interface {
+	// Name is a short lowercase name for the collector, e.g. "git", used to
+	// label progress and warnings.
+	Name() string
 	// Collect runs the collection logic and returns its contribution to the bundle.
 	// Warnings are returned as non-fatal issues in CollectorResult.Warnings.
 	Collect(ctx context.Context, sess *session.Session) (CollectorResult, error)
@@ -16,9 +20,32 @@ type Collector interface {
 
 // CollectorResult holds the output of a single collector.
 type CollectorResult struct {
-	FileEdits  []session.FileEdit // populated by FileCollector
-	Commands   []bundle.Command   // populated by ShellCollector
-	GitInfo    *bundle.GitInfo    // populated by GitCollector
-	EditorTabs []string           // populated by EditorCollector
-	Warnings   []string           // non-fatal issues encountered
+	FileEdits   []session.FileEdit  // populated by FileCollector
+	Commands    []bundle.Command    // populated by ShellCollector
+	GitInfo     *bundle.GitInfo     // populated by GitCollector
+	EditorTabs  []string            // populated by EditorCollector
+	Processes   []string            // populated by ProcessCollector
+	Env         map[string]string   // populated by EnvCollector
+	Toolchains  map[string]string   // populated by ToolchainCollector
+	BrowserTabs []bundle.BrowserTab // populated by BrowserCollector
+	Tmux        *bundle.TmuxLayout  // populated by TmuxCollector
+	Warnings    []string            // non-fatal issues encountered
+}
+
+type loggerKey struct{}
+
+// WithLogger returns a copy of ctx carrying logger. Collectors log what they
+// look at (the history file, editor storage directories and so on) to it at
+// debug level, for diagnosing unexpected results with --verbose.
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// logFrom returns the logger carried by ctx, or one that discards
+// everything.
+func logFrom(ctx context.Context) *slog.Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return l
+	}
+	return slog.New(slog.DiscardHandler)
 }
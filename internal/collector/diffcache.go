@@ -0,0 +1,156 @@
+package collector
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diffCacheMaxAge is how long on-disk cache entries are kept unused.
+const diffCacheMaxAge = 7 * 24 * time.Hour
+
+// DiffCache remembers the diffs FileCollector captures, so capturing an
+// unchanged file again (a --dry-run followed by the real stop, say) doesn't
+// run git again. Entries are keyed by the file's path, mtime and size and by
+// the repository state it was diffed against (the base commit and the
+// index), so editing, committing or staging the file recomputes its diff.
+// It is safe for concurrent use; the zero value caches in memory only.
+type DiffCache struct {
+	// Dir, if set, also keeps entries on disk there, one file each, so
+	// they outlive the process. Entries older than a week are removed.
+	Dir string
+
+	mu      sync.Mutex
+	entries map[string]string
+	pruned  sync.Once
+}
+
+// get returns the cached diff for key.
+func (c *DiffCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	diff, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok || c.Dir == "" {
+		return diff, ok
+	}
+	data, err := os.ReadFile(filepath.Join(c.Dir, key))
+	if err != nil {
+		return "", false
+	}
+	c.remember(key, string(data))
+	return string(data), true
+}
+
+// put caches diff under key. Disk errors are ignored: the cache only saves
+// work.
+func (c *DiffCache) put(key, diff string) {
+	c.remember(key, diff)
+	if c.Dir == "" {
+		return
+	}
+	c.pruned.Do(c.prune)
+	if err := os.MkdirAll(c.Dir, 0o700); err != nil {
+		return
+	}
+	tmp, err := os.CreateTemp(c.Dir, key+".*.tmp")
+	if err != nil {
+		return
+	}
+	_, err = tmp.WriteString(diff)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || os.Rename(tmp.Name(), filepath.Join(c.Dir, key)) != nil {
+		os.Remove(tmp.Name())
+	}
+}
+
+func (c *DiffCache) remember(key, diff string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = make(map[string]string)
+	}
+	c.entries[key] = diff
+}
+
+// prune removes on-disk entries older than diffCacheMaxAge.
+func (c *DiffCache) prune() {
+	entries, err := os.ReadDir(c.Dir)
+	if err != nil {
+		return
+	}
+	cutoff := time.Now().Add(-diffCacheMaxAge)
+	for _, e := range entries {
+		if info, err := e.Info(); err == nil && info.ModTime().Before(cutoff) {
+			os.Remove(filepath.Join(c.Dir, e.Name()))
+		}
+	}
+}
+
+// diffCacheKey returns the cache key for path diffed against repoState, or
+// "" if path can't be stat'ed (e.g. it was deleted), which isn't cached.
+func diffCacheKey(path, repoState string) string {
+	info, err := os.Stat(path)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(fmt.Appendf(nil, "%s\x00%d\x00%d\x00%s",
+		path, info.ModTime().UnixNano(), info.Size(), repoState))
+	return hex.EncodeToString(sum[:])
+}
+
+// repoState describes what diffs in workDir are taken against: the commit
+// base resolves to and the index's mtime and size. Outside a repository it
+// is "", since the fallback diff depends only on the file.
+func repoState(ctx context.Context, workDir, base string) string {
+	git := func(args ...string) string {
+		cmd := exec.CommandContext(ctx, "git", args...)
+		cmd.Dir = workDir
+		out, err := cmd.Output()
+		if err != nil {
+			return ""
+		}
+		return strings.TrimSpace(string(out))
+	}
+	commit := git("rev-parse", "--verify", "--quiet", base+"^{commit}")
+	index := git("rev-parse", "--git-path", "index")
+	if commit == "" && index == "" {
+		return ""
+	}
+	if index != "" && !filepath.IsAbs(index) {
+		index = filepath.Join(workDir, index)
+	}
+	state := commit
+	if info, err := os.Stat(index); err == nil {
+		state += fmt.Sprintf(" %d %d", info.ModTime().UnixNano(), info.Size())
+	}
+	return state
+}
+
+// cachedFileDiff is captureFileDiff through cache; a nil cache always
+// captures.
+func cachedFileDiff(ctx context.Context, cache *DiffCache, path, workDir, base, state string) string {
+	if cache == nil {
+		return captureFileDiff(ctx, path, workDir, base)
+	}
+	key := diffCacheKey(path, state)
+	if key == "" {
+		return captureFileDiff(ctx, path, workDir, base)
+	}
+	if diff, ok := cache.get(key); ok {
+		return diff
+	}
+	diff := captureFileDiff(ctx, path, workDir, base)
+	if ctx.Err() == nil {
+		cache.put(key, diff)
+	}
+	return diff
+}
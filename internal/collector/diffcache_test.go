@@ -0,0 +1,84 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDiffCache verifies that an unchanged file's diff comes from the cache,
+// and that editing or committing the file, or bypassing the cache,
+// captures it again.
+func TestDiffCache(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	path := filepath.Join(dir, "main.go")
+	write := func(content string, mtime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, mtime, mtime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("init", "-q")
+	write("package main\n", time.Now())
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+
+	ctx := context.Background()
+	capture := func(cache *DiffCache) string {
+		return cachedFileDiff(ctx, cache, path, dir, "HEAD", repoState(ctx, dir, "HEAD"))
+	}
+	// Planting a fake entry shows whether a capture was served from the cache.
+	plant := func(cache *DiffCache) {
+		key := diffCacheKey(path, repoState(ctx, dir, "HEAD"))
+		cache.remember(key, "cached")
+	}
+
+	edited := time.Now().Add(-time.Minute)
+	write("package main\n\nfunc a() {}\n", edited)
+	cache := &DiffCache{Dir: filepath.Join(t.TempDir(), "cache")}
+	if d := capture(cache); !strings.Contains(d, "+func a() {}") {
+		t.Fatalf("first capture: got\n%s", d)
+	}
+	plant(cache)
+	if d := capture(cache); d != "cached" {
+		t.Errorf("unchanged file: expected the cached diff, got\n%s", d)
+	}
+	if d := capture(nil); d == "cached" {
+		t.Error("nil cache: expected a fresh capture")
+	}
+
+	// The on-disk copy serves a new process's cache.
+	if d := capture(&DiffCache{Dir: cache.Dir}); !strings.Contains(d, "+func a() {}") {
+		t.Errorf("from disk: got\n%s", d)
+	}
+
+	write("package main\n\nfunc b() {}\n", edited.Add(time.Second))
+	if d := capture(cache); !strings.Contains(d, "+func b() {}") {
+		t.Errorf("edited file: expected a fresh capture, got\n%s", d)
+	}
+
+	plant(cache)
+	git("commit", "-q", "-am", "b")
+	if d := capture(cache); d == "cached" {
+		t.Error("after commit: expected a fresh capture")
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"os"
 	"os/exec"
@@ -28,7 +30,10 @@ type EditorCollector struct {
 }
 
 // editorReader is a function that attempts to collect open tabs from one editor.
-type editorReader func(home string) (tabs []string, warnings []string)
+type editorReader func(ctx context.Context, home string) (tabs []string, warnings []string)
+
+// Name implements Collector.
+func (e *EditorCollector) Name() string { return "editors" }
 
 // Collect tries all supported editors, merges their results, and filters to
 // only files/directories under sess.WorkDir so the bundle stays focused on
@@ -38,7 +43,7 @@ func (e *EditorCollector) Collect(ctx context.Context, sess *session.Session) (C
 
 	// StateDir is used in tests to override the VS Code storage path only.
 	if e.StateDir != "" {
-		tabs, warnings := collectVSCodeFamily("VS Code (test)", e.StateDir)
+		tabs, warnings := collectVSCodeFamily(ctx, "VS Code (test)", e.StateDir)
 		if len(tabs) == 0 && len(warnings) == 0 {
 			warnings = []string{fmt.Sprintf("VS Code workspace storage unavailable (%s)", e.StateDir)}
 		}
@@ -64,7 +69,10 @@ func (e *EditorCollector) Collect(ctx context.Context, sess *session.Session) (C
 	var allWarnings []string
 
 	for _, reader := range readers {
-		tabs, warnings := reader(home)
+		if err := ctx.Err(); err != nil {
+			return CollectorResult{}, err
+		}
+		tabs, warnings := reader(ctx, home)
 		allWarnings = append(allWarnings, warnings...)
 		for _, t := range tabs {
 			if !seen[t] {
@@ -75,7 +83,9 @@ func (e *EditorCollector) Collect(ctx context.Context, sess *session.Session) (C
 	}
 
 	// Filter to only files/dirs under the session working directory.
+	found := len(allTabs)
 	allTabs = filterToWorkDir(allTabs, workDir)
+	logFrom(ctx).Debug("editor tabs", "found", found, "under_workdir", len(allTabs))
 
 	if len(allTabs) == 0 {
 		allWarnings = append(allWarnings, fmt.Sprintf("no open editor tabs found under %s", workDir))
@@ -85,24 +95,45 @@ func (e *EditorCollector) Collect(ctx context.Context, sess *session.Session) (C
 }
 
 // filterToWorkDir returns only paths that are under workDir.
-// If workDir is empty, all paths are returned unchanged.
+// If workDir is empty, all paths are returned unchanged. Paths are compared
+// both cleaned ("proj/../proj/a.go") and with symlinks resolved, so a file
+// opened through a symlinked project directory still counts.
 func filterToWorkDir(paths []string, workDir string) []string {
 	if workDir == "" {
 		return paths
 	}
-	prefix := workDir
-	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
-		prefix += string(filepath.Separator)
-	}
+	cleanDir, realDir := filepath.Clean(workDir), resolvePath(workDir)
 	var result []string
 	for _, p := range paths {
-		if p == workDir || strings.HasPrefix(p, prefix) {
+		if isUnder(filepath.Clean(p), cleanDir) || isUnder(resolvePath(p), realDir) {
 			result = append(result, p)
 		}
 	}
 	return result
 }
 
+// resolvePath cleans path and resolves its symlinks. When path can't be
+// resolved, e.g. because the file was deleted, its nearest existing parent
+// is resolved instead; if none can be, path is only cleaned.
+func resolvePath(path string) string {
+	path = filepath.Clean(path)
+	if real, err := filepath.EvalSymlinks(path); err == nil {
+		return real
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path
+	}
+	return filepath.Join(resolvePath(parent), filepath.Base(path))
+}
+
+// isUnder reports whether path is dir or lies inside it. Both must be clean;
+// either may use / or \ separators.
+func isUnder(path, dir string) bool {
+	_, ok := session.RelPath(path, dir)
+	return ok
+}
+
 // ── VS Code fork family (VS Code, Kiro, Cursor, Windsurf, …) ───
 
 var vscodeAppNames = []struct {
@@ -116,14 +147,15 @@ var vscodeAppNames = []struct {
 	{"VSCodium", "VSCodium"},
 }
 
-func collectVSCodeFamilyAuto(home string) ([]string, []string) {
+func collectVSCodeFamilyAuto(ctx context.Context, home string) ([]string, []string) {
 	var allTabs []string
 	var allWarnings []string
 	seen := make(map[string]bool)
 
 	for _, app := range vscodeAppNames {
 		storageDir := vscodeStorageDir(home, app.appDir)
-		tabs, warnings := collectVSCodeFamily(app.name, storageDir)
+		logFrom(ctx).Debug("checking editor storage", "editor", app.name, "dir", storageDir)
+		tabs, warnings := collectVSCodeFamily(ctx, app.name, storageDir)
 		allWarnings = append(allWarnings, warnings...)
 		for _, t := range tabs {
 			if !seen[t] {
@@ -147,7 +179,7 @@ func vscodeStorageDir(home, appDir string) string {
 	}
 }
 
-func collectVSCodeFamily(editorName, storageDir string) ([]string, []string) {
+func collectVSCodeFamily(ctx context.Context, editorName, storageDir string) ([]string, []string) {
 	entries, err := os.ReadDir(storageDir)
 	if err != nil {
 		if !os.IsNotExist(err) {
@@ -157,7 +189,7 @@ func collectVSCodeFamily(editorName, storageDir string) ([]string, []string) {
 	}
 
 	seen := make(map[string]bool)
-	var tabs []string
+	var tabs, warnings []string
 
 	for _, entry := range entries {
 		if !entry.IsDir() {
@@ -165,10 +197,13 @@ func collectVSCodeFamily(editorName, storageDir string) ([]string, []string) {
 		}
 		workspaceDir := filepath.Join(storageDir, entry.Name())
 
-		// Prefer sqlite3 history.entries for actual open files.
+		// Prefer the state database's history.entries for actual open files.
 		dbPath := filepath.Join(workspaceDir, "state.vscdb")
 		if _, err := os.Stat(dbPath); err == nil {
-			files, err := readVSCodeDBTabs(dbPath)
+			files, err := readVSCodeDBTabs(ctx, dbPath)
+			if err != nil && ctx.Err() == nil {
+				warnings = append(warnings, fmt.Sprintf("%s open files unreadable (%s), listing the workspace folder instead: %v", editorName, dbPath, err))
+			}
 			if err == nil && len(files) > 0 {
 				for _, f := range files {
 					if !seen[f] {
@@ -202,16 +237,29 @@ func collectVSCodeFamily(editorName, storageDir string) ([]string, []string) {
 		}
 	}
 
-	return tabs, nil
+	return tabs, warnings
 }
 
-func readVSCodeDBTabs(dbPath string) ([]string, error) {
-	out, err := exec.Command("sqlite3", dbPath,
-		"SELECT value FROM ItemTable WHERE key='history.entries';").Output()
+// sqliteRetryDelays are the pauses between attempts to read a state database
+// that looked malformed, as one does when VS Code writes to it mid-read.
+var sqliteRetryDelays = []time.Duration{100 * time.Millisecond, 250 * time.Millisecond, 500 * time.Millisecond}
+
+// readVSCodeDBTabs returns the files in the editor history of a VS Code
+// state database, most recent first.
+func readVSCodeDBTabs(ctx context.Context, dbPath string) ([]string, error) {
+	value, _, err := readVSCodeItem(ctx, dbPath, "history.entries")
+	for i := 0; errors.Is(err, errSQLiteCorrupt) && i < len(sqliteRetryDelays); i++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(sqliteRetryDelays[i]):
+		}
+		value, _, err = readVSCodeItem(ctx, dbPath, "history.entries")
+	}
 	if err != nil {
-		return nil, fmt.Errorf("sqlite3: %w", err)
+		return nil, err
 	}
-	raw := strings.TrimSpace(string(out))
+	raw := strings.TrimSpace(value)
 	if raw == "" {
 		return nil, nil
 	}
@@ -269,7 +317,7 @@ type jetbrainsXMLProject struct {
 	} `xml:"component"`
 }
 
-func collectJetBrains(home string) ([]string, []string) {
+func collectJetBrains(_ context.Context, home string) ([]string, []string) {
 	var appSupportDir string
 	switch runtime.GOOS {
 	case "darwin":
@@ -297,10 +345,12 @@ func collectJetBrains(home string) ([]string, []string) {
 		if err != nil {
 			continue
 		}
-		for _, p := range projects {
-			if !seen[p] {
-				seen[p] = true
-				tabs = append(tabs, p)
+		for _, project := range projects {
+			for _, p := range jetbrainsProjectTabs(project, home) {
+				if !seen[p] {
+					seen[p] = true
+					tabs = append(tabs, p)
+				}
 			}
 		}
 	}
@@ -308,6 +358,89 @@ func collectJetBrains(home string) ([]string, []string) {
 	return tabs, nil
 }
 
+// jetbrainsProjectTabs returns the files open in project's editor, falling
+// back to the project folder itself when .idea/workspace.xml is unavailable
+// or lists no files.
+func jetbrainsProjectTabs(project, home string) []string {
+	files, err := parseJetBrainsWorkspace(filepath.Join(project, ".idea", "workspace.xml"), project, home)
+	if err != nil || len(files) == 0 {
+		return []string{project}
+	}
+	return files
+}
+
+// parseJetBrainsWorkspace extracts the open editor tabs recorded under the
+// FileEditorManager component of a project's workspace.xml. Splits nest
+// leaves arbitrarily deep, so the document is walked token by token rather
+// than unmarshalled into a fixed shape.
+func parseJetBrainsWorkspace(xmlPath, projectDir, home string) ([]string, error) {
+	f, err := os.Open(xmlPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	dec := xml.NewDecoder(f)
+	var stack []string
+	inManager := false
+	var files []string
+
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("parse %s: %w", xmlPath, err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "component" && xmlAttr(t, "name") == "FileEditorManager" {
+				inManager = true
+			}
+			parent := ""
+			if len(stack) > 0 {
+				parent = stack[len(stack)-1]
+			}
+			if inManager && t.Name.Local == "entry" && parent == "file" {
+				if p, ok := jetbrainsFileURL(xmlAttr(t, "file"), projectDir, home); ok {
+					files = append(files, p)
+				}
+			}
+			stack = append(stack, t.Name.Local)
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+			if t.Name.Local == "component" {
+				inManager = false
+			}
+		}
+	}
+	return files, nil
+}
+
+// jetbrainsFileURL converts a workspace.xml file URL such as
+// "file://$PROJECT_DIR$/main.go" to a local path. Non-file URLs (jar://,
+// temp://) are rejected.
+func jetbrainsFileURL(raw, projectDir, home string) (string, bool) {
+	rest, ok := strings.CutPrefix(raw, "file://")
+	if !ok || rest == "" {
+		return "", false
+	}
+	rest = strings.ReplaceAll(rest, "$PROJECT_DIR$", projectDir)
+	rest = strings.ReplaceAll(rest, "$USER_HOME$", home)
+	return filepath.Clean(filepath.FromSlash(rest)), true
+}
+
+// xmlAttr returns the value of the named attribute on el, or "".
+func xmlAttr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
 func parseJetBrainsRecentProjects(xmlPath, home string) ([]string, error) {
 	data, err := os.ReadFile(xmlPath)
 	if err != nil {
@@ -368,7 +501,7 @@ func parseJetBrainsRecentProjects(xmlPath, home string) ([]string, error) {
 
 // ── Vim ──────
 
-func collectVim(home string) ([]string, []string) {
+func collectVim(_ context.Context, home string) ([]string, []string) {
 	tabs, err := parseViminfo(filepath.Join(home, ".viminfo"), home)
 	if err != nil {
 		return nil, nil
@@ -406,8 +539,27 @@ func parseViminfo(path, home string) ([]string, error) {
 
 // ── Neovim ─────
 
-func collectNeovim(home string) ([]string, []string) {
-	out, err := exec.Command("nvim", "--headless", "--noplugin",
+// neovimBuffersExpr lists the names of the live instance's loaded file
+// buffers, one per line. Help, terminal and other special buffers are
+// skipped by their buftype.
+const neovimBuffersExpr = `join(map(filter(nvim_list_bufs(), {_, b -> nvim_buf_is_loaded(b) && getbufvar(b, '&buftype') ==# ''}), {_, b -> nvim_buf_get_name(b)}), "\n")`
+
+// collectNeovim returns the buffers open in the running Neovim when handoff
+// is run from inside it ($NVIM names its RPC socket), and otherwise falls
+// back to v:oldfiles from a headless instance, which lists recently opened
+// files across all sessions.
+func collectNeovim(ctx context.Context, home string) ([]string, []string) {
+	if socket := os.Getenv("NVIM"); socket != "" {
+		out, err := exec.CommandContext(ctx, "nvim", "--server", socket, "--remote-expr", neovimBuffersExpr).Output()
+		if err == nil {
+			return parseNeovimFileList(out, home), nil
+		}
+		if ctx.Err() != nil {
+			return nil, nil
+		}
+	}
+
+	out, err := exec.CommandContext(ctx, "nvim", "--headless", "--noplugin",
 		"-c", "echo join(v:oldfiles, \"\\n\")",
 		"-c", "qa!").Output()
 	if err != nil {
@@ -419,7 +571,12 @@ func collectNeovim(home string) ([]string, []string) {
 		}
 		return tabs, nil
 	}
+	return parseNeovimFileList(out, home), nil
+}
 
+// parseNeovimFileList splits nvim's newline-separated file list, expanding
+// "~/" and dropping blanks and duplicates.
+func parseNeovimFileList(out []byte, home string) []string {
 	seen := make(map[string]bool)
 	var files []string
 	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
@@ -435,7 +592,7 @@ func collectNeovim(home string) ([]string, []string) {
 			files = append(files, line)
 		}
 	}
-	return files, nil
+	return files
 }
 
 // ── Shared helpers ─────
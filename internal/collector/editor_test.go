@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"testing"
 	"time"
 
@@ -87,3 +88,138 @@ func TestEditorCollectorMissingStateDir(t *testing.T) {
 		t.Error("expected at least one warning for missing state dir, got none")
 	}
 }
+
+// TestJetBrainsProjectTabs verifies that open files are read from the
+// FileEditorManager component of .idea/workspace.xml, including nested
+// splits, and that the project folder is used when the file is missing.
+func TestJetBrainsProjectTabs(t *testing.T) {
+	home := t.TempDir()
+	project := filepath.Join(home, "project")
+	if err := os.MkdirAll(filepath.Join(project, ".idea"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	workspace := `<?xml version="1.0" encoding="UTF-8"?>
+<project version="4">
+  <component name="ChangeListManager">
+    <list default="true"><change afterPath="$PROJECT_DIR$/ignored.go" /></list>
+  </component>
+  <component name="FileEditorManager">
+    <splitter split-orientation="vertical">
+      <split-first>
+        <leaf>
+          <file current-in-tab="true">
+            <entry file="file://$PROJECT_DIR$/cmd/main.go">
+              <provider selected="true" editor-type-id="text-editor" />
+            </entry>
+          </file>
+        </leaf>
+      </split-first>
+      <split-second>
+        <leaf>
+          <file>
+            <entry file="file://$USER_HOME$/notes.txt" />
+          </file>
+          <file>
+            <entry file="jar://$USER_HOME$/sdk/rt.jar!/java/lang/String.class" />
+          </file>
+        </leaf>
+      </split-second>
+    </splitter>
+  </component>
+</project>`
+	if err := os.WriteFile(filepath.Join(project, ".idea", "workspace.xml"), []byte(workspace), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got := jetbrainsProjectTabs(project, home)
+	want := []string{filepath.Join(project, "cmd", "main.go"), filepath.Join(home, "notes.txt")}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("tabs = %v, want %v", got, want)
+	}
+
+	other := filepath.Join(home, "other")
+	if got := jetbrainsProjectTabs(other, home); len(got) != 1 || got[0] != other {
+		t.Errorf("fallback = %v, want [%s]", got, other)
+	}
+}
+
+// TestFilterToWorkDirSymlinks verifies that paths are matched against the
+// work dir after cleaning and symlink resolution, in either direction.
+func TestFilterToWorkDirSymlinks(t *testing.T) {
+	root := t.TempDir()
+	real := filepath.Join(root, "real")
+	if err := os.MkdirAll(filepath.Join(real, "cmd"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	link := filepath.Join(root, "link")
+	if err := os.Symlink(real, link); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	mainGo := filepath.Join(real, "cmd", "main.go")
+	if err := os.WriteFile(mainGo, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	paths := []string{
+		filepath.Join(link, "cmd", "main.go"),       // symlinked, exists
+		real + "/../real/cmd/main.go",               // unclean
+		filepath.Join(link, "deleted.go"),           // symlinked, doesn't exist
+		filepath.Join(root, "other", "main.go"),     // outside
+		filepath.Join(root, "realistic", "file.go"), // shares a name prefix
+	}
+	want := paths[:3]
+
+	for _, workDir := range []string{real, link} {
+		got := filterToWorkDir(paths, workDir)
+		if fmt.Sprint(got) != fmt.Sprint(want) {
+			t.Errorf("workDir %s: got %v, want %v", workDir, got, want)
+		}
+	}
+}
+
+// TestFilterToWorkDirMixedSeparators verifies that editor paths match the
+// work dir when one uses backslashes and the other forward slashes, as VS
+// Code's storage does on Windows.
+func TestFilterToWorkDirMixedSeparators(t *testing.T) {
+	paths := []string{
+		"c:/Users/me/proj/cmd/main.go",
+		`C:\Users\me\proj\README.md`,
+		`C:\Users\me\project\main.go`,
+	}
+	got := filterToWorkDir(paths, `C:\Users\me\proj`)
+	if want := paths[:2]; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+// TestCollectNeovimLiveBuffers verifies that with $NVIM set the open buffers
+// are queried from that instance instead of reading v:oldfiles.
+func TestCollectNeovimLiveBuffers(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("fake nvim is a shell script")
+	}
+	bin := t.TempDir()
+	script := `#!/bin/sh
+if [ "$1" = "--server" ] && [ "$2" = "/tmp/nvim.sock" ]; then
+	printf '/work/a.go\n\n~/notes.md\n/work/a.go\n'
+else
+	echo /old/file.go
+fi
+`
+	if err := os.WriteFile(filepath.Join(bin, "nvim"), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", bin)
+
+	t.Setenv("NVIM", "/tmp/nvim.sock")
+	got, _ := collectNeovim(context.Background(), "/home/u")
+	if want := []string{"/work/a.go", "/home/u/notes.md"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("live buffers: got %v, want %v", got, want)
+	}
+
+	t.Setenv("NVIM", "")
+	got, _ = collectNeovim(context.Background(), "/home/u")
+	if want := []string{"/old/file.go"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("oldfiles fallback: got %v, want %v", got, want)
+	}
+}
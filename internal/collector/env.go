@@ -0,0 +1,67 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// redactedValue replaces the value of any sensitive-looking variable.
+const redactedValue = "***"
+
+// sensitiveEnvPatterns are glob patterns (matched case-insensitively against
+// the variable name) whose values are always redacted.
+var sensitiveEnvPatterns = []string{"*TOKEN*", "*SECRET*", "*KEY*", "*PASSWORD*"}
+
+// EnvCollector captures an allow-list of environment variables, redacting any
+// whose name looks sensitive.
+type EnvCollector struct {
+	AllowList []string
+	// Lookup overrides os.LookupEnv (used in tests).
+	Lookup func(key string) (string, bool)
+}
+
+// Name implements Collector.
+func (ec *EnvCollector) Name() string { return "env" }
+
+// Collect implements Collector. Unset variables are omitted.
+func (ec *EnvCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	if len(ec.AllowList) == 0 {
+		return CollectorResult{}, nil
+	}
+	lookup := ec.Lookup
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	env := make(map[string]string)
+	for _, name := range ec.AllowList {
+		value, ok := lookup(name)
+		if !ok {
+			continue
+		}
+		if isSensitiveEnvName(name) {
+			value = redactedValue
+		}
+		env[name] = value
+	}
+	if len(env) == 0 {
+		return CollectorResult{}, nil
+	}
+	return CollectorResult{Env: env}, nil
+}
+
+// isSensitiveEnvName reports whether name matches any redaction pattern.
+// The rules apply even to explicitly allow-listed names.
+func isSensitiveEnvName(name string) bool {
+	upper := strings.ToUpper(name)
+	for _, pattern := range sensitiveEnvPatterns {
+		if matched, _ := filepath.Match(pattern, upper); matched {
+			return true
+		}
+	}
+	return false
+}
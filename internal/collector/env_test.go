@@ -0,0 +1,51 @@
+package collector
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestEnvCollectorRedaction verifies that allow-listed variables are captured,
+// unset ones are skipped, and sensitive names are always redacted.
+func TestEnvCollectorRedaction(t *testing.T) {
+	vars := map[string]string{
+		"GOFLAGS":       "-mod=mod",
+		"NODE_ENV":      "development",
+		"GITHUB_TOKEN":  "ghp_abc",
+		"aws_secret_id": "s3cr3t",
+		"API_KEY":       "k",
+		"DB_PASSWORD":   "hunter2",
+	}
+	ec := &EnvCollector{
+		AllowList: []string{"GOFLAGS", "NODE_ENV", "GITHUB_TOKEN", "aws_secret_id", "API_KEY", "DB_PASSWORD", "UNSET_VAR"},
+		Lookup: func(key string) (string, bool) {
+			v, ok := vars[key]
+			return v, ok
+		},
+	}
+
+	result, err := ec.Collect(context.Background(), &session.Session{StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+
+	want := map[string]string{
+		"GOFLAGS":       "-mod=mod",
+		"NODE_ENV":      "development",
+		"GITHUB_TOKEN":  redactedValue,
+		"aws_secret_id": redactedValue,
+		"API_KEY":       redactedValue,
+		"DB_PASSWORD":   redactedValue,
+	}
+	if len(result.Env) != len(want) {
+		t.Fatalf("expected %d env vars, got %d: %v", len(want), len(result.Env), result.Env)
+	}
+	for k, v := range want {
+		if result.Env[k] != v {
+			t.Errorf("Env[%q]: got %q, want %q", k, result.Env[k], v)
+		}
+	}
+}
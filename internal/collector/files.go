@@ -7,26 +7,61 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	pathpkg "path"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
+// DefaultMaxDiffBytes is the per-file diff size cap used by `handoff stop`.
+const DefaultMaxDiffBytes = 256 * 1024
+
+// diffTruncatedMarker is appended to diffs that exceed MaxDiffBytes.
+const diffTruncatedMarker = "... (diff truncated)"
+
 // FileCollector collects file edit events from the working directory.
 type FileCollector struct {
 	WorkDir        string
 	IgnorePatterns []string
+	// MaxDiffWorkers bounds the number of concurrent diff captures.
+	// If zero or negative, runtime.NumCPU() is used.
+	MaxDiffWorkers int
+	// MaxDiffBytes caps the size of each captured diff. Larger diffs are
+	// truncated with a marker. If zero or negative, diffs are kept whole.
+	MaxDiffBytes int
+	// DiffBase is the git ref diffs are taken against, e.g. "main" to show
+	// everything changed on the branch. Empty means HEAD.
+	DiffBase string
+	// AnnotateHunks marks each hunk header with whether git blame dates it
+	// inside the session window.
+	AnnotateHunks bool
+	// SessionOnly drops the hunks git blame dates before the session, so
+	// diffs show only the session's own changes.
+	SessionOnly bool
+	// DiffCache, if set, reuses diffs captured earlier for files that
+	// haven't changed since. Nil captures every diff afresh.
+	DiffCache *DiffCache
+	// SkipGitignore ignores .gitignore files and the global git excludes
+	// file, leaving IgnorePatterns and .handoffignore.
+	SkipGitignore bool
 }
 
+// Name implements Collector.
+func (fc *FileCollector) Name() string { return "files" }
+
 // Collect finds files modified within the session time window by walking the
 // working directory and checking each file's mtime against sess.StartTime.
 // It also merges any FileEdits already recorded in the session (from the
 // background watcher, if running), deduplicating by keeping the latest timestamp.
 func (fc *FileCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
-	patterns, err := fc.loadIgnorePatterns()
+	patterns, err := fc.loadIgnorePatterns(ctx)
 	if err != nil {
 		// Non-fatal: log as warning and continue with configured patterns only.
 		return CollectorResult{
@@ -53,11 +88,22 @@ func (fc *FileCollector) Collect(ctx context.Context, sess *session.Session) (Co
 	if workDir == "" {
 		workDir = "."
 	}
-	_ = filepath.WalkDir(workDir, func(path string, d os.DirEntry, err error) error {
+	logFrom(ctx).Debug("scanning work dir", "dir", workDir, "ignore_patterns", len(patterns), "watched_edits", len(sess.FileEdits))
+	// A negated pattern may re-include a file under an ignored directory, so
+	// only prune ignored directories when no negations are present.
+	pruneDirs := !hasNegation(patterns)
+	walkErr := filepath.WalkDir(workDir, func(path string, d os.DirEntry, err error) error {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
 		if err != nil {
 			return nil // skip unreadable entries
 		}
 		if d.IsDir() {
+			// Don't descend into ignored directories at all.
+			if pruneDirs && path != workDir && fc.isIgnored(path, patterns) {
+				return filepath.SkipDir
+			}
 			return nil
 		}
 		if fc.isIgnored(path, patterns) {
@@ -76,6 +122,9 @@ func (fc *FileCollector) Collect(ctx context.Context, sess *session.Session) (Co
 		}
 		return nil
 	})
+	if walkErr != nil {
+		return CollectorResult{}, walkErr
+	}
 
 	// Build the result slice, applying ignore patterns.
 	var edits []session.FileEdit
@@ -83,16 +132,114 @@ func (fc *FileCollector) Collect(ctx context.Context, sess *session.Session) (Co
 		if fc.isIgnored(path, patterns) {
 			continue
 		}
-		diff := captureFileDiff(path, fc.WorkDir)
-		edits = append(edits, session.FileEdit{Path: path, Timestamp: ts, Diff: diff})
+		edits = append(edits, session.FileEdit{Path: path, Timestamp: ts})
 	}
 
-	return CollectorResult{FileEdits: edits}, nil
+	base, warnings := resolveDiffBase(ctx, workDir, fc.DiffBase)
+	fc.captureDiffs(ctx, edits, base, sess.StartTime, stopTime)
+	if err := ctx.Err(); err != nil {
+		return CollectorResult{}, err
+	}
+
+	return CollectorResult{FileEdits: edits, Warnings: warnings}, nil
+}
+
+// captureDiffs fills in the Diff field of each edit using a bounded pool of
+// workers, since each capture may spawn a git subprocess. start and stop
+// bound the session for AnnotateHunks and SessionOnly.
+func (fc *FileCollector) captureDiffs(ctx context.Context, edits []session.FileEdit, base string, start, stop time.Time) {
+	workers := fc.MaxDiffWorkers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > len(edits) {
+		workers = len(edits)
+	}
+
+	var state string
+	if fc.DiffCache != nil {
+		state = repoState(ctx, fc.WorkDir, base)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if ctx.Err() != nil {
+					continue // drain remaining jobs without spawning git
+				}
+				diff := cachedFileDiff(ctx, fc.DiffCache, edits[i].Path, fc.WorkDir, base, state)
+				if fc.AnnotateHunks || fc.SessionOnly {
+					diff = scopeHunks(ctx, diff, edits[i].Path, fc.WorkDir, start, stop, fc.AnnotateHunks, fc.SessionOnly)
+				}
+				edits[i].Diff = truncateDiff(diff, fc.MaxDiffBytes)
+			}
+		}()
+	}
+	for i := range edits {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+}
+
+// truncateDiff cuts diff down to at most maxBytes (on a line boundary where
+// possible, else on a character boundary) and appends a truncation marker. maxBytes <= 0 disables the cap.
+func truncateDiff(diff string, maxBytes int) string {
+	if maxBytes <= 0 || len(diff) <= maxBytes {
+		return diff
+	}
+	cut := diff[:maxBytes]
+	if i := strings.LastIndexByte(cut, '\n'); i > 0 {
+		cut = cut[:i]
+	} else {
+		// No line break to cut at: don't split a multi-byte character.
+		for len(cut) > 0 && !utf8.RuneStart(diff[len(cut)]) {
+			cut = cut[:len(cut)-1]
+		}
+	}
+	return cut + "\n" + diffTruncatedMarker
+}
+
+// Watch defaults.
+const (
+	DefaultWatchDebounce        = 2 * time.Second
+	DefaultWatchMaxEditsPerPath = 20
+)
+
+// WatchOptions tunes how Watch records edits. Zero values use the defaults.
+type WatchOptions struct {
+	// Debounce coalesces repeated writes to the same path: an event within
+	// this window of the path's last recorded edit updates that edit's
+	// timestamp instead of adding a new one. Pending edits are saved once
+	// per window.
+	Debounce time.Duration
+	// MaxEditsPerPath caps the edits kept per path; the oldest are dropped.
+	MaxEditsPerPath int
+	// SkipGitignore is FileCollector.SkipGitignore for the watcher.
+	SkipGitignore bool
+	// OnEdit, if set, is called for each edit once it is saved to the
+	// session, oldest first.
+	OnEdit func(path string, at time.Time)
+	// OnIgnored, if set, is called for each change to a file the ignore
+	// patterns leave out.
+	OnIgnored func(path string)
 }
 
 // Watch starts a recursive fsnotify watcher on workDir and records Write/Create
-// events into the store until ctx is cancelled. This is called from `handoff start`.
-func Watch(ctx context.Context, workDir string, store session.SessionStore, ignorePatterns []string) error {
+// events into the store until ctx is cancelled. `handoff start` runs it in a
+// background process unless the watcher is turned off.
+func Watch(ctx context.Context, workDir string, store session.SessionStore, ignorePatterns []string, opts WatchOptions) error {
+	if opts.Debounce <= 0 {
+		opts.Debounce = DefaultWatchDebounce
+	}
+	if opts.MaxEditsPerPath <= 0 {
+		opts.MaxEditsPerPath = DefaultWatchMaxEditsPerPath
+	}
+
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
 		return err
@@ -113,32 +260,61 @@ func Watch(ctx context.Context, workDir string, store session.SessionStore, igno
 	}
 
 	// Load ignore patterns (gitignore + handoffignore + configured patterns).
-	fc := &FileCollector{WorkDir: workDir, IgnorePatterns: ignorePatterns}
-	patterns, _ := fc.loadIgnorePatterns()
+	fc := &FileCollector{WorkDir: workDir, IgnorePatterns: ignorePatterns, SkipGitignore: opts.SkipGitignore}
+	patterns, _ := fc.loadIgnorePatterns(ctx)
+
+	// Events are buffered in pending (latest time per path) and written to
+	// the store in one batch per debounce window.
+	pending := make(map[string]time.Time)
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+		// Update holds the store's lock, so notes and tags added by other
+		// commands in the meantime are kept.
+		err := store.Update(func(sess *session.Session) error {
+			sess.FileEdits = mergeWatchedEdits(sess.FileEdits, pending, opts.Debounce, opts.MaxEditsPerPath)
+			return nil
+		})
+		if err != nil {
+			return // keep pending; retry on the next tick
+		}
+		if opts.OnEdit != nil {
+			paths := make([]string, 0, len(pending))
+			for p := range pending {
+				paths = append(paths, p)
+			}
+			sort.Slice(paths, func(a, b int) bool { return pending[paths[a]].Before(pending[paths[b]]) })
+			for _, p := range paths {
+				opts.OnEdit(p, pending[p])
+			}
+		}
+		clear(pending)
+	}
+	ticker := time.NewTicker(opts.Debounce)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			flush()
 			return nil
 
+		case <-ticker.C:
+			flush()
+
 		case event, ok := <-watcher.Events:
 			if !ok {
 				return nil
 			}
 			if event.Has(fsnotify.Write) || event.Has(fsnotify.Create) {
 				if fc.isIgnored(event.Name, patterns) {
+					if opts.OnIgnored != nil {
+						opts.OnIgnored(event.Name)
+					}
 					continue
 				}
-				// Load current session, append the edit, and save atomically.
-				sess, err := store.Load()
-				if err != nil {
-					continue
-				}
-				sess.FileEdits = append(sess.FileEdits, session.FileEdit{
-					Path:      event.Name,
-					Timestamp: time.Now(),
-				})
-				_ = store.Save(sess) // best-effort; don't crash the watcher on save failure
+				pending[event.Name] = time.Now()
 
 				// If a new directory was created, watch it too.
 				if event.Has(fsnotify.Create) {
@@ -157,8 +333,62 @@ func Watch(ctx context.Context, workDir string, store session.SessionStore, igno
 	}
 }
 
-// isIgnored reports whether path matches any of the given glob patterns.
+// mergeWatchedEdits folds pending watcher events into edits. An event within
+// window of the latest edit for the same path replaces that edit's timestamp;
+// otherwise a new edit is appended. At most maxPerPath edits are kept per
+// path, dropping the oldest.
+func mergeWatchedEdits(edits []session.FileEdit, pending map[string]time.Time, window time.Duration, maxPerPath int) []session.FileEdit {
+	latest := make(map[string]int, len(edits)) // path -> index of its newest edit
+	for i, fe := range edits {
+		if j, ok := latest[fe.Path]; !ok || !fe.Timestamp.Before(edits[j].Timestamp) {
+			latest[fe.Path] = i
+		}
+	}
+
+	paths := make([]string, 0, len(pending))
+	for p := range pending {
+		paths = append(paths, p)
+	}
+	sort.Slice(paths, func(a, b int) bool { return pending[paths[a]].Before(pending[paths[b]]) })
+
+	for _, p := range paths {
+		ts := pending[p]
+		if i, ok := latest[p]; ok && ts.Sub(edits[i].Timestamp) <= window {
+			edits[i].Timestamp = ts
+			continue
+		}
+		edits = append(edits, session.FileEdit{Path: p, Timestamp: ts})
+		latest[p] = len(edits) - 1
+	}
+
+	// Enforce the per-path cap, keeping the newest entries.
+	seen := make(map[string]int)
+	keep := make([]bool, len(edits))
+	for i := len(edits) - 1; i >= 0; i-- {
+		seen[edits[i].Path]++
+		keep[i] = seen[edits[i].Path] <= maxPerPath
+	}
+	result := edits[:0]
+	for i, fe := range edits {
+		if keep[i] {
+			result = append(result, fe)
+		}
+	}
+	return result
+}
+
+// isIgnored reports whether path is excluded by the given gitignore-style
+// patterns. Patterns are evaluated in order and the last match wins, so a
+// pattern prefixed with "!" re-includes a path excluded by an earlier one.
+// A pattern also matches any path beneath a matching directory.
 func (fc *FileCollector) isIgnored(path string, patterns []string) bool {
+	i := fc.lastMatch(path, patterns)
+	return i >= 0 && !strings.HasPrefix(patterns[i], "!")
+}
+
+// lastMatch returns the index of the last of patterns that matches path,
+// negated or not, or -1 if none does.
+func (fc *FileCollector) lastMatch(path string, patterns []string) int {
 	// Normalise to a relative path for matching when possible.
 	rel := path
 	if fc.WorkDir != "" {
@@ -166,72 +396,394 @@ func (fc *FileCollector) isIgnored(path string, patterns []string) bool {
 			rel = r
 		}
 	}
-	base := filepath.Base(path)
 
-	for _, pattern := range patterns {
-		// Match against the base name.
-		if matched, _ := filepath.Match(pattern, base); matched {
+	match := -1
+	for i, pattern := range patterns {
+		pattern, dirOnly := strings.CutSuffix(strings.TrimPrefix(pattern, "!"), "/")
+		if pattern == "" {
+			continue
+		}
+		if matchIgnorePattern(pattern, path, rel, dirOnly) {
+			match = i
+		}
+	}
+	return match
+}
+
+// IgnoreMatch is CheckIgnore's verdict on a path.
+type IgnoreMatch struct {
+	Ignored bool
+	// Pattern is the last pattern that matches the path, as written in its
+	// file, or "" if none does. It decides Ignored: a "!" pattern
+	// re-includes the path.
+	Pattern string
+	// Source is the file Pattern was read from, or "" for IgnorePatterns,
+	// and Line its line there (for IgnorePatterns, its position in the
+	// list).
+	Source string
+	Line   int
+}
+
+// CheckIgnore loads the ignore patterns as Collect does and reports whether
+// path is ignored, and by which pattern. A relative path is taken to be
+// relative to WorkDir.
+func (fc *FileCollector) CheckIgnore(ctx context.Context, path string) (IgnoreMatch, error) {
+	rules, err := fc.loadIgnoreRules(ctx)
+	if err != nil {
+		return IgnoreMatch{}, err
+	}
+	if !filepath.IsAbs(path) && fc.WorkDir != "" {
+		path = filepath.Join(fc.WorkDir, path)
+	}
+	i := fc.lastMatch(path, rulePatterns(rules))
+	if i < 0 {
+		return IgnoreMatch{}, nil
+	}
+	r := rules[i]
+	return IgnoreMatch{
+		Ignored: !strings.HasPrefix(r.pattern, "!"),
+		Pattern: r.text,
+		Source:  r.source,
+		Line:    r.line,
+	}, nil
+}
+
+// matchIgnorePattern reports whether a single (non-negated) pattern matches
+// path, its working-directory-relative form rel, or any parent directory of rel.
+// Patterns containing "**" are matched segment-wise against rel only, and a
+// leading "/" anchors a pattern to the working directory, as in a root
+// .gitignore. With dirOnly (the pattern ended in "/") path itself only
+// matches if it is a directory.
+func matchIgnorePattern(pattern, path, rel string, dirOnly bool) bool {
+	self := func(matched bool) bool {
+		return matched && (!dirOnly || isDirectory(path))
+	}
+
+	if strings.Contains(pattern, "**") {
+		pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "/")
+		p := filepath.ToSlash(rel)
+		if p == "." || p == "" {
+			return false
+		}
+		if self(matchDoublestar(pattern, p)) {
 			return true
 		}
-		// Match against the relative path.
-		if matched, _ := filepath.Match(pattern, rel); matched {
+		for p = parentDir(p); p != ""; p = parentDir(p) {
+			if matchDoublestar(pattern, p) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if anchored, ok := strings.CutPrefix(pattern, "/"); ok {
+		// An absolute path from ignore_patterns still matches as written.
+		if matched, _ := filepath.Match(pattern, path); self(matched) {
 			return true
 		}
-		// Match against the full path.
-		if matched, _ := filepath.Match(pattern, path); matched {
+		if matched, _ := filepath.Match(anchored, rel); self(matched) {
+			return true
+		}
+		for dir := filepath.Dir(rel); dir != "." && dir != ".." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+			if matched, _ := filepath.Match(anchored, dir); matched {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Match against the base name.
+	if matched, _ := filepath.Match(pattern, filepath.Base(path)); self(matched) {
+		return true
+	}
+	// Match against the relative path.
+	if matched, _ := filepath.Match(pattern, rel); self(matched) {
+		return true
+	}
+	// Match against the full path.
+	if matched, _ := filepath.Match(pattern, path); self(matched) {
+		return true
+	}
+	// Match against each parent directory so "logs" also covers "logs/a.txt".
+	for dir := filepath.Dir(rel); dir != "." && dir != ".." && dir != string(filepath.Separator); dir = filepath.Dir(dir) {
+		if matched, _ := filepath.Match(pattern, dir); matched {
+			return true
+		}
+		if matched, _ := filepath.Match(pattern, filepath.Base(dir)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isDirectory reports whether path is a directory. Like git, a symlink to
+// one counts as a file.
+func isDirectory(path string) bool {
+	info, err := os.Lstat(path)
+	return err == nil && info.IsDir()
+}
+
+// parentDir returns the slash-separated parent of p, or "" at the top.
+func parentDir(p string) string {
+	i := strings.LastIndexByte(p, '/')
+	if i <= 0 {
+		return ""
+	}
+	return p[:i]
+}
+
+// matchDoublestar matches a slash-separated pattern against a slash-separated
+// name. A "**" segment matches zero or more whole path segments; all other
+// segments are matched with path.Match semantics.
+func matchDoublestar(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchSegments(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for len(pat) > 0 && pat[0] == "**" {
+				pat = pat[1:]
+			}
+			if len(pat) == 0 {
+				return true
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchSegments(pat, segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if matched, _ := pathpkg.Match(pat[0], segs[0]); !matched {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// hasNegation reports whether any pattern re-includes paths with "!".
+func hasNegation(patterns []string) bool {
+	for _, p := range patterns {
+		if strings.HasPrefix(p, "!") {
 			return true
 		}
 	}
 	return false
 }
 
-// loadIgnorePatterns merges the configured patterns with those from .gitignore
-// and .handoffignore files found in the working directory.
-func (fc *FileCollector) loadIgnorePatterns() ([]string, error) {
-	patterns := make([]string, len(fc.IgnorePatterns))
-	copy(patterns, fc.IgnorePatterns)
+// loadIgnorePatterns merges, from lowest to highest precedence, the
+// configured patterns, the user's global git excludes file, the .gitignore
+// files of the working directory and its subdirectories, and .handoffignore.
+// isIgnored lets the last match win, so a "!pattern" in .handoffignore
+// re-includes a gitignored file. With SkipGitignore the git files are left
+// out. Patterns from nested .gitignore files are rewritten relative to the
+// working directory so they only apply below their own directory.
+func (fc *FileCollector) loadIgnorePatterns(ctx context.Context) ([]string, error) {
+	rules, err := fc.loadIgnoreRules(ctx)
+	return rulePatterns(rules), err
+}
+
+// ignoreRule is an ignore pattern along with where it was read from.
+type ignoreRule struct {
+	pattern string // as matched, rewritten relative to the working directory
+	text    string // as written in source
+	source  string // "" for IgnorePatterns
+	line    int
+}
+
+// rulePatterns returns the patterns of rules.
+func rulePatterns(rules []ignoreRule) []string {
+	patterns := make([]string, len(rules))
+	for i, r := range rules {
+		patterns[i] = r.pattern
+	}
+	return patterns
+}
+
+// loadIgnoreRules does the work of loadIgnorePatterns, keeping the source of
+// each pattern.
+func (fc *FileCollector) loadIgnoreRules(ctx context.Context) ([]ignoreRule, error) {
+	rules := make([]ignoreRule, len(fc.IgnorePatterns))
+	for i, p := range fc.IgnorePatterns {
+		rules[i] = ignoreRule{pattern: p, text: p, line: i + 1}
+	}
+
+	if !fc.SkipGitignore {
+		if p := globalExcludesFile(ctx, fc.WorkDir); p != "" {
+			if extra, err := readPatternRules(p); err == nil {
+				rules = append(rules, extra...)
+			}
+		}
+
+		nested, err := fc.gitignoreRules(rulePatterns(rules))
+		if err != nil {
+			return rules, err
+		}
+		rules = append(rules, nested...)
+	}
+
+	extra, err := readPatternRules(filepath.Join(fc.WorkDir, ".handoffignore"))
+	if err != nil && !os.IsNotExist(err) {
+		return rules, err
+	}
+	return append(rules, extra...), nil
+}
+
+// gitignoreRules walks the working directory and returns the patterns of
+// every .gitignore found, parents before children so deeper files take
+// precedence. Directories ignored by base (or by a .gitignore above them) are
+// not descended into, unless a negation could re-include something.
+func (fc *FileCollector) gitignoreRules(base []string) ([]ignoreRule, error) {
+	root := fc.WorkDir
+	if root == "" {
+		root = "."
+	}
+	var rules []ignoreRule
+	var patterns []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return nil // skip unreadable entries
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if path != root {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			all := append(base[:len(base):len(base)], patterns...)
+			if !hasNegation(all) && fc.isIgnored(path, all) {
+				return filepath.SkipDir
+			}
+		}
 
-	for _, name := range []string{".gitignore", ".handoffignore"} {
-		p := filepath.Join(fc.WorkDir, name)
-		extra, err := readPatternFile(p)
+		lines, err := readPatternRules(filepath.Join(path, ".gitignore"))
 		if err != nil {
 			if os.IsNotExist(err) {
-				continue
+				return nil
 			}
-			return patterns, err
+			return err
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		for _, r := range lines {
+			r.pattern = scopeIgnorePattern(r.pattern, filepath.ToSlash(rel))
+			rules = append(rules, r)
+			patterns = append(patterns, r.pattern)
+		}
+		return nil
+	})
+	return rules, err
+}
+
+// scopeIgnorePattern rewrites a pattern read from the .gitignore in dir
+// (slash-separated, relative to the working directory) so it matches the
+// same paths when evaluated from the working directory. Following git, a
+// pattern with a slash before its last character is anchored to dir;
+// otherwise it matches at any depth below dir.
+func scopeIgnorePattern(pattern, dir string) string {
+	if dir == "." || dir == "" {
+		return pattern
+	}
+	negate := strings.HasPrefix(pattern, "!")
+	body := strings.TrimPrefix(pattern, "!")
+	dirOnly := strings.HasSuffix(body, "/")
+	body = strings.TrimSuffix(body, "/")
+
+	if strings.Contains(body, "/") {
+		body = dir + "/" + strings.TrimPrefix(body, "/")
+	} else {
+		body = dir + "/**/" + body
+	}
+
+	if dirOnly {
+		body += "/"
+	}
+	if negate {
+		body = "!" + body
+	}
+	return body
+}
+
+// globalExcludesFile returns the path of the user's global git excludes
+// file: core.excludesfile if set, otherwise git's default location. Returns
+// "" when git is unavailable or the file does not exist.
+func globalExcludesFile(ctx context.Context, workDir string) string {
+	cmd := exec.CommandContext(ctx, "git", "config", "--get", "core.excludesfile")
+	cmd.Dir = workDir
+	var path string
+	if out, err := cmd.Output(); err == nil {
+		path = strings.TrimSpace(string(out))
+	} else if configHome := os.Getenv("XDG_CONFIG_HOME"); configHome != "" {
+		path = filepath.Join(configHome, "git", "ignore")
+	} else if home, err := os.UserHomeDir(); err == nil {
+		path = filepath.Join(home, ".config", "git", "ignore")
+	}
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
 		}
-		patterns = append(patterns, extra...)
+		path = filepath.Join(home, rest)
+	}
+	if path == "" {
+		return ""
 	}
-	return patterns, nil
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
 }
 
-// readPatternFile reads a gitignore-style file and returns non-empty, non-comment lines.
-func readPatternFile(path string) ([]string, error) {
+// readPatternRules reads a gitignore-style file and returns its non-empty,
+// non-comment lines.
+func readPatternRules(path string) ([]ignoreRule, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		return nil, err
 	}
 	defer f.Close()
 
-	var patterns []string
+	var rules []ignoreRule
 	scanner := bufio.NewScanner(f)
-	for scanner.Scan() {
+	for n := 1; scanner.Scan(); n++ {
 		line := strings.TrimSpace(scanner.Text())
 		if line == "" || strings.HasPrefix(line, "#") {
 			continue
 		}
-		patterns = append(patterns, line)
+		rules = append(rules, ignoreRule{pattern: line, text: line, source: path, line: n})
 	}
-	return patterns, scanner.Err()
+	return rules, scanner.Err()
+}
+
+// resolveDiffBase returns the ref to diff against: base if it resolves to a
+// commit, otherwise HEAD with a warning.
+func resolveDiffBase(ctx context.Context, workDir, base string) (string, []string) {
+	if base == "" || base == "HEAD" {
+		return "HEAD", nil
+	}
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", base+"^{commit}")
+	cmd.Dir = workDir
+	if err := cmd.Run(); err != nil {
+		return "HEAD", []string{fmt.Sprintf("diff base %q does not resolve to a commit; diffing against HEAD", base)}
+	}
+	return base, nil
 }
 
 // captureFileDiff returns a unified diff for the given file.
-// It first tries git (diff HEAD, then --cached). If git is unavailable or the
+// It first tries git (diff <base>, then --cached). If git is unavailable or the
 // file is not tracked, it falls back to a pure-Go diff against an empty file,
 // effectively showing the full file content as additions.
-func captureFileDiff(path, workDir string) string {
+func captureFileDiff(ctx context.Context, path, workDir, base string) string {
 	run := func(args ...string) string {
-		cmd := exec.Command("git", args...)
+		cmd := exec.CommandContext(ctx, "git", args...)
 		cmd.Dir = workDir
 		var out bytes.Buffer
 		cmd.Stdout = &out
@@ -241,7 +793,7 @@ func captureFileDiff(path, workDir string) string {
 		return strings.TrimRight(out.String(), "\n")
 	}
 
-	if d := run("diff", "HEAD", "--", path); d != "" {
+	if d := run("diff", base, "--", path); d != "" {
 		return d
 	}
 	if d := run("diff", "--cached", "--", path); d != "" {
@@ -259,6 +811,9 @@ func fallbackDiff(path string) string {
 	if err != nil {
 		return ""
 	}
+	if bytes.IndexByte(data, 0) >= 0 {
+		return fmt.Sprintf("Binary files /dev/null and %s differ", path)
+	}
 	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "--- /dev/null\n+++ %s\n@@ -0,0 +1,%d @@\n", path, len(lines))
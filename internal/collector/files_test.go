@@ -2,8 +2,14 @@ package collector
 
 import (
 	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+	"unicode/utf8"
 
 	"github.com/fakeyudi/handoff/internal/session"
 	"pgregory.net/rapid"
@@ -146,3 +152,574 @@ func TestIgnorePatternFiltering(t *testing.T) {
 		}
 	})
 }
+
+// TestTruncateDiff verifies that oversized diffs are cut on a line boundary
+// (or, without one, a character boundary) and marked, while small diffs and
+// a disabled cap leave the diff untouched.
+func TestTruncateDiff(t *testing.T) {
+	diff := "--- a/x\n+++ b/x\n@@ -1 +1 @@\n-old\n+new"
+
+	if got := truncateDiff(diff, 0); got != diff {
+		t.Errorf("cap 0: expected diff unchanged, got %q", got)
+	}
+	if got := truncateDiff(diff, len(diff)); got != diff {
+		t.Errorf("cap == len: expected diff unchanged, got %q", got)
+	}
+
+	got := truncateDiff(diff, 20)
+	if !strings.HasSuffix(got, diffTruncatedMarker) {
+		t.Errorf("expected truncation marker, got %q", got)
+	}
+	if !strings.HasPrefix(got, "--- a/x\n+++ b/x\n") {
+		t.Errorf("expected truncation on a line boundary, got %q", got)
+	}
+
+	// A long line is cut at a character boundary, never inside one.
+	long := "+" + strings.Repeat("é", 10)
+	got = truncateDiff(long, 6)
+	if !utf8.ValidString(got) || !strings.HasPrefix(got, "+éé\n") {
+		t.Errorf("expected a cut between characters, got %q", got)
+	}
+}
+
+// TestCollectSkipsIgnoredDirectories verifies that the walk does not descend
+// into directories matching an ignore pattern.
+func TestCollectSkipsIgnoredDirectories(t *testing.T) {
+	workDir := t.TempDir()
+	for _, rel := range []string{"vendor/lib/a.go", "main.go"} {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("package x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sess := &session.Session{
+		ID:        "test-skip-dirs",
+		StartTime: time.Now().Add(-time.Minute),
+		WorkDir:   workDir,
+	}
+	fc := &FileCollector{
+		WorkDir:        workDir,
+		IgnorePatterns: []string{"vendor"},
+		MaxDiffWorkers: 2,
+	}
+	result, err := fc.Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+
+	var sawMain bool
+	for _, fe := range result.FileEdits {
+		if strings.Contains(fe.Path, "vendor") {
+			t.Errorf("file under ignored directory was collected: %s", fe.Path)
+		}
+		if filepath.Base(fe.Path) == "main.go" {
+			sawMain = true
+			if fe.Diff == "" {
+				t.Errorf("expected a diff for %s", fe.Path)
+			}
+		}
+	}
+	if !sawMain {
+		t.Errorf("expected main.go in FileEdits, got %v", result.FileEdits)
+	}
+}
+
+// TestIgnoreNegation verifies gitignore-style "!" negation: a directory-level
+// ignore can be overridden for a single file, and the last match wins.
+func TestIgnoreNegation(t *testing.T) {
+	fc := &FileCollector{WorkDir: "/repo"}
+
+	cases := []struct {
+		name     string
+		patterns []string
+		path     string
+		want     bool
+	}{
+		{"dir ignored", []string{"logs"}, "/repo/logs/debug.log", true},
+		{"file un-ignored under ignored dir", []string{"logs", "!logs/important.log"}, "/repo/logs/important.log", false},
+		{"sibling still ignored", []string{"logs", "!logs/important.log"}, "/repo/logs/debug.log", true},
+		{"glob un-ignore", []string{"*.log", "!important.log"}, "/repo/important.log", false},
+		{"re-ignored by later pattern", []string{"*.log", "!important.log", "important.log"}, "/repo/important.log", true},
+		{"negation without prior match", []string{"!main.go"}, "/repo/main.go", false},
+		{"trailing slash dir pattern", []string{"build/"}, "/repo/build/out.bin", true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := fc.isIgnored(tc.path, tc.patterns); got != tc.want {
+				t.Errorf("isIgnored(%q, %v) = %v, want %v", tc.path, tc.patterns, got, tc.want)
+			}
+		})
+	}
+}
+
+// TestIgnoreAnchoredAndDirOnly verifies two gitignore rules that need the
+// file system: a leading "/" anchors a pattern to the working directory,
+// and a trailing "/" only matches directories (and what is inside them).
+func TestIgnoreAnchoredAndDirOnly(t *testing.T) {
+	workDir := t.TempDir()
+	for _, rel := range []string{"build/out.bin", "sub/build/out.bin", "logs", "sub/logs/debug.log"} {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	fc := &FileCollector{WorkDir: workDir}
+
+	cases := []struct {
+		pattern, rel string
+		want         bool
+	}{
+		{"/build", "build", true},
+		{"/build", "build/out.bin", true},
+		{"/build", "sub/build/out.bin", false},
+		{"/build/", "build", true},
+		{"logs/", "logs", false},
+		{"logs/", "sub/logs", true},
+		{"logs/", "sub/logs/debug.log", true},
+		{"logs", "logs", true},
+	}
+	for _, tc := range cases {
+		if got := fc.isIgnored(filepath.Join(workDir, tc.rel), []string{tc.pattern}); got != tc.want {
+			t.Errorf("isIgnored(%q, %q) = %v, want %v", tc.rel, tc.pattern, got, tc.want)
+		}
+	}
+}
+
+// TestCollectHonorsHandoffignoreNegation verifies that a file re-included by a
+// negation in .handoffignore is collected even though its directory is ignored.
+func TestCollectHonorsHandoffignoreNegation(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		".handoffignore":     "logs\n!logs/important.log\n",
+		"logs/debug.log":     "noise\n",
+		"logs/important.log": "keep me\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sess := &session.Session{
+		ID:        "test-negation",
+		StartTime: time.Now().Add(-time.Minute),
+		WorkDir:   workDir,
+	}
+	fc := &FileCollector{WorkDir: workDir}
+	result, err := fc.Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, fe := range result.FileEdits {
+		rel, _ := filepath.Rel(workDir, fe.Path)
+		got[filepath.ToSlash(rel)] = true
+	}
+	if !got["logs/important.log"] {
+		t.Errorf("expected logs/important.log to be collected, got %v", got)
+	}
+	if got["logs/debug.log"] {
+		t.Errorf("expected logs/debug.log to be ignored, got %v", got)
+	}
+}
+
+// Feature: handoff, Property 3b: Recursive "**" ignore patterns
+func TestDoublestarIgnoreExcludesNestedFiles(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		ignoredDir := rapid.StringMatching(`[a-z]{1,8}`).Draw(t, "ignoredDir")
+		ext := rapid.StringMatching(`[a-z]{1,3}`).Draw(t, "ext")
+
+		// Build a path nested 0-5 directories below the ignored directory,
+		// optionally itself under 0-2 unrelated leading directories.
+		var segs []string
+		lead := rapid.IntRange(0, 2).Draw(t, "lead")
+		for i := 0; i < lead; i++ {
+			segs = append(segs, rapid.StringMatching(`[a-z]{1,8}`).Draw(t, "leadDir"))
+		}
+		segs = append(segs, ignoredDir)
+		depth := rapid.IntRange(0, 5).Draw(t, "depth")
+		for i := 0; i < depth; i++ {
+			segs = append(segs, rapid.StringMatching(`[a-z]{1,8}`).Draw(t, "subDir"))
+		}
+		segs = append(segs, rapid.StringMatching(`[a-z]{1,8}`).Draw(t, "stem")+"."+ext)
+		rel := strings.Join(segs, "/")
+
+		// Anchored forms only apply when the ignored dir is at the root.
+		patterns := []string{"**/" + ignoredDir + "/**", "**/" + ignoredDir + "/**/*." + ext}
+		if lead == 0 {
+			patterns = append(patterns, ignoredDir+"/**", ignoredDir+"/**/*."+ext)
+		}
+
+		workDir := "/work"
+		fc := &FileCollector{WorkDir: workDir}
+		for _, pattern := range patterns {
+			if !fc.isIgnored(workDir+"/"+rel, []string{pattern}) {
+				t.Fatalf("path %q was not excluded by pattern %q", rel, pattern)
+			}
+		}
+	})
+}
+
+// TestDoublestarMatching covers the segment semantics of "**".
+func TestDoublestarMatching(t *testing.T) {
+	cases := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"node_modules/**", "node_modules/a/b/c.js", true},
+		{"node_modules/**", "src/node_modules/a.js", false},
+		{"build/**/*.o", "build/a.o", true},
+		{"build/**/*.o", "build/x/y/a.o", true},
+		{"build/**/*.o", "build/x/y/a.c", false},
+		{"**/*.tmp", "a.tmp", true},
+		{"**/*.tmp", "a/b/c.tmp", true},
+		{"a/**/b", "a/b", true},
+		{"a/**/b", "a/x/y/b", true},
+		{"a/**/b", "a/x/y/c", false},
+	}
+	for _, tc := range cases {
+		if got := matchDoublestar(tc.pattern, tc.name); got != tc.want {
+			t.Errorf("matchDoublestar(%q, %q) = %v, want %v", tc.pattern, tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestFileCollectorHonorsCancellation verifies that Collect stops walking and
+// returns the context error once the context is cancelled.
+func TestFileCollectorHonorsCancellation(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("a\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	fc := &FileCollector{WorkDir: dir}
+	sess := &session.Session{StartTime: time.Now().Add(-time.Hour), WorkDir: dir}
+	if _, err := fc.Collect(ctx, sess); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+// TestMergeWatchedEdits verifies that watcher events within the debounce
+// window extend the latest edit for a path, later ones append, and the
+// per-path cap drops the oldest edits.
+func TestMergeWatchedEdits(t *testing.T) {
+	base := time.Date(2026, 2, 19, 15, 0, 0, 0, time.UTC)
+	edits := []session.FileEdit{{Path: "/repo/a.go", Timestamp: base}}
+
+	// Within the window: coalesced into the existing edit.
+	edits = mergeWatchedEdits(edits, map[string]time.Time{"/repo/a.go": base.Add(time.Second)}, 2*time.Second, 3)
+	if len(edits) != 1 || !edits[0].Timestamp.Equal(base.Add(time.Second)) {
+		t.Fatalf("expected one coalesced edit at +1s, got %+v", edits)
+	}
+
+	// Outside the window: new edits are appended until the cap is reached.
+	for i := 1; i <= 4; i++ {
+		ts := base.Add(time.Duration(i) * time.Minute)
+		edits = mergeWatchedEdits(edits, map[string]time.Time{"/repo/a.go": ts, "/repo/b.go": ts}, 2*time.Second, 3)
+	}
+	var aTimes []time.Time
+	for _, fe := range edits {
+		if fe.Path == "/repo/a.go" {
+			aTimes = append(aTimes, fe.Timestamp)
+		}
+	}
+	if len(aTimes) != 3 {
+		t.Fatalf("expected a.go capped at 3 edits, got %d: %v", len(aTimes), aTimes)
+	}
+	if !aTimes[0].Equal(base.Add(2 * time.Minute)) {
+		t.Errorf("expected the oldest a.go edits to be dropped, oldest kept is %v", aTimes[0])
+	}
+}
+
+// TestCollectHonorsNestedAndGlobalGitignore verifies that a subdirectory's
+// .gitignore only applies below that directory, that its anchored patterns
+// are relative to it, and that the global excludes file is read.
+func TestCollectHonorsNestedAndGlobalGitignore(t *testing.T) {
+	workDir := t.TempDir()
+	globalIgnore := filepath.Join(t.TempDir(), "global-ignore")
+	if err := os.WriteFile(globalIgnore, []byte("*.swp\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	gitConfig := filepath.Join(t.TempDir(), "gitconfig")
+	if err := os.WriteFile(gitConfig, []byte("[core]\n\texcludesfile = "+filepath.ToSlash(globalIgnore)+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GIT_CONFIG_GLOBAL", gitConfig)
+
+	files := map[string]string{
+		"sub/.gitignore":     "*.log\n/out\n",
+		"root.log":           "kept: outside sub\n",
+		"sub/a.log":          "ignored\n",
+		"sub/deep/b.log":     "ignored\n",
+		"sub/out/x.txt":      "ignored: anchored to sub\n",
+		"sub/deep/out/y.txt": "kept: /out is anchored\n",
+		"main.go.swp":        "ignored: global excludes\n",
+		"main.go":            "kept\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sess := &session.Session{StartTime: time.Now().Add(-time.Minute), WorkDir: workDir}
+	fc := &FileCollector{WorkDir: workDir}
+	result, err := fc.Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+
+	got := make(map[string]bool)
+	for _, fe := range result.FileEdits {
+		rel, _ := filepath.Rel(workDir, fe.Path)
+		got[filepath.ToSlash(rel)] = true
+	}
+	for _, want := range []string{"root.log", "sub/deep/out/y.txt", "main.go", "sub/.gitignore"} {
+		if !got[want] {
+			t.Errorf("expected %s to be collected, got %v", want, got)
+		}
+	}
+	for _, ignored := range []string{"sub/a.log", "sub/deep/b.log", "sub/out/x.txt", "main.go.swp"} {
+		if got[ignored] {
+			t.Errorf("expected %s to be ignored, got %v", ignored, got)
+		}
+	}
+}
+
+// TestHandoffignoreOverridesGitignore verifies that .handoffignore is
+// evaluated after .gitignore, so its negations re-include gitignored files,
+// and that SkipGitignore drops .gitignore entirely.
+func TestHandoffignoreOverridesGitignore(t *testing.T) {
+	workDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":     "*.log\nbuild/\n",
+		".handoffignore": "!keep.log\n*.tmp\n",
+		"debug.log":      "gitignored\n",
+		"keep.log":       "re-included\n",
+		"build/out.txt":  "gitignored\n",
+		"scratch.tmp":    "handoffignored\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, skip := range []bool{false, true} {
+		sess := &session.Session{StartTime: time.Now().Add(-time.Minute), WorkDir: workDir}
+		fc := &FileCollector{WorkDir: workDir, SkipGitignore: skip}
+		result, err := fc.Collect(context.Background(), sess)
+		if err != nil {
+			t.Fatalf("Collect returned unexpected error: %v", err)
+		}
+		got := make(map[string]bool)
+		for _, fe := range result.FileEdits {
+			rel, _ := filepath.Rel(workDir, fe.Path)
+			got[filepath.ToSlash(rel)] = true
+		}
+		if !got["keep.log"] || got["scratch.tmp"] {
+			t.Errorf("SkipGitignore=%v: .handoffignore not applied, got %v", skip, got)
+		}
+		if got["debug.log"] != skip || got["build/out.txt"] != skip {
+			t.Errorf("SkipGitignore=%v: gitignored files collected = %v, want %v; got %v",
+				skip, got["debug.log"] && got["build/out.txt"], skip, got)
+		}
+	}
+}
+
+// TestCheckIgnore verifies that CheckIgnore names the file, line and
+// pattern deciding each path, as written in a nested .gitignore, including
+// a negation that re-includes the path.
+func TestCheckIgnore(t *testing.T) {
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	workDir := t.TempDir()
+	files := map[string]string{
+		".gitignore":     "# build output\n*.log\n",
+		"sub/.gitignore": "/out\n",
+		".handoffignore": "!keep.log\n",
+	}
+	for rel, content := range files {
+		p := filepath.Join(workDir, rel)
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fc := &FileCollector{WorkDir: workDir, IgnorePatterns: []string{"node_modules", "*.tmp"}}
+	tests := []struct {
+		path string
+		want IgnoreMatch
+	}{
+		{"debug.log", IgnoreMatch{Ignored: true, Pattern: "*.log", Source: filepath.Join(workDir, ".gitignore"), Line: 2}},
+		{"keep.log", IgnoreMatch{Pattern: "!keep.log", Source: filepath.Join(workDir, ".handoffignore"), Line: 1}},
+		{"sub/out/x.txt", IgnoreMatch{Ignored: true, Pattern: "/out", Source: filepath.Join(workDir, "sub", ".gitignore"), Line: 1}},
+		{filepath.Join(workDir, "scratch.tmp"), IgnoreMatch{Ignored: true, Pattern: "*.tmp", Line: 2}},
+		{"out/x.txt", IgnoreMatch{}},
+	}
+	for _, tt := range tests {
+		got, err := fc.CheckIgnore(context.Background(), tt.path)
+		if err != nil {
+			t.Fatalf("CheckIgnore(%q): %v", tt.path, err)
+		}
+		if got != tt.want {
+			t.Errorf("CheckIgnore(%q) = %+v, want %+v", tt.path, got, tt.want)
+		}
+	}
+}
+
+// TestScopeIgnorePattern verifies how nested .gitignore patterns are
+// rewritten relative to the working directory.
+func TestScopeIgnorePattern(t *testing.T) {
+	tests := []struct{ pattern, dir, want string }{
+		{"*.log", ".", "*.log"},
+		{"*.log", "sub", "sub/**/*.log"},
+		{"/out", "sub", "sub/out"},
+		{"docs/*.md", "a/b", "a/b/docs/*.md"},
+		{"build/", "sub", "sub/**/build/"},
+		{"!keep.log", "sub", "!sub/**/keep.log"},
+	}
+	for _, tt := range tests {
+		if got := scopeIgnorePattern(tt.pattern, tt.dir); got != tt.want {
+			t.Errorf("scopeIgnorePattern(%q, %q) = %q, want %q", tt.pattern, tt.dir, got, tt.want)
+		}
+	}
+}
+
+// TestCaptureFileDiffAgainstBase verifies that diffs can be taken against an
+// earlier commit, and that an unknown base falls back to HEAD with a warning.
+func TestCaptureFileDiffAgainstBase(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	path := filepath.Join(dir, "main.go")
+	write := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write("package main\n")
+	git("add", ".")
+	git("commit", "-q", "-m", "base")
+	base := git("rev-parse", "HEAD")
+	write("package main\n\nfunc committed() {}\n")
+	git("commit", "-q", "-am", "on branch")
+
+	ref, warnings := resolveDiffBase(context.Background(), dir, base)
+	if ref != base || len(warnings) != 0 {
+		t.Fatalf("resolveDiffBase(%s) = %q, %v", base, ref, warnings)
+	}
+	if d := captureFileDiff(context.Background(), path, dir, ref); !strings.Contains(d, "+func committed() {}") {
+		t.Errorf("expected committed change in diff against base, got:\n%s", d)
+	}
+
+	ref, warnings = resolveDiffBase(context.Background(), dir, "no-such-branch")
+	if ref != "HEAD" || len(warnings) != 1 || !strings.Contains(warnings[0], "no-such-branch") {
+		t.Errorf("expected HEAD fallback with a warning, got %q, %v", ref, warnings)
+	}
+}
+
+// TestWatchCallbacks verifies that Watch reports saved edits through OnEdit
+// and ignored changes through OnIgnored.
+func TestWatchCallbacks(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	workDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(workDir, ".handoffignore"), []byte("*.log\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Save(&session.Session{ID: "watch", StartTime: time.Now(), WorkDir: workDir}); err != nil {
+		t.Fatal(err)
+	}
+
+	edits := make(chan string, 10)
+	ignored := make(chan string, 10)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Watch(ctx, workDir, store, nil, WatchOptions{
+			Debounce:      50 * time.Millisecond,
+			SkipGitignore: true,
+			OnEdit:        func(path string, _ time.Time) { edits <- path },
+			OnIgnored:     func(path string) { ignored <- path },
+		})
+	}()
+	time.Sleep(200 * time.Millisecond) // let the watcher subscribe
+
+	code, logFile := filepath.Join(workDir, "main.go"), filepath.Join(workDir, "debug.log")
+	if err := os.WriteFile(code, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(logFile, []byte("noise\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		ch   chan string
+		want string
+	}{{edits, code}, {ignored, logFile}} {
+		select {
+		case got := <-c.ch:
+			if got != c.want {
+				t.Errorf("got %q, want %q", got, c.want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no callback for %s", c.want)
+		}
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Watch: %v", err)
+	}
+
+	s, err := store.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.FileEdits) != 1 || s.FileEdits[0].Path != code {
+		t.Errorf("expected one saved edit for main.go, got %+v", s.FileEdits)
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"os/exec"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -11,24 +13,64 @@ import (
 	"github.com/fakeyudi/handoff/internal/session"
 )
 
-// GitRunner executes a git command and returns its output.
-// This abstraction allows mocking in tests.
-type GitRunner func(workDir string, args ...string) (string, error)
+// GitRunner executes a git command and returns its output. The command must
+// be abandoned when ctx is cancelled. This abstraction allows mocking in tests.
+type GitRunner func(ctx context.Context, workDir string, args ...string) (string, error)
 
 // GitCollector collects git repository state.
 type GitCollector struct {
 	WorkDir string
 	Runner  GitRunner // if nil, uses the real git subprocess
+	// LogLimit caps the commits kept in RecentLog; the rest are counted in
+	// RecentLogOmitted. Zero or negative means no cap.
+	LogLimit int
+	// LogAuthor keeps only commits whose author matches (git log --author).
+	LogAuthor string
+	// LogNoMerges leaves merge commits out of RecentLog.
+	LogNoMerges bool
 }
 
 // defaultGitRunner runs git as a real subprocess.
-func defaultGitRunner(workDir string, args ...string) (string, error) {
-	cmd := exec.Command("git", args...)
+func defaultGitRunner(ctx context.Context, workDir string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
 	cmd.Dir = workDir
 	out, err := cmd.Output()
 	return string(out), err
 }
 
+// ErrNotGitRepository is returned by Head when the work dir is not inside a
+// git repository.
+var ErrNotGitRepository = errors.New("not a git repository")
+
+// Name implements Collector.
+func (g *GitCollector) Name() string { return "git" }
+
+// Head returns the checked-out branch ("HEAD" when detached) and commit of
+// the repository at g.WorkDir.
+func (g *GitCollector) Head(ctx context.Context) (branch, commit string, err error) {
+	runner := g.Runner
+	if runner == nil {
+		runner = defaultGitRunner
+	}
+	return head(ctx, runner, g.WorkDir)
+}
+
+func head(ctx context.Context, runner GitRunner, workDir string) (branch, commit string, err error) {
+	// The branch lookup also serves as the "is this a git repo?" check.
+	branch, err = runner(ctx, workDir, "rev-parse", "--abbrev-ref", "HEAD")
+	if err != nil {
+		if isExitCode128(err) {
+			return "", "", ErrNotGitRepository
+		}
+		return "", "", err
+	}
+	commit, err = runner(ctx, workDir, "rev-parse", "HEAD")
+	if err != nil {
+		return "", "", err
+	}
+	return strings.TrimSpace(branch), strings.TrimSpace(commit), nil
+}
+
 // Collect implements Collector. It runs several git commands to capture
 // the current repository state and populates a GitInfo in the result.
 // If the working directory is not a git repository (exit code 128), it
@@ -43,47 +85,89 @@ func (g *GitCollector) Collect(ctx context.Context, sess *session.Session) (Coll
 	if workDir == "" {
 		workDir = sess.WorkDir
 	}
+	logFrom(ctx).Debug("reading git repository", "dir", workDir)
 
-	// Determine branch — also serves as the "is this a git repo?" check.
-	branch, err := runner(workDir, "rev-parse", "--abbrev-ref", "HEAD")
+	branch, headCommit, err := head(ctx, runner, workDir)
 	if err != nil {
-		if isExitCode128(err) {
+		if errors.Is(err, ErrNotGitRepository) {
 			return CollectorResult{
-				Warnings: []string{"not a git repository"},
+				Warnings: []string{err.Error()},
 			}, nil
 		}
 		return CollectorResult{}, err
 	}
 
-	headCommit, err := runner(workDir, "rev-parse", "HEAD")
+	diff, err := runner(ctx, workDir, "diff")
 	if err != nil {
 		return CollectorResult{}, err
 	}
 
-	diff, err := runner(workDir, "diff")
+	stagedDiff, err := runner(ctx, workDir, "diff", "--staged")
 	if err != nil {
 		return CollectorResult{}, err
 	}
 
-	stagedDiff, err := runner(workDir, "diff", "--staged")
+	untrackedOut, err := runner(ctx, workDir, "ls-files", "--others", "--exclude-standard")
 	if err != nil {
 		return CollectorResult{}, err
 	}
+	untracked := parseLogLines(untrackedOut)
+	var untrackedDiffs []string
+	for _, rel := range untracked {
+		if d := fallbackDiff(filepath.Join(workDir, rel)); d != "" {
+			untrackedDiffs = append(untrackedDiffs, truncateDiff(d, DefaultMaxDiffBytes))
+		}
+	}
 
-	since := sess.StartTime.Format(time.RFC3339)
-	logOut, err := runner(workDir, "log", "--oneline", "--since="+since)
+	stashOut, err := runner(ctx, workDir, "stash", "list")
+	if err != nil {
+		return CollectorResult{}, err
+	}
+
+	// Ask for one commit more than the cap to tell whether it was hit.
+	filters := []string{"--since=" + sess.StartTime.Format(time.RFC3339)}
+	if g.LogNoMerges {
+		filters = append(filters, "--no-merges")
+	}
+	if g.LogAuthor != "" {
+		filters = append(filters, "--author="+g.LogAuthor)
+	}
+	logArgs := append([]string{"log", "--oneline"}, filters...)
+	if g.LogLimit > 0 {
+		logArgs = append(logArgs, "-n", strconv.Itoa(g.LogLimit+1))
+	}
+	logOut, err := runner(ctx, workDir, logArgs...)
 	if err != nil {
 		return CollectorResult{}, err
 	}
 
 	recentLog := parseLogLines(logOut)
+	omitted := 0
+	if g.LogLimit > 0 && len(recentLog) > g.LogLimit {
+		recentLog = recentLog[:g.LogLimit]
+		countOut, err := runner(ctx, workDir, append(append([]string{"rev-list", "--count"}, filters...), "HEAD")...)
+		if err != nil {
+			return CollectorResult{}, err
+		}
+		if total, err := strconv.Atoi(strings.TrimSpace(countOut)); err == nil && total > g.LogLimit {
+			omitted = total - g.LogLimit
+		}
+	}
 
 	info := &bundle.GitInfo{
-		Branch:     strings.TrimSpace(branch),
-		HeadCommit: strings.TrimSpace(headCommit),
-		Diff:       diff,
-		StagedDiff: stagedDiff,
-		RecentLog:  recentLog,
+		Branch:           branch,
+		HeadCommit:       headCommit,
+		Diff:             diff,
+		StagedDiff:       stagedDiff,
+		RecentLog:        recentLog,
+		RecentLogOmitted: omitted,
+	}
+	if len(untracked) > 0 {
+		info.UntrackedFiles = untracked
+		info.UntrackedDiff = strings.Join(untrackedDiffs, "\n")
+	}
+	if stashes := parseLogLines(stashOut); len(stashes) > 0 {
+		info.Stashes = stashes
 	}
 
 	return CollectorResult{GitInfo: info}, nil
@@ -2,7 +2,9 @@ package collector
 
 import (
 	"context"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -26,7 +28,7 @@ func TestGitCollectorNonGitRepo(t *testing.T) {
 		t.Fatal("expected exit code 128 error, got nil")
 	}
 
-	mockRunner := func(workDir string, args ...string) (string, error) {
+	mockRunner := func(ctx context.Context, workDir string, args ...string) (string, error) {
 		return "", exitErr
 	}
 
@@ -65,14 +67,16 @@ func TestGitCollectorNonGitRepo(t *testing.T) {
 // populates all GitInfo fields correctly.
 func TestGitCollectorSuccess(t *testing.T) {
 	responses := map[string]string{
-		"rev-parse --abbrev-ref HEAD": "main\n",
-		"rev-parse HEAD":              "abc123def456\n",
-		"diff":                        "diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n",
-		"diff --staged":               "diff --git a/bar.go b/bar.go\n--- a/bar.go\n+++ b/bar.go\n",
-		"log --oneline":               "abc123 first commit\ndef456 second commit\n",
+		"rev-parse --abbrev-ref HEAD":          "main\n",
+		"rev-parse HEAD":                       "abc123def456\n",
+		"diff":                                 "diff --git a/foo.go b/foo.go\n--- a/foo.go\n+++ b/foo.go\n",
+		"diff --staged":                        "diff --git a/bar.go b/bar.go\n--- a/bar.go\n+++ b/bar.go\n",
+		"log --oneline":                        "abc123 first commit\ndef456 second commit\n",
+		"ls-files --others --exclude-standard": "",
+		"stash list":                           "",
 	}
 
-	mockRunner := func(workDir string, args ...string) (string, error) {
+	mockRunner := func(ctx context.Context, workDir string, args ...string) (string, error) {
 		key := strings.Join(args, " ")
 		// log command includes a --since=... flag; match by prefix
 		if strings.HasPrefix(key, "log --oneline") {
@@ -129,4 +133,102 @@ func TestGitCollectorSuccess(t *testing.T) {
 	if gi.RecentLog[1] != "def456 second commit" {
 		t.Errorf("expected second log entry %q, got %q", "def456 second commit", gi.RecentLog[1])
 	}
+	if gi.Stashes != nil {
+		t.Errorf("expected no stashes, got %v", gi.Stashes)
+	}
+}
+
+// TestGitCollectorStashes verifies that `git stash list` entries are
+// recorded in order.
+func TestGitCollectorStashes(t *testing.T) {
+	mockRunner := func(ctx context.Context, workDir string, args ...string) (string, error) {
+		if strings.Join(args, " ") == "stash list" {
+			return "stash@{0}: WIP on main: 1a2b3c4 fix login\nstash@{1}: On main: try the new parser\n", nil
+		}
+		return "", nil
+	}
+	gc := &GitCollector{WorkDir: "/repo", Runner: mockRunner}
+
+	result, err := gc.Collect(context.Background(), &session.Session{StartTime: time.Now(), WorkDir: "/repo"})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	want := []string{"stash@{0}: WIP on main: 1a2b3c4 fix login", "stash@{1}: On main: try the new parser"}
+	if got := result.GitInfo.Stashes; len(got) != 2 || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("Stashes = %q, want %q", got, want)
+	}
+}
+
+// TestGitCollectorLogLimit verifies that the log filters are passed to git
+// and that commits beyond LogLimit are counted rather than listed.
+func TestGitCollectorLogLimit(t *testing.T) {
+	var logKey, countKey string
+	mockRunner := func(ctx context.Context, workDir string, args ...string) (string, error) {
+		key := strings.Join(args, " ")
+		switch {
+		case strings.HasPrefix(key, "log --oneline"):
+			logKey = key
+			return "c3 third\nc2 second\nc1 first\n", nil
+		case strings.HasPrefix(key, "rev-list --count"):
+			countKey = key
+			return "7\n", nil
+		case key == "rev-parse --abbrev-ref HEAD":
+			return "main\n", nil
+		}
+		return "", nil
+	}
+
+	gc := &GitCollector{Runner: mockRunner, LogLimit: 2, LogAuthor: "alice", LogNoMerges: true}
+	result, err := gc.Collect(context.Background(), &session.Session{StartTime: time.Now().Add(-time.Hour), WorkDir: "/repo"})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+
+	for _, want := range []string{"--no-merges", "--author=alice", "-n 3"} {
+		if !strings.Contains(logKey, want) {
+			t.Errorf("git log args %q: missing %q", logKey, want)
+		}
+	}
+	if !strings.Contains(countKey, "--author=alice") || !strings.HasSuffix(countKey, " HEAD") {
+		t.Errorf("unexpected rev-list args %q", countKey)
+	}
+	gi := result.GitInfo
+	if len(gi.RecentLog) != 2 || gi.RecentLog[1] != "c2 second" {
+		t.Errorf("RecentLog = %v, want the first 2 commits", gi.RecentLog)
+	}
+	if gi.RecentLogOmitted != 5 {
+		t.Errorf("RecentLogOmitted = %d, want 5", gi.RecentLogOmitted)
+	}
+}
+
+// TestGitCollectorUntrackedFiles verifies that files reported by
+// `git ls-files --others` are listed and shown as additions.
+func TestGitCollectorUntrackedFiles(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mockRunner := func(ctx context.Context, workDir string, args ...string) (string, error) {
+		if strings.Join(args, " ") == "ls-files --others --exclude-standard" {
+			return "new.go\n", nil
+		}
+		return "", nil
+	}
+	gc := &GitCollector{WorkDir: dir, Runner: mockRunner}
+
+	result, err := gc.Collect(context.Background(), &session.Session{StartTime: time.Now(), WorkDir: dir})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	gi := result.GitInfo
+	if gi == nil {
+		t.Fatal("expected GitInfo to be populated, got nil")
+	}
+	if len(gi.UntrackedFiles) != 1 || gi.UntrackedFiles[0] != "new.go" {
+		t.Errorf("UntrackedFiles = %v, want [new.go]", gi.UntrackedFiles)
+	}
+	if !strings.Contains(gi.UntrackedDiff, "--- /dev/null") || !strings.Contains(gi.UntrackedDiff, "+package main") {
+		t.Errorf("unexpected UntrackedDiff:\n%s", gi.UntrackedDiff)
+	}
 }
@@ -0,0 +1,182 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hunk header annotations added by scopeHunks.
+const (
+	hunkDuringSession = "[during session]"
+	hunkBeforeSession = "[before session]"
+)
+
+// hunkHeader matches a unified diff hunk header and captures the start and
+// (optional) length of the new-file range.
+var hunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,(\d+))? @@`)
+
+// hunkScope classifies a hunk by when its added lines were written.
+type hunkScope int
+
+const (
+	scopeUnknown hunkScope = iota // no added lines, or no blame data
+	scopeDuring
+	scopeBefore
+)
+
+// diffHunk is one hunk of a unified diff: its header line and body lines.
+type diffHunk struct {
+	header string
+	lines  []string
+	added  []int // new-file line numbers of the + lines
+}
+
+// scopeHunks uses git blame to tell which hunks of diff (a diff of path's
+// working copy) were written during [start, stop]. With annotate it marks
+// each hunk header with the result, and with sessionOnly it drops hunks
+// written before the session. Lines not committed yet count as written during the session,
+// since git can't date them. When blame isn't available, e.g. for untracked
+// files, diff is returned unchanged.
+func scopeHunks(ctx context.Context, diff, path, workDir string, start, stop time.Time, annotate, sessionOnly bool) string {
+	preamble, hunks := splitHunks(diff)
+	if len(hunks) == 0 {
+		return diff
+	}
+
+	var ranges []string
+	for _, h := range hunks {
+		if len(h.added) > 0 {
+			ranges = append(ranges, "-L", fmt.Sprintf("%d,%d", h.added[0], h.added[len(h.added)-1]))
+		}
+	}
+	if len(ranges) == 0 {
+		return diff
+	}
+	args := append([]string{"blame", "--porcelain"}, ranges...)
+	cmd := exec.CommandContext(ctx, "git", append(args, "--", path)...)
+	cmd.Dir = workDir
+	out, err := cmd.Output()
+	if err != nil {
+		return diff
+	}
+	written := parseBlameTimes(out)
+
+	var sb strings.Builder
+	sb.WriteString(preamble)
+	kept := 0
+	for _, h := range hunks {
+		scope := classifyHunk(h, written, start, stop)
+		if sessionOnly && scope == scopeBefore {
+			continue
+		}
+		kept++
+		header := h.header
+		if annotate {
+			switch scope {
+			case scopeDuring:
+				header += " " + hunkDuringSession
+			case scopeBefore:
+				header += " " + hunkBeforeSession
+			}
+		}
+		sb.WriteString(header + "\n")
+		for _, l := range h.lines {
+			sb.WriteString(l + "\n")
+		}
+	}
+	if kept == 0 {
+		return "" // nothing in the diff was written during the session
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// splitHunks splits a unified diff into the file header lines before the
+// first hunk and the hunks themselves.
+func splitHunks(diff string) (string, []diffHunk) {
+	var preamble strings.Builder
+	var hunks []diffHunk
+	next := 0 // new-file line number of the next context or + line
+	for _, line := range strings.Split(diff, "\n") {
+		if m := hunkHeader.FindStringSubmatch(line); m != nil {
+			next, _ = strconv.Atoi(m[1])
+			hunks = append(hunks, diffHunk{header: line})
+			continue
+		}
+		if len(hunks) == 0 {
+			preamble.WriteString(line + "\n")
+			continue
+		}
+		h := &hunks[len(hunks)-1]
+		h.lines = append(h.lines, line)
+		switch {
+		case strings.HasPrefix(line, "+"):
+			h.added = append(h.added, next)
+			next++
+		case strings.HasPrefix(line, " "):
+			next++
+		}
+	}
+	return preamble.String(), hunks
+}
+
+// classifyHunk decides when a hunk was written from the blame times of its
+// added lines. A zero time means the line isn't committed yet.
+func classifyHunk(h diffHunk, written map[int]time.Time, start, stop time.Time) hunkScope {
+	scope := scopeUnknown
+	for _, n := range h.added {
+		t, ok := written[n]
+		if !ok {
+			continue
+		}
+		if t.IsZero() || (!t.Before(start) && !t.After(stop)) {
+			return scopeDuring
+		}
+		scope = scopeBefore
+	}
+	return scope
+}
+
+// parseBlameTimes maps final line numbers to the author time of the commit
+// that last changed them, from `git blame --porcelain` output. Lines that
+// are not committed yet map to the zero time.
+func parseBlameTimes(out []byte) map[int]time.Time {
+	const uncommitted = "0000000000000000000000000000000000000000"
+	commitTimes := make(map[string]time.Time)
+	lineCommits := make(map[int]string)
+
+	var current string
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "\t") {
+			continue // line content
+		}
+		fields := strings.Fields(line)
+		if len(fields) >= 3 && len(fields[0]) == 40 {
+			if n, err := strconv.Atoi(fields[2]); err == nil {
+				current = fields[0]
+				lineCommits[n] = current
+				continue
+			}
+		}
+		if len(fields) == 2 && fields[0] == "author-time" && current != uncommitted {
+			if epoch, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				commitTimes[current] = time.Unix(epoch, 0)
+			}
+		}
+	}
+
+	written := make(map[int]time.Time, len(lineCommits))
+	for n, sha := range lineCommits {
+		written[n] = commitTimes[sha] // zero for uncommitted lines
+	}
+	return written
+}
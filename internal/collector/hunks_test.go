@@ -0,0 +1,94 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestScopeHunks verifies that hunks committed before the session are
+// marked as such (and dropped with sessionOnly), while uncommitted hunks
+// count as written during the session.
+func TestScopeHunks(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	git := func(env []string, args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), env...)
+		out, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+		return strings.TrimSpace(string(out))
+	}
+	path := filepath.Join(dir, "notes.txt")
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	write := func() {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := []string{"GIT_AUTHOR_DATE=2020-01-01T00:00:00Z", "GIT_COMMITTER_DATE=2020-01-01T00:00:00Z"}
+
+	git(nil, "init", "-q")
+	write()
+	git(old, "add", ".")
+	git(old, "commit", "-q", "-m", "base")
+	base := git(nil, "rev-parse", "HEAD")
+	lines[1] = "line 2, changed before the session"
+	write()
+	git(old, "commit", "-q", "-am", "earlier work")
+	lines[17] = "line 18, changed during the session"
+	write()
+
+	start := time.Now().Add(-time.Hour)
+	stop := time.Now().Add(time.Minute)
+	diff := captureFileDiff(context.Background(), path, dir, base)
+
+	annotated := scopeHunks(context.Background(), diff, path, dir, start, stop, true, false)
+	// git may add function context after the range, before our marker.
+	headers := map[string]string{}
+	for _, line := range strings.Split(annotated, "\n") {
+		if strings.HasPrefix(line, "@@ ") {
+			headers[strings.Fields(line)[2]] = line
+		}
+	}
+	if h := headers["+1,5"]; !strings.HasSuffix(h, " "+hunkBeforeSession) {
+		t.Errorf("expected the committed hunk to be marked before the session, got %q", h)
+	}
+	if h := headers["+15,6"]; !strings.HasSuffix(h, " "+hunkDuringSession) {
+		t.Errorf("expected the uncommitted hunk to be marked during the session, got %q", h)
+	}
+
+	scoped := scopeHunks(context.Background(), diff, path, dir, start, stop, false, true)
+	if strings.Contains(scoped, "changed before the session") {
+		t.Errorf("expected the earlier hunk to be dropped:\n%s", scoped)
+	}
+	if !strings.Contains(scoped, "+line 18, changed during the session") || !strings.HasPrefix(scoped, "diff --git") {
+		t.Errorf("expected the file header and session hunk to remain:\n%s", scoped)
+	}
+
+	// Untracked files have no blame data: the diff is left alone.
+	untracked := filepath.Join(dir, "new.txt")
+	if err := os.WriteFile(untracked, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	fallback := fallbackDiff(untracked)
+	if got := scopeHunks(context.Background(), fallback, untracked, dir, start, stop, true, true); got != fallback {
+		t.Errorf("expected the diff unchanged without blame data, got:\n%s", got)
+	}
+}
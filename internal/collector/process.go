@@ -0,0 +1,184 @@
+package collector
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// ProcessCollector lists running processes that belong to the session's
+// working directory — e.g. a dev server or test watcher the next person
+// needs to know about. A process matches when its current directory is
+// under WorkDir or its command line mentions WorkDir.
+type ProcessCollector struct {
+	WorkDir string
+	// ProcRoot overrides the /proc mount point on Linux (used in tests).
+	ProcRoot string
+}
+
+// processInfo is a single running process.
+type processInfo struct {
+	pid     int
+	cwd     string
+	cmdline string
+}
+
+// Name implements Collector.
+func (pc *ProcessCollector) Name() string { return "processes" }
+
+// Collect implements Collector. Enumeration failures are reported as
+// warnings, never as errors.
+func (pc *ProcessCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	workDir := pc.WorkDir
+	if workDir == "" {
+		workDir = sess.WorkDir
+	}
+	if workDir == "" {
+		return CollectorResult{}, nil
+	}
+
+	var procs []processInfo
+	var err error
+	switch {
+	case pc.ProcRoot != "" || runtime.GOOS == "linux":
+		root := pc.ProcRoot
+		if root == "" {
+			root = "/proc"
+		}
+		procs, err = listProcProcesses(root)
+	case runtime.GOOS == "darwin":
+		procs, err = listDarwinProcesses(ctx)
+	default:
+		return CollectorResult{
+			Warnings: []string{fmt.Sprintf("running process collection is not supported on %s", runtime.GOOS)},
+		}, nil
+	}
+	if err != nil {
+		return CollectorResult{
+			Warnings: []string{fmt.Sprintf("running processes unavailable: %v", err)},
+		}, nil
+	}
+
+	self := os.Getpid()
+	var matched []processInfo
+	for _, p := range procs {
+		if p.pid == self || p.cmdline == "" {
+			continue
+		}
+		if isWithinDir(p.cwd, workDir) || strings.Contains(p.cmdline, workDir) {
+			matched = append(matched, p)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].pid < matched[j].pid })
+
+	result := make([]string, 0, len(matched))
+	for _, p := range matched {
+		result = append(result, fmt.Sprintf("%d  %s", p.pid, p.cmdline))
+	}
+	return CollectorResult{Processes: result}, nil
+}
+
+// listProcProcesses enumerates processes from a Linux-style /proc tree.
+// Processes whose details can't be read (typically other users') are skipped.
+func listProcProcesses(root string) ([]processInfo, error) {
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, err
+	}
+
+	var procs []processInfo
+	for _, e := range entries {
+		pid, err := strconv.Atoi(e.Name())
+		if err != nil {
+			continue
+		}
+		dir := filepath.Join(root, e.Name())
+		raw, err := os.ReadFile(filepath.Join(dir, "cmdline"))
+		if err != nil {
+			continue
+		}
+		cmdline := strings.TrimSpace(strings.ReplaceAll(string(raw), "\x00", " "))
+		cwd, _ := os.Readlink(filepath.Join(dir, "cwd"))
+		procs = append(procs, processInfo{pid: pid, cwd: cwd, cmdline: cmdline})
+	}
+	return procs, nil
+}
+
+// listDarwinProcesses enumerates processes on macOS using ps for command
+// lines and lsof for working directories.
+func listDarwinProcesses(ctx context.Context) ([]processInfo, error) {
+	out, err := exec.CommandContext(ctx, "ps", "-axo", "pid=,command=").Output()
+	if err != nil {
+		return nil, fmt.Errorf("ps: %w", err)
+	}
+
+	byPID := make(map[int]*processInfo)
+	var procs []*processInfo
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		fields := strings.SplitN(strings.TrimSpace(scanner.Text()), " ", 2)
+		if len(fields) != 2 {
+			continue
+		}
+		pid, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		p := &processInfo{pid: pid, cmdline: strings.TrimSpace(fields[1])}
+		byPID[pid] = p
+		procs = append(procs, p)
+	}
+
+	// lsof output is "p<pid>" followed by "n<path>" for each cwd descriptor.
+	// It exits non-zero when some processes can't be inspected, so parse
+	// whatever it managed to print.
+	lsofOut, _ := exec.CommandContext(ctx, "lsof", "-d", "cwd", "-Fpn").Output()
+	var current *processInfo
+	scanner = bufio.NewScanner(bytes.NewReader(lsofOut))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+		switch line[0] {
+		case 'p':
+			pid, _ := strconv.Atoi(line[1:])
+			current = byPID[pid]
+		case 'n':
+			if current != nil {
+				current.cwd = line[1:]
+			}
+		}
+	}
+
+	result := make([]processInfo, 0, len(procs))
+	for _, p := range procs {
+		result = append(result, *p)
+	}
+	return result, nil
+}
+
+// isWithinDir reports whether path is dir or lies beneath it.
+func isWithinDir(path, dir string) bool {
+	if path == "" || dir == "" {
+		return false
+	}
+	if path == dir {
+		return true
+	}
+	prefix := dir
+	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
+		prefix += string(filepath.Separator)
+	}
+	return strings.HasPrefix(path, prefix)
+}
@@ -0,0 +1,79 @@
+package collector
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestProcessCollectorWithFakeProc verifies that processes are matched by
+// working directory or command line, using a fake /proc tree.
+func TestProcessCollectorWithFakeProc(t *testing.T) {
+	procRoot := t.TempDir()
+	workDir := t.TempDir()
+	otherDir := t.TempDir()
+
+	fakeProcs := []struct {
+		pid     string
+		cwd     string
+		cmdline string
+	}{
+		{"100", workDir, "npm\x00run\x00dev\x00"},
+		{"200", otherDir, "go\x00test\x00" + workDir + "/...\x00"},
+		{"300", otherDir, "vim\x00notes.txt\x00"},
+		{"400", filepath.Join(workDir, "sub"), ""}, // kernel thread: no cmdline
+	}
+	for _, p := range fakeProcs {
+		dir := filepath.Join(procRoot, p.pid)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "cmdline"), []byte(p.cmdline), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Symlink(p.cwd, filepath.Join(dir, "cwd")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Non-numeric entries are ignored.
+	if err := os.MkdirAll(filepath.Join(procRoot, "self"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	pc := &ProcessCollector{WorkDir: workDir, ProcRoot: procRoot}
+	sess := &session.Session{ID: "test", StartTime: time.Now(), WorkDir: workDir}
+	result, err := pc.Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	if len(result.Warnings) != 0 {
+		t.Errorf("expected no warnings, got %v", result.Warnings)
+	}
+	if len(result.Processes) != 2 {
+		t.Fatalf("expected 2 processes, got %d: %v", len(result.Processes), result.Processes)
+	}
+	if !strings.HasPrefix(result.Processes[0], "100") || !strings.Contains(result.Processes[0], "npm run dev") {
+		t.Errorf("unexpected first process: %q", result.Processes[0])
+	}
+	if !strings.HasPrefix(result.Processes[1], "200") {
+		t.Errorf("unexpected second process: %q", result.Processes[1])
+	}
+}
+
+// TestProcessCollectorUnreadableProc verifies that a missing /proc yields a
+// warning rather than an error.
+func TestProcessCollectorUnreadableProc(t *testing.T) {
+	pc := &ProcessCollector{WorkDir: "/repo", ProcRoot: filepath.Join(t.TempDir(), "missing")}
+	result, err := pc.Collect(context.Background(), &session.Session{WorkDir: "/repo"})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	if len(result.Warnings) == 0 {
+		t.Error("expected a warning for unreadable /proc, got none")
+	}
+}
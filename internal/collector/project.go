@@ -0,0 +1,107 @@
+package collector
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// readmeScanLimit bounds how much of a README is searched for a heading.
+const readmeScanLimit = 64 << 10
+
+// ProjectName names the project in workDir, so a bundle from a generic path
+// like ~/code/tmp says what it is about. It tries, in order, the first
+// heading of README.md, the "name" in package.json and the module path in
+// go.mod, and falls back to the directory's base name.
+func ProjectName(workDir string) string {
+	for _, find := range []func(string) string{readmeHeading, packageJSONName, goModulePath} {
+		if name := find(workDir); name != "" {
+			return name
+		}
+	}
+	return filepath.Base(workDir)
+}
+
+// readmeHeading returns the text of the first Markdown heading in the
+// README.md (matched case-insensitively) in dir, skipping code blocks.
+func readmeHeading(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, e := range entries {
+		if e.Type().IsRegular() && strings.EqualFold(e.Name(), "README.md") {
+			f, err := os.Open(filepath.Join(dir, e.Name()))
+			if err != nil {
+				return ""
+			}
+			defer f.Close()
+			return firstHeading(io.LimitReader(f, readmeScanLimit))
+		}
+	}
+	return ""
+}
+
+// firstHeading returns the text of the first ATX heading ("# Title") in r,
+// without its markers.
+func firstHeading(r io.Reader) string {
+	inFence := false
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "```") || strings.HasPrefix(line, "~~~") {
+			inFence = !inFence
+			continue
+		}
+		if inFence || !strings.HasPrefix(line, "#") {
+			continue
+		}
+		text := strings.TrimLeft(line, "#")
+		if len(line)-len(text) > 6 || text != "" && text[0] != ' ' && text[0] != '\t' {
+			continue // "#hashtag" or too many #s: not a heading
+		}
+		if text = strings.TrimSpace(strings.TrimRight(text, "# \t")); text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// packageJSONName returns the "name" field of dir's package.json.
+func packageJSONName(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Name string `json:"name"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return strings.TrimSpace(pkg.Name)
+}
+
+// goModulePath returns the module path declared in dir's go.mod.
+func goModulePath(dir string) string {
+	data, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	if err != nil {
+		return ""
+	}
+	for line := range strings.Lines(string(data)) {
+		line, _, _ = strings.Cut(line, "//")
+		fields := strings.Fields(line)
+		if len(fields) != 2 || fields[0] != "module" {
+			continue
+		}
+		if path, err := strconv.Unquote(fields[1]); err == nil {
+			return path
+		}
+		return fields[1]
+	}
+	return ""
+}
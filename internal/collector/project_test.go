@@ -0,0 +1,57 @@
+package collector
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestProjectName verifies the order ProjectName looks for a name in: the
+// README's first heading, package.json, go.mod, then the directory name.
+func TestProjectName(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{
+			name: "readme heading",
+			files: map[string]string{
+				"readme.md":    "<img src=logo.png>\n\n```sh\n# not a heading\n```\n#hashtag\n## Invoice Service ##\n# Later\n",
+				"package.json": `{"name": "invoice-web"}`,
+			},
+			want: "Invoice Service",
+		},
+		{
+			name: "package.json",
+			files: map[string]string{
+				"README.md":    "no headings here\n",
+				"package.json": `{"name": "invoice-web"}`,
+				"go.mod":       "module example.com/invoice\n",
+			},
+			want: "invoice-web",
+		},
+		{
+			name:  "go.mod",
+			files: map[string]string{"go.mod": "// comment\nmodule \"example.com/invoice\" // trailing\n\ngo 1.25\n"},
+			want:  "example.com/invoice",
+		},
+		{name: "base name", want: "scratch"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "scratch")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			for name, content := range tc.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := ProjectName(dir); got != tc.want {
+				t.Errorf("ProjectName = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
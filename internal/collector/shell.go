@@ -8,6 +8,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strconv"
 	"strings"
 	"time"
@@ -28,58 +29,45 @@ type ShellCollector struct {
 	// UsePluginLog instructs the collector to read from the handoff command log
 	// (written by the shell plugin) instead of the shell history file.
 	UsePluginLog bool
+	// KeepPluginLog leaves the command log in place after reading it, for when
+	// other sessions are still active and need their share of it.
+	KeepPluginLog bool
 }
 
+// Name implements Collector.
+func (sc *ShellCollector) Name() string { return "shell" }
+
 // Collect reads shell commands for the session window.
 // If UsePluginLog is true and the log has entries, it uses those (accurate
 // timestamps, no buffering issues). Otherwise falls back to the history file.
 func (sc *ShellCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
 	if sc.UsePluginLog {
-		cmds, err := shellpkg.ReadCommandLog()
+		// Consume the log unless other sessions still need it. Taking it
+		// (rather than reading, then truncating) can't drop a command the
+		// plugin appends in between.
+		readLog := shellpkg.TakeCommandLog
+		if sc.KeepPluginLog {
+			readLog = shellpkg.ReadCommandLog
+		}
+		cmds, err := readLog()
+		logFrom(ctx).Debug("read shell plugin log", "commands", len(cmds), "err", err)
 		if err == nil && len(cmds) > 0 {
 			// Filter to session window and strip noise.
 			var warnings []string
 			filtered := filterCommands(cmds, sess.StartTime, sess.StopTime, 0, &warnings)
-			// Truncate the log now that we've consumed it.
-			_ = shellpkg.TruncateCommandLog()
+			filtered = dropPausedCommands(filtered, sess)
 			return CollectorResult{Commands: filtered, Warnings: warnings}, nil
 		}
 		// Log empty or unreadable — fall through to history file with a hint.
 	}
 
-	return sc.collectFromHistory(sess)
+	return sc.collectFromHistory(ctx, sess)
 }
 
 // collectFromHistory reads the shell history file as a fallback.
-func (sc *ShellCollector) collectFromHistory(sess *session.Session) (CollectorResult, error) {
-	shell := filepath.Base(os.Getenv("SHELL"))
-
-	var parser HistoryParser
-	var defaultPath string
-	switch shell {
-	case "bash":
-		parser = parseBashHistory
-		home, _ := os.UserHomeDir()
-		defaultPath = filepath.Join(home, ".bash_history")
-	case "zsh":
-		parser = parseZshHistory
-		home, _ := os.UserHomeDir()
-		defaultPath = filepath.Join(home, ".zsh_history")
-	case "fish":
-		parser = parseFishHistory
-		home, _ := os.UserHomeDir()
-		defaultPath = filepath.Join(home, ".local", "share", "fish", "fish_history")
-	default:
-		// Unknown shell — try bash as a best-effort fallback.
-		parser = parseBashHistory
-		home, _ := os.UserHomeDir()
-		defaultPath = filepath.Join(home, ".bash_history")
-	}
-
-	histPath := sc.HistoryPath
-	if histPath == "" {
-		histPath = defaultPath
-	}
+func (sc *ShellCollector) collectFromHistory(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	parser, histPath := historySource(sc.HistoryPath)
+	logFrom(ctx).Debug("reading shell history", "shell", shellpkg.Detect(), "path", histPath)
 
 	f, err := os.Open(histPath)
 	if err != nil {
@@ -97,6 +85,7 @@ func (sc *ShellCollector) collectFromHistory(sess *session.Session) (CollectorRe
 	// Filter to [StartTime, StopTime] if StopTime is set.
 	var warnings []string
 	filtered := filterCommands(commands, sess.StartTime, sess.StopTime, sess.HistoryBaselineCount, &warnings)
+	filtered = dropPausedCommands(filtered, sess)
 
 	return CollectorResult{
 		Commands: filtered,
@@ -104,6 +93,11 @@ func (sc *ShellCollector) collectFromHistory(sess *session.Session) (CollectorRe
 	}, nil
 }
 
+// HistoryFlushHint tells users how to make their shell write history as they
+// go rather than on exit.
+const HistoryFlushHint = "run 'handoff setup --append-history', or add 'setopt INC_APPEND_HISTORY' to ~/.zshrc (zsh) or " +
+	"'PROMPT_COMMAND=\"history -a\"' to ~/.bashrc (bash), to capture commands in real time"
+
 // maxNoTimestampCommands is the maximum number of recent commands to include
 // when the shell history has no timestamps.
 const maxNoTimestampCommands = 50
@@ -120,7 +114,7 @@ func isHandoffNoise(raw string) bool {
 	if len(fields) < 2 {
 		return false
 	}
-	bin := filepath.Base(fields[0])
+	bin := strings.TrimSuffix(filepath.Base(fields[0]), ".exe")
 	if bin != "handoff" {
 		return false
 	}
@@ -172,9 +166,7 @@ func filterCommands(commands []bundle.Command, start time.Time, stop *time.Time,
 		} else if baselineCount >= len(fresh) {
 			// History count at stop == baseline: shell hasn't flushed new commands yet.
 			// This happens when INC_APPEND_HISTORY / HISTFILE sharing is not enabled.
-			*warnings = append(*warnings, "shell history was not flushed during session — "+
-				"add 'setopt INC_APPEND_HISTORY' to ~/.zshrc (zsh) or "+
-				"'PROMPT_COMMAND=\"history -a\"' to ~/.bashrc (bash) to capture commands in real time")
+			*warnings = append(*warnings, "shell history was not flushed during session — "+HistoryFlushHint)
 			fresh = nil
 		}
 		if len(fresh) > maxNoTimestampCommands {
@@ -189,47 +181,152 @@ func filterCommands(commands []bundle.Command, start time.Time, stop *time.Time,
 	return result
 }
 
+// dropPausedCommands removes commands whose timestamp falls inside one of the
+// session's paused intervals. Commands without timestamps are kept, since
+// there is no way to tell when they ran.
+func dropPausedCommands(commands []bundle.Command, sess *session.Session) []bundle.Command {
+	if len(sess.PausedIntervals) == 0 {
+		return commands
+	}
+	var result []bundle.Command
+	for _, cmd := range commands {
+		if !cmd.Timestamp.IsZero() && sess.InPause(cmd.Timestamp) {
+			continue
+		}
+		result = append(result, cmd)
+	}
+	return result
+}
+
 // SnapshotHistoryBaseline returns the total number of commands currently in
 // the shell history file. Stored at session start so the stop collector can
 // skip that many entries and only show commands typed during the session.
 func SnapshotHistoryBaseline(historyPathOverride string) int {
-	shell := filepath.Base(os.Getenv("SHELL"))
+	status, err := InspectHistory(historyPathOverride)
+	if err != nil {
+		return 0
+	}
+	return status.Commands
+}
 
-	var parser HistoryParser
-	var defaultPath string
+// HistoryStatus describes the shell history file the collector reads.
+type HistoryStatus struct {
+	Path        string
+	Commands    int // entries in the file
+	Timestamped int // entries that record when they ran
+}
+
+// InspectHistory parses the history file the collector would read for the
+// current shell. It fails if the file can't be read or parsed.
+func InspectHistory(historyPathOverride string) (HistoryStatus, error) {
+	parser, histPath := historySource(historyPathOverride)
+	status := HistoryStatus{Path: histPath}
+
+	f, err := os.Open(histPath)
+	if err != nil {
+		return status, err
+	}
+	defer f.Close()
+
+	commands, err := parser(f, time.Time{})
+	if err != nil {
+		return status, err
+	}
+	status.Commands = len(commands)
+	for _, c := range commands {
+		if !c.Timestamp.IsZero() {
+			status.Timestamped++
+		}
+	}
+	return status, nil
+}
+
+// historySource returns the parser and history file for the current $SHELL.
+// The file is, in order of precedence: override (the shell_history_path
+// config), $HISTFILE for bash and zsh, then the shell's default location.
+// Both the start-time baseline and the stop-time read use it, so they always
+// look at the same file.
+func historySource(override string) (HistoryParser, string) {
 	home, _ := os.UserHomeDir()
 
-	switch shell {
+	var parser HistoryParser
+	var defaultPath string
+	honorHistfile := false
+	switch shellpkg.Detect() {
 	case "bash":
 		parser = parseBashHistory
 		defaultPath = filepath.Join(home, ".bash_history")
+		honorHistfile = true
 	case "zsh":
 		parser = parseZshHistory
 		defaultPath = filepath.Join(home, ".zsh_history")
+		honorHistfile = true
 	case "fish":
 		parser = parseFishHistory
-		defaultPath = filepath.Join(home, ".local", "share", "fish", "fish_history")
+		defaultPath = fishHistoryPath(home)
+	case "powershell":
+		parser = parsePowerShellHistory
+		defaultPath = powershellHistoryPath(home)
 	default:
+		if runtime.GOOS == "windows" {
+			// cmd.exe keeps no history; PowerShell is the likely shell.
+			parser = parsePowerShellHistory
+			defaultPath = powershellHistoryPath(home)
+			break
+		}
+		// Unknown shell — try bash as a best-effort fallback.
 		parser = parseBashHistory
 		defaultPath = filepath.Join(home, ".bash_history")
 	}
 
-	histPath := historyPathOverride
-	if histPath == "" {
-		histPath = defaultPath
+	switch {
+	case override != "":
+		return parser, override
+	case honorHistfile && os.Getenv("HISTFILE") != "":
+		return parser, os.Getenv("HISTFILE")
+	default:
+		return parser, defaultPath
 	}
+}
 
-	f, err := os.Open(histPath)
-	if err != nil {
-		return 0
+// fishHistoryPath returns fish's history file, which lives under
+// $XDG_DATA_HOME (by default ~/.local/share) and is named for the history
+// session: fish_history, or <name>_history when $fish_history names
+// another session.
+func fishHistoryPath(home string) string {
+	dir := os.Getenv("XDG_DATA_HOME")
+	if dir == "" {
+		dir = filepath.Join(home, ".local", "share")
 	}
-	defer f.Close()
+	name := os.Getenv("fish_history")
+	if name == "" {
+		name = "fish"
+	}
+	return filepath.Join(dir, "fish", name+"_history")
+}
 
-	commands, err := parser(f, time.Time{})
-	if err != nil {
-		return 0
+// powershellHistoryPath returns PSReadLine's history file. The default
+// location is used when it exists; otherwise PowerShell is asked for
+// (Get-PSReadLineOption).HistorySavePath, which honors a custom path set in
+// the user's profile.
+func powershellHistoryPath(home string) string {
+	path := filepath.Join(home, ".local", "share", "powershell", "PSReadLine", "ConsoleHost_history.txt")
+	if runtime.GOOS == "windows" {
+		path = filepath.Join(os.Getenv("APPDATA"), "Microsoft", "Windows", "PowerShell", "PSReadLine", "ConsoleHost_history.txt")
 	}
-	return len(commands)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, exe := range []string{"pwsh", "powershell"} {
+		out, err := exec.CommandContext(ctx, exe, "-NoLogo", "-Command", "(Get-PSReadLineOption).HistorySavePath").Output()
+		if saved := strings.TrimSpace(string(out)); err == nil && saved != "" {
+			return saved
+		}
+	}
+	return path
 }
 
 // flushShellHistory attempts to flush the current shell's in-memory history to
@@ -252,46 +349,81 @@ func flushShellHistory(shell string) {
 	// Ignore errors — this is best-effort.
 	_ = cmd.Run()
 }
+
+// parseBashHistory parses ~/.bash_history.
 //
 // Format:
-//   - Plain: one command per line (no timestamps).
-//   - With HISTTIMEFORMAT: a `#<epoch>` line precedes each command.
+//   - Plain: one command per line (no timestamps). A line ending in an
+//     unescaped backslash continues on the next line; the backslash is
+//     dropped, as with PowerShell's backtick.
+//   - With HISTTIMEFORMAT: a `#<epoch>` line precedes each command, and
+//     every line up to the next marker belongs to it, so multiline commands
+//     saved with `shopt -s lithist` stay whole.
+//
+// Multiline commands keep their line breaks in Command.Raw.
 func parseBashHistory(r io.Reader, since time.Time) ([]bundle.Command, error) {
 	var commands []bundle.Command
 	scanner := bufio.NewScanner(r)
 
-	var pendingTime time.Time
+	// Lines of the command being read, and its timestamp.
+	var lines []string
+	var ts time.Time
+	timestamped := false // a #<epoch> marker has been seen
+
+	flush := func() {
+		if raw := strings.TrimRight(strings.Join(lines, "\n"), "\n"); raw != "" {
+			commands = append(commands, bundle.Command{Raw: raw, Timestamp: ts})
+		}
+		lines, ts = nil, time.Time{}
+	}
 
 	for scanner.Scan() {
 		line := scanner.Text()
 
-		if strings.HasPrefix(line, "#") {
-			// Possible timestamp line: #<epoch>
-			epochStr := strings.TrimPrefix(line, "#")
-			if epoch, err := strconv.ParseInt(epochStr, 10, 64); err == nil {
-				pendingTime = time.Unix(epoch, 0)
-				continue
-			}
-			// Otherwise it's a comment — skip.
-			pendingTime = time.Time{}
+		if epoch, ok := bashTimestamp(line); ok {
+			flush()
+			timestamped = true
+			ts = time.Unix(epoch, 0)
 			continue
 		}
-
-		if line == "" {
-			pendingTime = time.Time{}
+		if timestamped {
+			lines = append(lines, line)
 			continue
 		}
 
-		commands = append(commands, bundle.Command{
-			Raw:       line,
-			Timestamp: pendingTime,
-		})
-		pendingTime = time.Time{}
+		// Plain history. Blank lines and comments only count inside a
+		// continued command.
+		if len(lines) == 0 && (line == "" || strings.HasPrefix(line, "#")) {
+			continue
+		}
+		if continuesLine(line) {
+			lines = append(lines, strings.TrimSuffix(line, `\`))
+			continue
+		}
+		lines = append(lines, line)
+		flush()
 	}
+	flush()
 
 	return commands, scanner.Err()
 }
 
+// bashTimestamp parses a `#<epoch>` history timestamp line.
+func bashTimestamp(line string) (int64, bool) {
+	if !strings.HasPrefix(line, "#") {
+		return 0, false
+	}
+	epoch, err := strconv.ParseInt(line[1:], 10, 64)
+	return epoch, err == nil
+}
+
+// continuesLine reports whether line ends in an unescaped backslash, which
+// makes the shell read the next line as part of the same command.
+func continuesLine(line string) bool {
+	n := len(line) - len(strings.TrimRight(line, `\`))
+	return n%2 == 1
+}
+
 // parseZshHistory parses ~/.zsh_history.
 //
 // Extended format: `: <epoch>:<elapsed>;<command>`
@@ -340,54 +472,105 @@ func parseZshHistory(r io.Reader, since time.Time) ([]bundle.Command, error) {
 	return commands, scanner.Err()
 }
 
-// parseFishHistory parses ~/.local/share/fish/fish_history.
+// parseFishHistory parses fish's fish_history.
 //
-// YAML-like format:
+// YAML-like format, written by fish itself rather than a YAML library:
 //
 //	- cmd: <command>
 //	  when: <epoch>
+//	  paths:
+//	    - <path the command mentioned>
+//
+// Backslashes and newlines in values are escaped as \\ and \n. Keys are
+// recognized by their indentation, so a path or command that happens to
+// contain "when:" is never read as a timestamp.
 func parseFishHistory(r io.Reader, since time.Time) ([]bundle.Command, error) {
 	var commands []bundle.Command
 	scanner := bufio.NewScanner(r)
 
-	var currentCmd string
-	var currentTime time.Time
-	inEntry := false
+	var current *bundle.Command
+	flush := func() {
+		if current != nil && current.Raw != "" {
+			commands = append(commands, *current)
+		}
+		current = nil
+	}
 
 	for scanner.Scan() {
-		line := scanner.Text()
+		line := strings.TrimSuffix(scanner.Text(), "\r")
 
-		if strings.HasPrefix(line, "- cmd: ") {
-			// Save previous entry if any.
-			if inEntry && currentCmd != "" {
-				commands = append(commands, bundle.Command{
-					Raw:       currentCmd,
-					Timestamp: currentTime,
-				})
-			}
-			currentCmd = strings.TrimPrefix(line, "- cmd: ")
-			currentTime = time.Time{}
-			inEntry = true
+		if rest, ok := strings.CutPrefix(line, "- cmd:"); ok {
+			flush()
+			current = &bundle.Command{Raw: unescapeFish(strings.TrimPrefix(rest, " "))}
+			continue
+		}
+		// Entry keys are indented two spaces; deeper lines are items of
+		// the paths list.
+		if current == nil || !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") {
 			continue
 		}
+		key, value, _ := strings.Cut(line[2:], ":")
+		if key == "when" {
+			if epoch, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil {
+				current.Timestamp = time.Unix(epoch, 0)
+			}
+		}
+	}
+	flush()
 
-		if inEntry && strings.HasPrefix(line, "  when: ") {
-			epochStr := strings.TrimPrefix(line, "  when: ")
-			if epoch, err := strconv.ParseInt(strings.TrimSpace(epochStr), 10, 64); err == nil {
-				currentTime = time.Unix(epoch, 0)
+	return commands, scanner.Err()
+}
+
+// unescapeFish undoes fish's escaping of history values: "\\" stands for
+// a backslash and "\n" for a newline.
+func unescapeFish(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	var sb strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			switch s[i+1] {
+			case '\\':
+				sb.WriteByte('\\')
+				i++
+				continue
+			case 'n':
+				sb.WriteByte('\n')
+				i++
+				continue
 			}
-			continue
 		}
+		sb.WriteByte(s[i])
+	}
+	return sb.String()
+}
 
-		// Any other line (e.g. "  paths:") — just skip, stay in entry.
+// parsePowerShellHistory parses PSReadLine's ConsoleHost_history.txt.
+//
+// Plain format: one command per line, no timestamps. A line ending in a
+// backtick continues the command on the next line.
+func parsePowerShellHistory(r io.Reader, since time.Time) ([]bundle.Command, error) {
+	var commands []bundle.Command
+	scanner := bufio.NewScanner(r)
+
+	var current []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if cont, ok := strings.CutSuffix(line, "`"); ok {
+			current = append(current, cont)
+			continue
+		}
+		current = append(current, line)
+		if cmd := strings.Join(current, "\n"); strings.TrimSpace(cmd) != "" {
+			commands = append(commands, bundle.Command{Raw: cmd})
+		}
+		current = nil
 	}
 
-	// Flush the last entry.
-	if inEntry && currentCmd != "" {
-		commands = append(commands, bundle.Command{
-			Raw:       currentCmd,
-			Timestamp: currentTime,
-		})
+	// Flush a continuation left open at the end of the file.
+	if cmd := strings.Join(current, "\n"); strings.TrimSpace(cmd) != "" {
+		commands = append(commands, bundle.Command{Raw: cmd})
 	}
 
 	return commands, scanner.Err()
@@ -4,13 +4,18 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
 	"pgregory.net/rapid"
 
+	"github.com/fakeyudi/handoff/internal/bundle"
 	"github.com/fakeyudi/handoff/internal/session"
+	shellpkg "github.com/fakeyudi/handoff/internal/shell"
 )
 
 // Feature: handoff, Property 4: Shell history time-window filtering
@@ -230,6 +235,123 @@ func TestPerShellHistoryParsing(t *testing.T) {
 	})
 }
 
+// TestPowerShellHistoryParsing verifies that PSReadLine history is read as
+// one command per line, without timestamps, and that backtick continuations
+// are joined into a single command.
+func TestPowerShellHistoryParsing(t *testing.T) {
+	rapid.Check(t, func(t *rapid.T) {
+		cmds := rapid.SliceOfN(rapid.StringMatching(`[A-Za-z][A-Za-z0-9 -]{0,20}`), 1, 10).Draw(t, "cmds")
+
+		var sb strings.Builder
+		for _, c := range cmds {
+			sb.WriteString(c + "\r\n")
+		}
+
+		parsed, err := parsePowerShellHistory(strings.NewReader(sb.String()), time.Time{})
+		if err != nil {
+			t.Fatalf("parsePowerShellHistory returned unexpected error: %v", err)
+		}
+		if len(parsed) != len(cmds) {
+			t.Fatalf("expected %d commands, got %d", len(cmds), len(parsed))
+		}
+		for i, c := range cmds {
+			if parsed[i].Raw != c {
+				t.Fatalf("entry %d: expected command %q, got %q", i, c, parsed[i].Raw)
+			}
+			if !parsed[i].Timestamp.IsZero() {
+				t.Fatalf("entry %d: expected no timestamp, got %v", i, parsed[i].Timestamp)
+			}
+		}
+	})
+
+	history := "Get-ChildItem\nGet-Process |`\n  Sort-Object CPU\n\ngo test ./...\n"
+	parsed, err := parsePowerShellHistory(strings.NewReader(history), time.Time{})
+	if err != nil {
+		t.Fatalf("parsePowerShellHistory returned unexpected error: %v", err)
+	}
+	want := []string{"Get-ChildItem", "Get-Process |\n  Sort-Object CPU", "go test ./..."}
+	if len(parsed) != len(want) {
+		t.Fatalf("expected %d commands, got %d: %v", len(want), len(parsed), parsed)
+	}
+	for i, w := range want {
+		if parsed[i].Raw != w {
+			t.Errorf("entry %d: expected command %q, got %q", i, w, parsed[i].Raw)
+		}
+	}
+}
+
+// TestBashMultilineHistory verifies that multiline commands come back as one
+// command each: everything between timestamp markers, and backslash
+// continuations in plain history.
+func TestBashMultilineHistory(t *testing.T) {
+	// Lines never start with "#" or end in a backslash or space.
+	line := rapid.StringMatching(`[a-z][a-z0-9 |]{0,15}[a-z0-9]`)
+
+	t.Run("timestamped", func(t *testing.T) {
+		rapid.Check(t, func(t *rapid.T) {
+			n := rapid.IntRange(1, 8).Draw(t, "n")
+			cmds := make([]string, n)
+			epochs := make([]int64, n)
+			var sb strings.Builder
+			for i := range n {
+				lines := rapid.SliceOfN(line, 1, 4).Draw(t, fmt.Sprintf("lines%d", i))
+				cmds[i] = strings.Join(lines, "\n")
+				epochs[i] = rapid.Int64Range(1_000_000_000, 1_700_000_000).Draw(t, fmt.Sprintf("epoch%d", i))
+				fmt.Fprintf(&sb, "#%d\n%s\n", epochs[i], cmds[i])
+			}
+
+			parsed, err := parseBashHistory(strings.NewReader(sb.String()), time.Time{})
+			if err != nil {
+				t.Fatalf("parseBashHistory returned unexpected error: %v", err)
+			}
+			if len(parsed) != n {
+				t.Fatalf("expected %d commands, got %d: %+v", n, len(parsed), parsed)
+			}
+			for i := range n {
+				if parsed[i].Raw != cmds[i] || parsed[i].Timestamp.Unix() != epochs[i] {
+					t.Fatalf("entry %d: expected %q at %d, got %q at %d",
+						i, cmds[i], epochs[i], parsed[i].Raw, parsed[i].Timestamp.Unix())
+				}
+			}
+		})
+	})
+
+	t.Run("backslash continuation", func(t *testing.T) {
+		rapid.Check(t, func(t *rapid.T) {
+			n := rapid.IntRange(1, 8).Draw(t, "n")
+			cmds := make([]string, n)
+			var sb strings.Builder
+			for i := range n {
+				lines := rapid.SliceOfN(line, 1, 4).Draw(t, fmt.Sprintf("lines%d", i))
+				cmds[i] = strings.Join(lines, " \n")
+				sb.WriteString(strings.Join(lines, " \\\n") + "\n")
+			}
+
+			parsed, err := parseBashHistory(strings.NewReader(sb.String()), time.Time{})
+			if err != nil {
+				t.Fatalf("parseBashHistory returned unexpected error: %v", err)
+			}
+			if len(parsed) != n {
+				t.Fatalf("expected %d commands, got %d: %+v", n, len(parsed), parsed)
+			}
+			for i := range n {
+				if parsed[i].Raw != cmds[i] {
+					t.Fatalf("entry %d: expected %q, got %q", i, cmds[i], parsed[i].Raw)
+				}
+			}
+		})
+	})
+
+	// An escaped backslash at the end of a line doesn't continue it.
+	parsed, err := parseBashHistory(strings.NewReader("echo a\\\\\necho b\n"), time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed) != 2 || parsed[0].Raw != `echo a\\` {
+		t.Errorf("escaped backslash: got %+v", parsed)
+	}
+}
+
 // TestShellDetection tests that the correct parser is selected based on the SHELL env var,
 // verified indirectly by writing known history content and asserting correct parsing.
 func TestShellDetection(t *testing.T) {
@@ -289,6 +411,29 @@ func TestShellDetection(t *testing.T) {
 		}
 	})
 
+	t.Run("SHELL=pwsh selects PowerShell parser", func(t *testing.T) {
+		t.Setenv("SHELL", "/usr/bin/pwsh")
+
+		f, err := os.CreateTemp(t.TempDir(), "ConsoleHost_history.txt")
+		if err != nil {
+			t.Fatalf("failed to create temp file: %v", err)
+		}
+		fmt.Fprintf(f, "hello-pwsh\n")
+		f.Close()
+
+		sc := &ShellCollector{HistoryPath: f.Name()}
+		result, err := sc.Collect(context.Background(), sess)
+		if err != nil {
+			t.Fatalf("Collect returned error: %v", err)
+		}
+		if len(result.Commands) != 1 {
+			t.Fatalf("expected 1 command, got %d", len(result.Commands))
+		}
+		if result.Commands[0].Raw != "hello-pwsh" {
+			t.Errorf("expected command %q, got %q", "hello-pwsh", result.Commands[0].Raw)
+		}
+	})
+
 	t.Run("SHELL=fish selects fish parser", func(t *testing.T) {
 		t.Setenv("SHELL", "fish")
 
@@ -314,6 +459,65 @@ func TestShellDetection(t *testing.T) {
 	})
 }
 
+// TestHistfileOverride verifies the history file precedence: the config
+// override, then $HISTFILE (bash and zsh only), then the shell default.
+func TestHistfileOverride(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_DATA_HOME", "")
+	t.Setenv("fish_history", "")
+	histfile := filepath.Join(home, ".cache", "zsh", "history")
+
+	tests := []struct {
+		shell, histfile, override, want string
+	}{
+		{"zsh", histfile, "", histfile},
+		{"bash", histfile, "", histfile},
+		{"zsh", histfile, "/custom/history", "/custom/history"},
+		{"zsh", "", "", filepath.Join(home, ".zsh_history")},
+		{"fish", histfile, "", filepath.Join(home, ".local", "share", "fish", "fish_history")},
+	}
+	for _, tt := range tests {
+		t.Setenv("SHELL", "/bin/"+tt.shell)
+		t.Setenv("HISTFILE", tt.histfile)
+		if _, got := historySource(tt.override); got != tt.want {
+			t.Errorf("%s, HISTFILE=%q, override=%q: got %q, want %q", tt.shell, tt.histfile, tt.override, got, tt.want)
+		}
+	}
+
+	// fish keeps its history under $XDG_DATA_HOME, one file per session.
+	t.Setenv("SHELL", "/usr/bin/fish")
+	t.Setenv("XDG_DATA_HOME", "/xdg")
+	t.Setenv("fish_history", "work")
+	if _, got := historySource(""); got != filepath.Join("/xdg", "fish", "work_history") {
+		t.Errorf("fish with XDG_DATA_HOME and fish_history: got %q", got)
+	}
+
+	// The baseline and the stop-time read must agree on the file.
+	t.Setenv("SHELL", "/usr/bin/zsh")
+	t.Setenv("HISTFILE", histfile)
+	if err := os.MkdirAll(filepath.Dir(histfile), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	content := fmt.Sprintf(": %d:0;before\n: %d:0;during\n", now.Add(-2*time.Hour).Unix(), now.Unix())
+	if err := os.WriteFile(histfile, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got := SnapshotHistoryBaseline(""); got != 2 {
+		t.Errorf("SnapshotHistoryBaseline = %d, want 2", got)
+	}
+	stop := now.Add(time.Hour)
+	sess := &session.Session{StartTime: now.Add(-time.Hour), StopTime: &stop}
+	result, err := (&ShellCollector{}).Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect returned error: %v", err)
+	}
+	if len(result.Commands) != 1 || result.Commands[0].Raw != "during" {
+		t.Errorf("commands = %v, want [during]", result.Commands)
+	}
+}
+
 // TestMissingHistoryFile tests that a missing history file produces a warning and empty commands.
 func TestMissingHistoryFile(t *testing.T) {
 	now := time.Now()
@@ -345,3 +549,171 @@ func TestMissingHistoryFile(t *testing.T) {
 		t.Errorf("expected warning to mention 'history', got: %v", result.Warnings)
 	}
 }
+
+// TestDropPausedCommands verifies that commands run while the session was
+// paused are removed, while untimestamped commands are kept.
+func TestDropPausedCommands(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	sess := &session.Session{
+		StartTime: base,
+		PausedIntervals: []session.PauseInterval{
+			{Start: base.Add(10 * time.Minute), End: base.Add(20 * time.Minute)},
+			{Start: base.Add(50 * time.Minute)}, // still paused
+		},
+	}
+	cmds := []bundle.Command{
+		{Raw: "before", Timestamp: base.Add(5 * time.Minute)},
+		{Raw: "during", Timestamp: base.Add(15 * time.Minute)},
+		{Raw: "between", Timestamp: base.Add(30 * time.Minute)},
+		{Raw: "open-pause", Timestamp: base.Add(55 * time.Minute)},
+		{Raw: "no-timestamp"},
+	}
+
+	got := dropPausedCommands(cmds, sess)
+	var raws []string
+	for _, c := range got {
+		raws = append(raws, c.Raw)
+	}
+	want := "before,between,no-timestamp"
+	if strings.Join(raws, ",") != want {
+		t.Errorf("dropPausedCommands: got %v, want %s", raws, want)
+	}
+}
+
+// TestPluginLogConcurrentAppend simulates the shell plugin appending to the
+// command log while sessions are being stopped, and checks that every
+// command is collected exactly once.
+func TestPluginLogConcurrentAppend(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	logPath, err := shellpkg.CommandLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	const total = 300
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < total; i++ {
+			// Open, append and close per command, like the plugin's >>.
+			f, err := os.OpenFile(logPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			fmt.Fprintf(f, "%d\tcmd-%d\n", time.Now().Unix(), i)
+			f.Close()
+			time.Sleep(100 * time.Microsecond)
+		}
+	}()
+
+	sess := &session.Session{StartTime: time.Now().Add(-time.Minute)}
+	sc := &ShellCollector{UsePluginLog: true, HistoryPath: filepath.Join(tmp, "no_history")}
+	seen := make(map[string]int)
+	collect := func() {
+		result, err := sc.Collect(context.Background(), sess)
+		if err != nil {
+			t.Fatalf("Collect: %v", err)
+		}
+		for _, c := range result.Commands {
+			seen[c.Raw]++
+		}
+	}
+	for running := true; running; {
+		select {
+		case <-done:
+			running = false
+		default:
+			collect()
+		}
+	}
+	collect()
+
+	for i := 0; i < total; i++ {
+		if n := seen[fmt.Sprintf("cmd-%d", i)]; n != 1 {
+			t.Errorf("cmd-%d collected %d times, want 1", i, n)
+		}
+	}
+}
+
+// TestPluginLogExitCodes verifies that the command log's <epoch>\t<exit>\t<cmd>
+// lines keep their exit status, and that old <epoch>\t<cmd> lines still parse.
+func TestPluginLogExitCodes(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+	logPath, err := shellpkg.CommandLogPath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now().Unix()
+	log := fmt.Sprintf("%d\tmake build\n%d\t0\tgo vet ./...\n%d\t2\tgo test ./...\n%d\techo 'a\tb'\n",
+		now, now, now, now)
+	if err := os.WriteFile(logPath, []byte(log), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	sess := &session.Session{StartTime: time.Now().Add(-time.Minute)}
+	sc := &ShellCollector{UsePluginLog: true, HistoryPath: filepath.Join(tmp, "no_history")}
+	result, err := sc.Collect(context.Background(), sess)
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	var got []string
+	for _, c := range result.Commands {
+		code := "-"
+		if c.ExitCode != nil {
+			code = strconv.Itoa(*c.ExitCode)
+		}
+		got = append(got, code+" "+c.Raw)
+	}
+	want := []string{"- make build", "0 go vet ./...", "2 go test ./...", "- echo 'a\tb'"}
+	if !slices.Equal(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+	if c := bundle.LastFailed(result.Commands); c == nil || c.Raw != "go test ./..." {
+		t.Errorf("LastFailed: got %+v", c)
+	}
+}
+
+// TestParseFishHistoryFixture verifies parsing of history as fish writes it:
+// paths lists (before or after when:), escaped backslashes and newlines, and
+// values containing "when:".
+func TestParseFishHistoryFixture(t *testing.T) {
+	f, err := os.Open(filepath.Join("testdata", "fish_history"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	got, err := parseFishHistory(f, time.Time{})
+	if err != nil {
+		t.Fatalf("parseFishHistory: %v", err)
+	}
+
+	want := []struct {
+		cmd  string
+		when int64
+	}{
+		{"cd ~/code/handoff", 1760000000},
+		{`git commit -m "fix: keep when: in messages"`, 1760000010},
+		{"for f in *.go\n    gofmt -l $f\nend", 1760000020},
+		{`echo C:\Users\dev`, 1760000030},
+		{"vim internal/collector/shell.go cmd/stop.go", 1760000040},
+		{"cat logs/when: 1.txt", 1760000050},
+		{"make test", 1760000060},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("expected %d commands, got %d: %+v", len(want), len(got), got)
+	}
+	for i, w := range want {
+		if got[i].Raw != w.cmd || got[i].Timestamp.Unix() != w.when {
+			t.Errorf("entry %d: got %q at %d, want %q at %d", i, got[i].Raw, got[i].Timestamp.Unix(), w.cmd, w.when)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// This file reads VS Code's state database with modernc.org/sqlite, a cgo-free
+// port of SQLite, so neither the sqlite3 CLI nor a C toolchain is needed.
+
+// errSQLiteCorrupt is wrapped by errors for databases that don't parse. A
+// database being written while it is read can look like this.
+var errSQLiteCorrupt = errors.New("malformed database")
+
+// readVSCodeItem returns the value stored under key in the ItemTable of the
+// VS Code state database at path. The database is opened read-only, and sees
+// what a running VS Code has written to its write-ahead log.
+func readVSCodeItem(ctx context.Context, path, key string) (value string, found bool, err error) {
+	dsn := (&url.URL{
+		Scheme:   "file",
+		OmitHost: true,
+		Path:     path,
+		RawQuery: "mode=ro&_pragma=busy_timeout(1000)",
+	}).String()
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return "", false, err
+	}
+	defer db.Close()
+
+	var raw []byte
+	err = db.QueryRowContext(ctx, "SELECT value FROM ItemTable WHERE key = ?", key).Scan(&raw)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return "", false, nil
+	case err != nil:
+		return "", false, sqliteError(err)
+	}
+	return string(raw), true, nil
+}
+
+// sqliteError wraps errSQLiteCorrupt into err if SQLite found the file
+// malformed or not a database at all.
+func sqliteError(err error) error {
+	var serr *sqlite.Error
+	if errors.As(err, &serr) {
+		switch serr.Code() & 0xff {
+		case sqlite3.SQLITE_CORRUPT, sqlite3.SQLITE_NOTADB:
+			return fmt.Errorf("%w: %v", errSQLiteCorrupt, err)
+		}
+	}
+	return err
+}
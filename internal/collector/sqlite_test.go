@@ -0,0 +1,154 @@
+package collector
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writeVSCodeDB creates a state database at path with 512-byte pages, so a
+// few rows already span several pages, and stores items in its ItemTable.
+// The connection is returned open; pragmas are added to its DSN.
+func writeVSCodeDB(t *testing.T, path, pragmas string, items map[string]string) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=page_size(512)"+pragmas)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec("CREATE TABLE ItemTable (key TEXT UNIQUE ON CONFLICT REPLACE, value BLOB)"); err != nil {
+		t.Fatal(err)
+	}
+	for k, v := range items {
+		if _, err := db.Exec("INSERT INTO ItemTable VALUES (?, ?)", k, v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return db
+}
+
+// historyEntries returns a history.entries value listing paths.
+func historyEntries(t *testing.T, paths ...string) string {
+	t.Helper()
+	var entries []map[string]any
+	for _, p := range paths {
+		entries = append(entries, map[string]any{"editor": map[string]string{"resource": "file://" + p}})
+	}
+	data, err := json.Marshal(entries)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// TestReadVSCodeDBTabs verifies that the editor history is read from a
+// state database whose table spans several pages, with a history.entries
+// value long enough to need overflow pages.
+func TestReadVSCodeDBTabs(t *testing.T) {
+	var files []string
+	for i := range 40 {
+		files = append(files, fmt.Sprintf("/work/src/module%03d/file.go", i))
+	}
+	items := map[string]string{"history.entries": historyEntries(t, files...)}
+	for i := range 120 {
+		items[fmt.Sprintf("setting.%d", i)] = fmt.Sprintf("value %d", i)
+	}
+	path := filepath.Join(t.TempDir(), "state.vscdb")
+	writeVSCodeDB(t, path, "", items).Close()
+
+	got, err := readVSCodeDBTabs(context.Background(), path)
+	if err != nil {
+		t.Fatalf("readVSCodeDBTabs: %v", err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(files) {
+		t.Errorf("got %v, want %v", got, files)
+	}
+
+	value, found, err := readVSCodeItem(context.Background(), path, "setting.119")
+	if err != nil || !found || value != "value 119" {
+		t.Errorf("setting.119: got %q, %v, %v", value, found, err)
+	}
+	if _, found, err := readVSCodeItem(context.Background(), path, "missing"); found || err != nil {
+		t.Errorf("missing key: got found=%v, err=%v", found, err)
+	}
+}
+
+// TestReadVSCodeDBTabsWAL verifies that writes still in the write-ahead log
+// are seen, and that the file alone gives the checkpointed value.
+func TestReadVSCodeDBTabsWAL(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "state.vscdb")
+	db := writeVSCodeDB(t, path, "&_pragma=journal_mode(WAL)&_pragma=wal_autocheckpoint(0)",
+		map[string]string{"history.entries": historyEntries(t, "/work/old.go")})
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Exec("INSERT INTO ItemTable VALUES ('history.entries', ?)", historyEntries(t, "/work/new.go")); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := readVSCodeDBTabs(context.Background(), path)
+	if err != nil {
+		t.Fatalf("readVSCodeDBTabs: %v", err)
+	}
+	if want := []string{"/work/new.go"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("with the log: got %v, want %v", got, want)
+	}
+
+	// Copied while the writer is still open, the file lacks the new entry.
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alone := filepath.Join(t.TempDir(), "state.vscdb")
+	if err := os.WriteFile(alone, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	got, err = readVSCodeDBTabs(context.Background(), alone)
+	if err != nil {
+		t.Fatalf("readVSCodeDBTabs without the log: %v", err)
+	}
+	if want := []string{"/work/old.go"}; fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("without the log: got %v, want %v", got, want)
+	}
+}
+
+// TestReadVSCodeDBTabsCorrupt verifies that a damaged database is reported
+// as malformed, not read or panicked on.
+func TestReadVSCodeDBTabsCorrupt(t *testing.T) {
+	delays := sqliteRetryDelays
+	sqliteRetryDelays = nil
+	t.Cleanup(func() { sqliteRetryDelays = delays })
+
+	items := map[string]string{"history.entries": historyEntries(t, "/work/a.go")}
+	for i := range 60 {
+		items[fmt.Sprintf("setting.%d", i)] = fmt.Sprintf("value %d", i)
+	}
+	src := filepath.Join(t.TempDir(), "state.vscdb")
+	writeVSCodeDB(t, src, "", items).Close()
+	data, err := os.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, damage := range map[string]func([]byte) []byte{
+		"zeroed":    func(b []byte) []byte { clear(b[512:]); return b },
+		"truncated": func(b []byte) []byte { return b[:1024] },
+		"cells":     func(b []byte) []byte { copy(b[100:], []byte{0x0d, 0, 0, 0xff, 0xff}); return b },
+		"header":    func(b []byte) []byte { copy(b, "not a database!!"); return b },
+	} {
+		path := filepath.Join(t.TempDir(), "state.vscdb")
+		if err := os.WriteFile(path, damage(append([]byte(nil), data...)), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := readVSCodeDBTabs(context.Background(), path); !errors.Is(err, errSQLiteCorrupt) {
+			t.Errorf("%s: expected a malformed-database error, got %v", name, err)
+		}
+	}
+}
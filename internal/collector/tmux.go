@@ -0,0 +1,109 @@
+package collector
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// tmuxWindowFormat and tmuxPaneFormat are the list-windows / list-panes
+// formats parsed by parseTmuxWindows and parseTmuxPanes. Fields are
+// tab-separated; the free-text field comes last so it may contain tabs.
+const (
+	tmuxWindowFormat = "#{window_index}\t#{window_active}\t#{window_layout}\t#{window_name}"
+	tmuxPaneFormat   = "#{window_index}\t#{pane_index}\t#{pane_active}\t#{pane_current_command}\t#{pane_current_path}"
+)
+
+// TmuxCollector records the window and pane layout of the tmux session
+// handoff runs in, so the next person can recreate it. Outside tmux ($TMUX
+// unset) it does nothing.
+type TmuxCollector struct{}
+
+// Name implements Collector.
+func (tc *TmuxCollector) Name() string { return "tmux" }
+
+// Collect implements Collector. tmux failures are reported as warnings.
+func (tc *TmuxCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	if os.Getenv("TMUX") == "" {
+		return CollectorResult{}, nil
+	}
+
+	tmux := func(args ...string) (string, error) {
+		out, err := exec.CommandContext(ctx, "tmux", args...).Output()
+		return string(out), err
+	}
+	name, err := tmux("display-message", "-p", "#{session_name}")
+	if err != nil {
+		return CollectorResult{Warnings: []string{fmt.Sprintf("tmux layout unavailable: %v", err)}}, nil
+	}
+	windows, err := tmux("list-windows", "-F", tmuxWindowFormat)
+	if err != nil {
+		return CollectorResult{Warnings: []string{fmt.Sprintf("tmux layout unavailable: %v", err)}}, nil
+	}
+	panes, err := tmux("list-panes", "-s", "-F", tmuxPaneFormat)
+	if err != nil {
+		return CollectorResult{Warnings: []string{fmt.Sprintf("tmux layout unavailable: %v", err)}}, nil
+	}
+
+	layout := &bundle.TmuxLayout{
+		Session: strings.TrimSpace(name),
+		Windows: parseTmuxWindows(windows),
+	}
+	addTmuxPanes(layout.Windows, panes)
+	return CollectorResult{Tmux: layout}, nil
+}
+
+// parseTmuxWindows parses list-windows output in tmuxWindowFormat.
+func parseTmuxWindows(out string) []bundle.TmuxWindow {
+	var windows []bundle.TmuxWindow
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 4)
+		if len(fields) < 4 {
+			continue
+		}
+		index, err := strconv.Atoi(fields[0])
+		if err != nil {
+			continue
+		}
+		windows = append(windows, bundle.TmuxWindow{
+			Index:  index,
+			Active: fields[1] == "1",
+			Layout: fields[2],
+			Name:   fields[3],
+		})
+	}
+	return windows
+}
+
+// addTmuxPanes parses list-panes output in tmuxPaneFormat and attaches each
+// pane to its window.
+func addTmuxPanes(windows []bundle.TmuxWindow, out string) {
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(line, "\t", 5)
+		if len(fields) < 5 {
+			continue
+		}
+		window, err1 := strconv.Atoi(fields[0])
+		index, err2 := strconv.Atoi(fields[1])
+		if err1 != nil || err2 != nil {
+			continue
+		}
+		for i := range windows {
+			if windows[i].Index == window {
+				windows[i].Panes = append(windows[i].Panes, bundle.TmuxPane{
+					Index:   index,
+					Active:  fields[2] == "1",
+					Command: fields[3],
+					Dir:     fields[4],
+				})
+				break
+			}
+		}
+	}
+}
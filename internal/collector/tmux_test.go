@@ -0,0 +1,48 @@
+package collector
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/fakeyudi/handoff/internal/bundle"
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestTmuxLayoutParsing verifies that list-windows and list-panes output is
+// assembled into windows with their panes.
+func TestTmuxLayoutParsing(t *testing.T) {
+	windows := parseTmuxWindows("1\t1\tb25f,200x50,0,0{100x50,0,0,1,99x50,101,0,2}\teditor\n" +
+		"2\t0\tc3a1,200x50,0,0,3\tlogs\tand tabs\n" +
+		"garbage\n")
+	addTmuxPanes(windows, "1\t0\t1\tnvim\t/work/app\n" +
+		"1\t1\t0\tzsh\t/work/app/server\n" +
+		"2\t0\t1\ttail\t/var/log\n" +
+		"9\t0\t0\torphan\t/tmp\n")
+
+	want := []bundle.TmuxWindow{
+		{Index: 1, Name: "editor", Active: true, Layout: "b25f,200x50,0,0{100x50,0,0,1,99x50,101,0,2}", Panes: []bundle.TmuxPane{
+			{Index: 0, Command: "nvim", Dir: "/work/app", Active: true},
+			{Index: 1, Command: "zsh", Dir: "/work/app/server"},
+		}},
+		{Index: 2, Name: "logs\tand tabs", Layout: "c3a1,200x50,0,0,3", Panes: []bundle.TmuxPane{
+			{Index: 0, Command: "tail", Dir: "/var/log", Active: true},
+		}},
+	}
+	if !reflect.DeepEqual(windows, want) {
+		t.Errorf("got  %+v\nwant %+v", windows, want)
+	}
+}
+
+// TestTmuxCollectorOutsideTmux verifies that the collector is silent when
+// $TMUX isn't set.
+func TestTmuxCollectorOutsideTmux(t *testing.T) {
+	t.Setenv("TMUX", "")
+	result, err := (&TmuxCollector{}).Collect(context.Background(), &session.Session{})
+	if err != nil {
+		t.Fatalf("Collect: %v", err)
+	}
+	if result.Tmux != nil || len(result.Warnings) != 0 {
+		t.Errorf("expected an empty result, got %+v", result)
+	}
+}
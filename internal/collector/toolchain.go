@@ -0,0 +1,73 @@
+package collector
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// defaultProbeTimeout bounds each probe so a hanging tool can't block stop.
+const defaultProbeTimeout = 3 * time.Second
+
+// ToolchainCollector records the versions of the tools the session ran under
+// by running each probe command (e.g. "node --version") and keeping its output.
+type ToolchainCollector struct {
+	Probes []string
+	// Timeout applies to each probe individually; defaults to defaultProbeTimeout.
+	Timeout time.Duration
+}
+
+// Name implements Collector.
+func (tc *ToolchainCollector) Name() string { return "toolchain" }
+
+// Collect implements Collector. Probes whose tool isn't installed are skipped
+// silently; probes that fail or time out produce a warning.
+func (tc *ToolchainCollector) Collect(ctx context.Context, sess *session.Session) (CollectorResult, error) {
+	timeout := tc.Timeout
+	if timeout <= 0 {
+		timeout = defaultProbeTimeout
+	}
+
+	var result CollectorResult
+	toolchains := make(map[string]string)
+	for _, probe := range tc.Probes {
+		args := strings.Fields(probe)
+		if len(args) == 0 {
+			continue
+		}
+		if _, err := exec.LookPath(args[0]); err != nil {
+			continue
+		}
+
+		out, err := runProbe(ctx, timeout, args)
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("toolchain probe %q: %v", probe, err))
+			continue
+		}
+		toolchains[probe] = out
+	}
+	if len(toolchains) > 0 {
+		result.Toolchains = toolchains
+	}
+	return result, nil
+}
+
+// runProbe runs args with a timeout and returns its trimmed combined output.
+func runProbe(ctx context.Context, timeout time.Duration, args []string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, args[0], args[1:]...).CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return "", fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,38 @@
+package collector
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fakeyudi/handoff/internal/session"
+)
+
+// TestToolchainCollectorProbes verifies that probe output is recorded, missing
+// tools are skipped, and a hanging probe is cut off with a warning.
+func TestToolchainCollectorProbes(t *testing.T) {
+	tc := &ToolchainCollector{
+		Probes:  []string{"echo v1.2.3", "handoff-no-such-tool --version", "sleep 5"},
+		Timeout: 200 * time.Millisecond,
+	}
+
+	start := time.Now()
+	result, err := tc.Collect(context.Background(), &session.Session{StartTime: time.Now()})
+	if err != nil {
+		t.Fatalf("Collect returned unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Errorf("Collect took %s; expected the hanging probe to time out", elapsed)
+	}
+
+	if got := result.Toolchains["echo v1.2.3"]; got != "v1.2.3" {
+		t.Errorf("echo probe output = %q, want %q", got, "v1.2.3")
+	}
+	if _, ok := result.Toolchains["handoff-no-such-tool --version"]; ok {
+		t.Error("expected missing tool to be skipped")
+	}
+	if len(result.Warnings) != 1 || !strings.Contains(result.Warnings[0], "timed out") {
+		t.Errorf("expected one timeout warning, got %v", result.Warnings)
+	}
+}
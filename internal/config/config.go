@@ -1,26 +1,80 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Config holds all configurable Handoff settings.
 type Config struct {
 	IgnorePatterns   []string `json:"ignore_patterns"`
 	ShellHistoryPath string   `json:"shell_history_path"` // override auto-detect
-	DefaultFormat    string   `json:"default_format"`     // "markdown" | "json"
+	DefaultFormat    string   `json:"default_format"`     // "markdown" | "json" | "jsonl"
 	OutputDir        string   `json:"output_dir"`
+	EnvAllowList     []string `json:"env_allow_list"`   // env vars to capture in the bundle
+	ToolchainProbes  []string `json:"toolchain_probes"` // version commands, e.g. "node --version"
+	CollectTimeout   string   `json:"collect_timeout"`  // Go duration bounding all collectors, e.g. "30s"
+	DiffBase         string   `json:"diff_base"`        // git ref file diffs are taken against
+	AnnotateHunks    *bool    `json:"annotate_hunks"`   // mark diff hunks written during/before the session
+	GitLogLimit      int      `json:"git_log_limit"`    // max commits in Recent Commits
+	GitLogAuthor     string   `json:"git_log_author"`   // only list commits by this author
+	GitLogNoMerges   *bool    `json:"git_log_no_merges"`
+	SigningKey       string   `json:"signing_key"`      // file holding the shared secret for sign/verify
+	PublishURL       string   `json:"publish_url"`      // where stop publishes bundles: http(s) URL or gist://
+	DedupeCommands   *bool    `json:"dedupe_commands"`  // collapse repeated commands into one counted entry
+	Frontmatter      *bool    `json:"frontmatter"`      // prepend YAML frontmatter to Markdown bundles
+	DiffCache        *bool    `json:"diff_cache"`       // keep captured diffs on disk to reuse across stops
+	Encrypt          *bool    `json:"encrypt"`          // encrypt bundles written by stop with a passphrase
+	SummaryTemplate  string   `json:"summary_template"` // pre-fills the summary prompt at stop
+	TimelineCluster  string   `json:"timeline_cluster"` // Go duration grouping edits under nearby notes in the viewer; "0s" turns it off
+	// Collector switches; nil means enabled. Read them with Enabled.
+	CollectFiles   *bool `json:"collect_files"`
+	CollectShell   *bool `json:"collect_shell"`
+	CollectGit     *bool `json:"collect_git"`
+	CollectEditors *bool `json:"collect_editors"`
+	// EnableWatcher controls whether start launches the background file
+	// watcher; nil means enabled. Read it with Enabled.
+	EnableWatcher *bool `json:"enable_watcher"`
+	// RespectGitignore controls whether .gitignore files and the global git
+	// excludes file hide files from bundles; nil means they do. Read it with
+	// Enabled.
+	RespectGitignore *bool `json:"respect_gitignore"`
+	// IncludeDiffs controls whether stop keeps diffs in bundles; nil means
+	// it does. Without them a bundle still lists the changed files and their
+	// line counts. Read it with Enabled.
+	IncludeDiffs *bool `json:"include_diffs"`
+}
+
+// Enabled reports whether a collector switch such as CollectShell is on.
+// Switches that were never set default to on.
+func Enabled(setting *bool) bool {
+	return setting == nil || *setting
+}
+
+// On reports whether an opt-in switch such as Encrypt is on. Switches that
+// were never set default to off.
+func On(setting *bool) bool {
+	return setting != nil && *setting
 }
 
 // Defaults returns sensible default configuration values.
 func Defaults() Config {
 	return Config{
-		DefaultFormat:  "markdown",
-		OutputDir:      ".",
-		IgnorePatterns: []string{},
+		DefaultFormat:   "markdown",
+		OutputDir:       ".",
+		IgnorePatterns:  []string{},
+		ToolchainProbes: []string{"go version", "git --version"},
+		CollectTimeout:  "30s",
+		TimelineCluster: "5s",
+		DiffBase:        "HEAD",
+		GitLogLimit:     50,
 	}
 }
 
@@ -35,13 +89,51 @@ func LoadGlobal() (*Config, error) {
 	return loadFile(path, true)
 }
 
+// ProjectTemplate is the .handoffconfig written by `handoff init`. Every
+// setting but ignore_patterns is commented out, so the file changes nothing
+// until someone edits it.
+const ProjectTemplate = `// handoff project config, shared by everyone working in this repository.
+// Settings here override ~/.config/handoff/config.json. This file is JSON
+// with comments: uncomment a line to set it. See the README for the rest.
+{
+  // Gitignore-style patterns for files to leave out of bundles, on top of
+  // .gitignore and .handoffignore.
+  "ignore_patterns": [],
+
+  // Set to false to track files .gitignore excludes, such as build output.
+  // .handoffignore still applies, and can re-include files with "!pattern".
+  // "respect_gitignore": true,
+
+  // Set to false to leave diffs out of bundles, keeping only the changed
+  // paths and line counts.
+  // "include_diffs": true,
+
+  // Bundle format: "markdown", "json" or "jsonl".
+  // "default_format": "markdown",
+
+  // Where bundles are written, relative to the directory handoff runs in.
+  // "output_dir": ".",
+}
+`
+
+// LoadFile reads the config file at path, named with --config in place of
+// the global one. Unlike the files handoff looks for, it must exist.
+func LoadFile(path string) (*Config, error) {
+	cfg, err := loadFile(path, false)
+	if err == nil && cfg == nil {
+		return nil, fmt.Errorf("config file %s not found", path)
+	}
+	return cfg, err
+}
+
 // LoadProject reads .handoffconfig in the current working directory.
 // Returns nil (no error) if the file is absent.
 func LoadProject() (*Config, error) {
 	return loadFile(".handoffconfig", false)
 }
 
-// loadFile reads and parses a JSON config file at path.
+// loadFile reads and parses a JSON config file at path. Comments and
+// trailing commas are allowed (see stripJSONC).
 // If returnDefaults is true, returns defaults when the file is absent.
 // If returnDefaults is false, returns nil when the file is absent.
 func loadFile(path string, returnDefaults bool) (*Config, error) {
@@ -57,12 +149,56 @@ func loadFile(path string, returnDefaults bool) (*Config, error) {
 		return nil, err
 	}
 	var cfg Config
-	if err := json.Unmarshal(data, &cfg); err != nil {
+	dec := json.NewDecoder(bytes.NewReader(stripJSONC(data)))
+	if os.Getenv(LaxEnvVar) == "" {
+		dec.DisallowUnknownFields()
+	}
+	if err := dec.Decode(&cfg); err != nil {
+		if key, ok := unknownField(err); ok {
+			err = fmt.Errorf("unknown key %q (check for typos, or set %s=1 to ignore unknown keys)", key, LaxEnvVar)
+		}
+		return nil, &ParseError{Path: path, Err: err}
+	}
+	if err := cfg.validate(); err != nil {
 		return nil, &ParseError{Path: path, Err: err}
 	}
 	return &cfg, nil
 }
 
+// LaxEnvVar, when set to a non-empty value, makes config loading ignore
+// unknown keys instead of rejecting them.
+const LaxEnvVar = "HANDOFF_LAX_CONFIG"
+
+// knownFormats are the accepted values for default_format.
+var knownFormats = []string{"markdown", "json", "jsonl"}
+
+// validate checks field values that JSON decoding alone can't.
+func (c *Config) validate() error {
+	if c.GitLogLimit < 0 {
+		return fmt.Errorf("invalid git_log_limit %d (want a positive number)", c.GitLogLimit)
+	}
+	if c.DefaultFormat != "" && !slices.Contains(knownFormats, c.DefaultFormat) {
+		return fmt.Errorf("invalid default_format %q (want one of: %s)", c.DefaultFormat, strings.Join(knownFormats, ", "))
+	}
+	if c.TimelineCluster != "" {
+		if d, err := time.ParseDuration(c.TimelineCluster); err != nil || d < 0 {
+			return fmt.Errorf("invalid timeline_cluster %q (want a duration such as \"5s\", or \"0s\" to turn it off)", c.TimelineCluster)
+		}
+	}
+	return nil
+}
+
+// unknownField extracts the key name from encoding/json's unknown-field
+// error, which has no dedicated type.
+func unknownField(err error) (string, bool) {
+	const prefix = "json: unknown field "
+	msg := err.Error()
+	if !strings.HasPrefix(msg, prefix) {
+		return "", false
+	}
+	return strings.Trim(strings.TrimPrefix(msg, prefix), `"`), true
+}
+
 // Merge combines global and project configs, with project taking precedence.
 // Missing keys fall back to global, then defaults.
 func Merge(global, project *Config) Config {
@@ -82,6 +218,75 @@ func Merge(global, project *Config) Config {
 		if len(global.IgnorePatterns) > 0 {
 			result.IgnorePatterns = global.IgnorePatterns
 		}
+		if len(global.EnvAllowList) > 0 {
+			result.EnvAllowList = global.EnvAllowList
+		}
+		if len(global.ToolchainProbes) > 0 {
+			result.ToolchainProbes = global.ToolchainProbes
+		}
+		if global.CollectTimeout != "" {
+			result.CollectTimeout = global.CollectTimeout
+		}
+		if global.DiffBase != "" {
+			result.DiffBase = global.DiffBase
+		}
+		if global.AnnotateHunks != nil {
+			result.AnnotateHunks = global.AnnotateHunks
+		}
+		if global.GitLogLimit > 0 {
+			result.GitLogLimit = global.GitLogLimit
+		}
+		if global.GitLogAuthor != "" {
+			result.GitLogAuthor = global.GitLogAuthor
+		}
+		if global.GitLogNoMerges != nil {
+			result.GitLogNoMerges = global.GitLogNoMerges
+		}
+		if global.SigningKey != "" {
+			result.SigningKey = global.SigningKey
+		}
+		if global.PublishURL != "" {
+			result.PublishURL = global.PublishURL
+		}
+		if global.DedupeCommands != nil {
+			result.DedupeCommands = global.DedupeCommands
+		}
+		if global.Frontmatter != nil {
+			result.Frontmatter = global.Frontmatter
+		}
+		if global.DiffCache != nil {
+			result.DiffCache = global.DiffCache
+		}
+		if global.Encrypt != nil {
+			result.Encrypt = global.Encrypt
+		}
+		if global.SummaryTemplate != "" {
+			result.SummaryTemplate = global.SummaryTemplate
+		}
+		if global.TimelineCluster != "" {
+			result.TimelineCluster = global.TimelineCluster
+		}
+		if global.CollectFiles != nil {
+			result.CollectFiles = global.CollectFiles
+		}
+		if global.CollectShell != nil {
+			result.CollectShell = global.CollectShell
+		}
+		if global.CollectGit != nil {
+			result.CollectGit = global.CollectGit
+		}
+		if global.CollectEditors != nil {
+			result.CollectEditors = global.CollectEditors
+		}
+		if global.EnableWatcher != nil {
+			result.EnableWatcher = global.EnableWatcher
+		}
+		if global.RespectGitignore != nil {
+			result.RespectGitignore = global.RespectGitignore
+		}
+		if global.IncludeDiffs != nil {
+			result.IncludeDiffs = global.IncludeDiffs
+		}
 	}
 
 	// Apply project values over global.
@@ -98,11 +303,127 @@ func Merge(global, project *Config) Config {
 		if len(project.IgnorePatterns) > 0 {
 			result.IgnorePatterns = project.IgnorePatterns
 		}
+		if len(project.EnvAllowList) > 0 {
+			result.EnvAllowList = project.EnvAllowList
+		}
+		if len(project.ToolchainProbes) > 0 {
+			result.ToolchainProbes = project.ToolchainProbes
+		}
+		if project.CollectTimeout != "" {
+			result.CollectTimeout = project.CollectTimeout
+		}
+		if project.DiffBase != "" {
+			result.DiffBase = project.DiffBase
+		}
+		if project.AnnotateHunks != nil {
+			result.AnnotateHunks = project.AnnotateHunks
+		}
+		if project.GitLogLimit > 0 {
+			result.GitLogLimit = project.GitLogLimit
+		}
+		if project.GitLogAuthor != "" {
+			result.GitLogAuthor = project.GitLogAuthor
+		}
+		if project.GitLogNoMerges != nil {
+			result.GitLogNoMerges = project.GitLogNoMerges
+		}
+		if project.SigningKey != "" {
+			result.SigningKey = project.SigningKey
+		}
+		if project.PublishURL != "" {
+			result.PublishURL = project.PublishURL
+		}
+		if project.DedupeCommands != nil {
+			result.DedupeCommands = project.DedupeCommands
+		}
+		if project.Frontmatter != nil {
+			result.Frontmatter = project.Frontmatter
+		}
+		if project.DiffCache != nil {
+			result.DiffCache = project.DiffCache
+		}
+		if project.Encrypt != nil {
+			result.Encrypt = project.Encrypt
+		}
+		if project.SummaryTemplate != "" {
+			result.SummaryTemplate = project.SummaryTemplate
+		}
+		if project.TimelineCluster != "" {
+			result.TimelineCluster = project.TimelineCluster
+		}
+		if project.CollectFiles != nil {
+			result.CollectFiles = project.CollectFiles
+		}
+		if project.CollectShell != nil {
+			result.CollectShell = project.CollectShell
+		}
+		if project.CollectGit != nil {
+			result.CollectGit = project.CollectGit
+		}
+		if project.CollectEditors != nil {
+			result.CollectEditors = project.CollectEditors
+		}
+		if project.EnableWatcher != nil {
+			result.EnableWatcher = project.EnableWatcher
+		}
+		if project.RespectGitignore != nil {
+			result.RespectGitignore = project.RespectGitignore
+		}
+		if project.IncludeDiffs != nil {
+			result.IncludeDiffs = project.IncludeDiffs
+		}
 	}
 
 	return result
 }
 
+// stripJSONC turns JSON with comments into plain JSON: line (//) and block
+// comments outside strings become spaces, and a comma before a closing } or
+// ] is dropped. Offsets are kept, so decode errors still point
+// at the right place.
+func stripJSONC(data []byte) []byte {
+	out := bytes.Clone(data)
+	lastComma := -1 // offset of a comma that may turn out to be trailing
+	for i := 0; i < len(out); i++ {
+		switch c := out[i]; {
+		case c == '"':
+			lastComma = -1
+			for i++; i < len(out) && out[i] != '"'; i++ {
+				if out[i] == '\\' {
+					i++
+				}
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '/':
+			for ; i < len(out) && out[i] != '\n'; i++ {
+				out[i] = ' '
+			}
+		case c == '/' && i+1 < len(out) && out[i+1] == '*':
+			end := bytes.Index(out[i+2:], []byte("*/"))
+			stop := len(out)
+			if end >= 0 {
+				stop = i + 2 + end + 2
+			}
+			for ; i < stop; i++ {
+				if out[i] != '\n' {
+					out[i] = ' '
+				}
+			}
+			i--
+		case c == ',':
+			lastComma = i
+		case c == '}' || c == ']':
+			if lastComma >= 0 {
+				out[lastComma] = ' '
+			}
+			lastComma = -1
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+		default:
+			lastComma = -1
+		}
+	}
+	return out
+}
+
 // ParseError is returned when a config file exists but cannot be parsed.
 type ParseError struct {
 	Path string
@@ -3,6 +3,10 @@ package config
 import (
 	"errors"
 	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
 	"testing"
 
 	"pgregory.net/rapid"
@@ -75,6 +79,45 @@ func checkStringField(t *rapid.T, name, globalVal, projectVal, defaultVal, merge
 	}
 }
 
+// TestMergeCollectorSwitches verifies that collector switches default to on
+// and that an explicit false in either file turns a collector off, with the
+// project file taking precedence.
+func TestMergeCollectorSwitches(t *testing.T) {
+	on, off := true, false
+
+	merged := Merge(&Config{CollectShell: &off, CollectGit: &off}, &Config{CollectGit: &on})
+	if !Enabled(merged.CollectFiles) {
+		t.Error("CollectFiles: want enabled by default")
+	}
+	if Enabled(merged.CollectShell) {
+		t.Error("CollectShell: want disabled by the global config")
+	}
+	if !Enabled(merged.CollectGit) {
+		t.Error("CollectGit: want the project config to re-enable it")
+	}
+}
+
+// TestMergeOptInSwitches verifies that opt-in switches such as encrypt
+// default to off and that the project file overrides the global one either
+// way.
+func TestMergeOptInSwitches(t *testing.T) {
+	on, off := true, false
+
+	merged := Merge(&Config{Encrypt: &on, DiffCache: &on, Frontmatter: &off}, &Config{Encrypt: &off, Frontmatter: &on})
+	if On(merged.DedupeCommands) {
+		t.Error("DedupeCommands: want off by default")
+	}
+	if On(merged.Encrypt) {
+		t.Error("Encrypt: want the project config to turn it off")
+	}
+	if !On(merged.DiffCache) {
+		t.Error("DiffCache: want it on from the global config")
+	}
+	if !On(merged.Frontmatter) {
+		t.Error("Frontmatter: want the project config to turn it on")
+	}
+}
+
 // --- Unit tests for config defaults and file loading (Requirement 9.4) ---
 
 func TestDefaultsValues(t *testing.T) {
@@ -156,3 +199,112 @@ func TestLoadGlobalParseError(t *testing.T) {
 		t.Errorf("expected *ParseError, got %T: %v", err, err)
 	}
 }
+
+// TestLoadRejectsUnknownKeys verifies that a misspelled key is reported by
+// name, and that HANDOFF_LAX_CONFIG turns the check off.
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+	path := t.TempDir() + "/config.json"
+	if err := os.WriteFile(path, []byte(`{"defualt_format": "json"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadFile(path, false)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *ParseError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), `unknown key "defualt_format"`) {
+		t.Errorf("expected error to name the unknown key, got: %v", err)
+	}
+
+	t.Setenv(LaxEnvVar, "1")
+	if _, err := loadFile(path, false); err != nil {
+		t.Errorf("expected unknown keys to be ignored with %s set, got: %v", LaxEnvVar, err)
+	}
+}
+
+// TestLoadRejectsInvalidDefaultFormat verifies that default_format must be a
+// known renderer.
+func TestLoadRejectsInvalidDefaultFormat(t *testing.T) {
+	path := t.TempDir() + "/config.json"
+	if err := os.WriteFile(path, []byte(`{"default_format": "html"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := loadFile(path, false)
+	var parseErr *ParseError
+	if !errors.As(err, &parseErr) {
+		t.Fatalf("expected *ParseError, got %T: %v", err, err)
+	}
+	if !strings.Contains(err.Error(), "invalid default_format") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+// TestLoadAcceptsEveryFormat verifies that default_format takes each format
+// stop can write.
+func TestLoadAcceptsEveryFormat(t *testing.T) {
+	for _, format := range []string{"markdown", "json", "jsonl"} {
+		path := t.TempDir() + "/config.json"
+		if err := os.WriteFile(path, []byte(`{"default_format": "`+format+`"}`), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		cfg, err := loadFile(path, false)
+		if err != nil {
+			t.Fatalf("%s: %v", format, err)
+		}
+		if cfg.DefaultFormat != format {
+			t.Errorf("DefaultFormat: got %q, want %q", cfg.DefaultFormat, format)
+		}
+	}
+}
+
+// TestLoadAcceptsComments verifies that config files may contain comments
+// and trailing commas, and that comment markers inside strings are kept.
+func TestLoadAcceptsComments(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `// leading comment
+{
+  /* block
+     comment */
+  "output_dir": "out//dir", // trailing comment
+  "ignore_patterns": ["*.log", "/*.tmp",],
+  // "default_format": "json",
+}
+`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := loadFile(path, false)
+	if err != nil {
+		t.Fatalf("loadFile: %v", err)
+	}
+	if cfg.OutputDir != "out//dir" {
+		t.Errorf("output_dir: got %q", cfg.OutputDir)
+	}
+	if want := []string{"*.log", "/*.tmp"}; !slices.Equal(cfg.IgnorePatterns, want) {
+		t.Errorf("ignore_patterns: got %q, want %q", cfg.IgnorePatterns, want)
+	}
+	if cfg.DefaultFormat != "" {
+		t.Errorf("commented-out default_format was read: %q", cfg.DefaultFormat)
+	}
+}
+
+// TestProjectTemplateChangesNothing verifies that the file `handoff init`
+// writes parses and, left as is, leaves the global settings in force.
+func TestProjectTemplateChangesNothing(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".handoffconfig")
+	if err := os.WriteFile(path, []byte(ProjectTemplate), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	project, err := loadFile(path, false)
+	if err != nil {
+		t.Fatalf("template does not parse: %v", err)
+	}
+	global := Defaults()
+	global.DefaultFormat = "json"
+	global.IgnorePatterns = []string{"*.log"}
+	if got := Merge(&global, project); !reflect.DeepEqual(got, global) {
+		t.Errorf("template overrides settings:\ngot  %+v\nwant %+v", got, global)
+	}
+}
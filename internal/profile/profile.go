@@ -9,7 +9,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+
+	shellpkg "github.com/fakeyudi/handoff/internal/shell"
 )
 
 // Profile holds user-level preferences set during first-run setup.
@@ -18,7 +21,9 @@ type Profile struct {
 	DefaultFormat     string `json:"default_format"`      // "markdown" | "json"
 	RecordCommands    bool   `json:"record_commands"`     // install shell plugin
 	OutputDir         string `json:"output_dir"`          // default bundle output dir
-	ShellPluginShell  string `json:"shell_plugin_shell"`  // "zsh" | "bash" | ""
+	ShellPluginShell  string `json:"shell_plugin_shell"`  // "zsh" | "bash" | "powershell" | ""
+	Theme             string `json:"theme,omitempty"`     // viewer theme: "dark" | "light" | "nocolor"
+	TimeZone          string `json:"time_zone,omitempty"` // viewer time zone: IANA name | "local" | "utc"
 }
 
 // profilePath returns the path to the profile file.
@@ -158,25 +163,45 @@ func RunSetup(existing *Profile) (*Profile, error) {
 		return nil, err
 	}
 
+	// The history offer below needs the shell even without the plugin, as
+	// stop then reads the shell's history file.
+	shell, err := ask("  Shell (zsh/bash/powershell)", detectShell())
+	if err != nil {
+		return nil, err
+	}
+	prof.ShellPluginShell = ""
 	if prof.RecordCommands {
-		shell, err := ask("  Shell (zsh/bash)", detectShell())
+		prof.ShellPluginShell = shell
+	}
+
+	// Shells that only write history on exit hide the session's commands
+	// from stop; offer to fix that while we're here.
+	if present, checked := shellpkg.HasHistoryAppend(shell); checked && !present {
+		line := shellpkg.HistoryAppendLine(shell)
+		fix, err := askBool("  Save commands to history as they run (adds `"+line+"` to your rc file)", true)
 		if err != nil {
 			return nil, err
 		}
-		prof.ShellPluginShell = shell
-	} else {
-		prof.ShellPluginShell = ""
+		if fix {
+			if _, err := shellpkg.AppendHistoryLine(shell); err != nil {
+				fmt.Printf("  ⚠ Could not update your rc file: %v\n", err)
+			}
+		}
 	}
 
 	fmt.Println()
 	return prof, nil
 }
 
-// detectShell returns the base name of the current shell.
+// detectShell returns the current shell if the plugin supports it, or a
+// default for the platform.
 func detectShell() string {
-	shell := filepath.Base(os.Getenv("SHELL"))
-	if shell == "zsh" || shell == "bash" {
+	switch shell := shellpkg.Detect(); shell {
+	case "zsh", "bash", "powershell":
 		return shell
 	}
+	if runtime.GOOS == "windows" {
+		return "powershell"
+	}
 	return "zsh"
 }
@@ -0,0 +1,128 @@
+// Package publish sends rendered bundles to a remote sink: any HTTP endpoint
+// that accepts a POST, or a GitHub gist.
+package publish
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// gistAPI is the GitHub endpoint gists are created at (overridden in tests).
+var gistAPI = "https://api.github.com/gists"
+
+// client bounds each publish so a slow server can't hang stop.
+var client = &http.Client{Timeout: 30 * time.Second}
+
+// Bundle is a rendered bundle ready to publish.
+type Bundle struct {
+	Name   string // file name, e.g. handoff-2026-02-19T17:30:00Z.md
+	Format string // "markdown", "json", "jsonl" or "encrypted"
+	Data   []byte
+}
+
+// Publish sends b to target and returns the URL it can be found at.
+//
+// target is either an http(s) URL, which receives the bundle as the body of
+// a POST, or "gist://" (a secret gist) / "gist://public", which creates a
+// GitHub gist using the token in $GITHUB_TOKEN.
+func Publish(ctx context.Context, target string, b Bundle) (string, error) {
+	switch {
+	case strings.HasPrefix(target, "gist://"):
+		return createGist(ctx, strings.TrimPrefix(target, "gist://") == "public", b)
+	case strings.HasPrefix(target, "http://"), strings.HasPrefix(target, "https://"):
+		return post(ctx, target, b)
+	default:
+		return "", fmt.Errorf("unsupported publish target %q (want an http(s) URL or gist://)", target)
+	}
+}
+
+// contentType returns the Content-Type for a bundle format.
+func contentType(format string) string {
+	switch format {
+	case "json":
+		return "application/json"
+	case "jsonl":
+		return "application/x-ndjson"
+	case "encrypted":
+		return "application/octet-stream"
+	}
+	return "text/markdown; charset=utf-8"
+}
+
+// post POSTs the bundle to url. The published location is taken from the
+// Location header, or from a response body that is just a URL; failing
+// both, url itself is returned.
+func post(ctx context.Context, url string, b Bundle) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(b.Data))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", contentType(b.Format))
+	req.Header.Set("X-Handoff-Filename", b.Name)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("%s returned %s", url, resp.Status)
+	}
+
+	if loc, err := resp.Location(); err == nil {
+		return loc.String(), nil
+	}
+	if s := strings.TrimSpace(string(body)); strings.HasPrefix(s, "http") && !strings.ContainsAny(s, " \n") {
+		return s, nil
+	}
+	return url, nil
+}
+
+// createGist creates a gist holding the bundle and returns its page URL.
+func createGist(ctx context.Context, public bool, b Bundle) (string, error) {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		return "", errors.New("gist:// needs a GitHub token in $GITHUB_TOKEN")
+	}
+	payload, err := json.Marshal(map[string]any{
+		"description": "handoff bundle " + b.Name,
+		"public":      public,
+		"files":       map[string]any{b.Name: map[string]string{"content": string(b.Data)}},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, gistAPI, bytes.NewReader(payload))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("creating gist: GitHub returned %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	var gist struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&gist); err != nil {
+		return "", fmt.Errorf("creating gist: %w", err)
+	}
+	return gist.HTMLURL, nil
+}
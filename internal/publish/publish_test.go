@@ -0,0 +1,90 @@
+package publish
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestPublishPost verifies the request sent to an HTTP sink and how the
+// published location is picked from the response.
+func TestPublishPost(t *testing.T) {
+	var gotType, gotBody string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotType = r.Header.Get("Content-Type")
+		data, _ := io.ReadAll(r.Body)
+		gotBody = string(data)
+		switch r.URL.Path {
+		case "/located":
+			w.Header().Set("Location", "/bundles/42")
+			w.WriteHeader(http.StatusCreated)
+		case "/body":
+			io.WriteString(w, "https://handoffs.example.com/7\n")
+		case "/fail":
+			http.Error(w, "nope", http.StatusForbidden)
+		}
+	}))
+	defer srv.Close()
+
+	b := Bundle{Name: "handoff.json", Format: "json", Data: []byte(`{"a":1}`)}
+	url, err := Publish(context.Background(), srv.URL+"/located", b)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if url != srv.URL+"/bundles/42" {
+		t.Errorf("location: got %q", url)
+	}
+	if gotType != "application/json" || gotBody != `{"a":1}` {
+		t.Errorf("request: content-type %q, body %q", gotType, gotBody)
+	}
+
+	if url, err := Publish(context.Background(), srv.URL+"/body", b); err != nil || url != "https://handoffs.example.com/7" {
+		t.Errorf("URL from body: got %q, %v", url, err)
+	}
+	if _, err := Publish(context.Background(), srv.URL+"/fail", b); err == nil {
+		t.Error("expected an error for a 403 response")
+	}
+	if _, err := Publish(context.Background(), "ftp://example.com", b); err == nil {
+		t.Error("expected an error for an unsupported scheme")
+	}
+}
+
+// TestPublishGist verifies the gist request and the returned page URL.
+func TestPublishGist(t *testing.T) {
+	var got struct {
+		Public bool                         `json:"public"`
+		Files  map[string]map[string]string `json:"files"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&got)
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, `{"html_url": "https://gist.github.com/u/abc"}`)
+	}))
+	defer srv.Close()
+	orig := gistAPI
+	gistAPI = srv.URL
+	t.Cleanup(func() { gistAPI = orig })
+
+	b := Bundle{Name: "handoff.md", Format: "markdown", Data: []byte("# Handoff\n")}
+	t.Setenv("GITHUB_TOKEN", "")
+	if _, err := Publish(context.Background(), "gist://", b); err == nil {
+		t.Error("expected an error without $GITHUB_TOKEN")
+	}
+
+	t.Setenv("GITHUB_TOKEN", "tok")
+	url, err := Publish(context.Background(), "gist://public", b)
+	if err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if url != "https://gist.github.com/u/abc" {
+		t.Errorf("url: got %q", url)
+	}
+	if auth != "Bearer tok" || !got.Public || got.Files["handoff.md"]["content"] != "# Handoff\n" {
+		t.Errorf("request: auth %q, payload %+v", auth, got)
+	}
+}
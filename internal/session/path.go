@@ -0,0 +1,45 @@
+package session
+
+import "strings"
+
+// RelPath returns path relative to workDir, with forward slashes, and
+// whether path is inside workDir at all; a path outside it is returned
+// whole, also with forward slashes. Both may use either separator, since a
+// bundle made on Windows can be viewed elsewhere and the other way round,
+// and Windows-style paths compare case-insensitively.
+func RelPath(path, workDir string) (string, bool) {
+	p := toSlash(path)
+	if workDir == "" {
+		return p, false
+	}
+	dir := strings.TrimSuffix(toSlash(workDir), "/")
+	same := func(a, b string) bool { return a == b }
+	if isWindowsPath(workDir) || isWindowsPath(path) {
+		same = strings.EqualFold
+	}
+	if same(p, dir) {
+		return ".", true
+	}
+	if len(p) > len(dir) && p[len(dir)] == '/' && same(p[:len(dir)], dir) {
+		return p[len(dir)+1:], true
+	}
+	return p, false
+}
+
+// toSlash replaces backslashes with forward slashes regardless of the
+// current OS, unlike filepath.ToSlash.
+func toSlash(path string) string {
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// isWindowsPath reports whether path looks like it came from Windows: it
+// starts with a drive letter or contains a backslash.
+func isWindowsPath(path string) bool {
+	if len(path) >= 2 && path[1] == ':' {
+		c := path[0] | 0x20
+		if c >= 'a' && c <= 'z' {
+			return true
+		}
+	}
+	return strings.Contains(path, `\`)
+}
@@ -0,0 +1,30 @@
+package session
+
+import "testing"
+
+// TestRelPath verifies that paths are made relative to the work dir whichever
+// separators either uses, and come back with forward slashes.
+func TestRelPath(t *testing.T) {
+	for _, tc := range []struct {
+		path, workDir string
+		want          string
+		under         bool
+	}{
+		{"/home/me/proj/cmd/main.go", "/home/me/proj", "cmd/main.go", true},
+		{"/home/me/proj/cmd/main.go", "/home/me/proj/", "cmd/main.go", true},
+		{"/home/me/proj", "/home/me/proj", ".", true},
+		{"/home/me/project/main.go", "/home/me/proj", "/home/me/project/main.go", false},
+		{`C:\Users\me\proj\cmd\main.go`, `C:\Users\me\proj`, "cmd/main.go", true},
+		{`C:\Users\me\proj\cmd\main.go`, "C:/Users/me/proj", "cmd/main.go", true},
+		{"c:/users/me/proj/cmd/main.go", `C:\Users\me\proj\`, "cmd/main.go", true},
+		{`C:\Users\me\proj/cmd\main.go`, `C:\Users\me\proj`, "cmd/main.go", true},
+		{`D:\other\main.go`, `C:\Users\me\proj`, "D:/other/main.go", false},
+		{"/Home/Me/Proj/main.go", "/home/me/proj", "/Home/Me/Proj/main.go", false},
+		{`C:\proj\main.go`, "", "C:/proj/main.go", false},
+	} {
+		got, under := RelPath(tc.path, tc.workDir)
+		if got != tc.want || under != tc.under {
+			t.Errorf("RelPath(%q, %q) = %q, %v; want %q, %v", tc.path, tc.workDir, got, under, tc.want, tc.under)
+		}
+	}
+}
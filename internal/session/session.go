@@ -1,6 +1,11 @@
 package session
 
-import "time"
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+)
 
 // Session represents an active or completed tracking session.
 type Session struct {
@@ -14,6 +19,71 @@ type Session struct {
 	// start. At stop time, the collector skips this many entries from the tail
 	// so only commands typed during the session are included.
 	HistoryBaselineCount int `json:"history_baseline_count,omitempty"`
+	// PausedIntervals records periods where tracking was paused. The last
+	// interval has a zero End while the session is currently paused.
+	PausedIntervals []PauseInterval `json:"paused_intervals,omitempty"`
+	// Commands are those carried over from the bundle a session was resumed
+	// from (start --resume-last); stop lists them before the ones it
+	// collects.
+	Commands []Command `json:"commands,omitempty"`
+	// Tags label the session (e.g. "bugfix") so bundles can be filtered.
+	Tags []string `json:"tags,omitempty"`
+	// WatcherPID is the process ID of the background file watcher started
+	// with the session; 0 when none was (start --watch=false), in which
+	// case edits are found by modification time at stop.
+	WatcherPID int `json:"watcher_pid,omitempty"`
+}
+
+// PauseInterval is a period during which a session was paused.
+type PauseInterval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"` // zero while still paused
+}
+
+// AddTag adds tag to the session unless it is already present, and reports
+// whether it was added.
+func (s *Session) AddTag(tag string) bool {
+	if slices.Contains(s.Tags, tag) {
+		return false
+	}
+	s.Tags = append(s.Tags, tag)
+	return true
+}
+
+// IsPaused reports whether the session is currently paused.
+func (s *Session) IsPaused() bool {
+	n := len(s.PausedIntervals)
+	return n > 0 && s.PausedIntervals[n-1].End.IsZero()
+}
+
+// PausedDuration returns the total time spent paused up to until. An interval
+// that is still open is counted as ending at until.
+func (s *Session) PausedDuration(until time.Time) time.Duration {
+	var total time.Duration
+	for _, p := range s.PausedIntervals {
+		end := p.End
+		if end.IsZero() || end.After(until) {
+			end = until
+		}
+		if end.After(p.Start) {
+			total += end.Sub(p.Start)
+		}
+	}
+	return total
+}
+
+// InPause reports whether t falls inside any paused interval. An interval
+// that is still open extends indefinitely.
+func (s *Session) InPause(t time.Time) bool {
+	for _, p := range s.PausedIntervals {
+		if t.Before(p.Start) {
+			continue
+		}
+		if p.End.IsZero() || !t.After(p.End) {
+			return true
+		}
+	}
+	return false
 }
 
 // Annotation is a developer-provided note attached to a session.
@@ -21,6 +91,43 @@ type Annotation struct {
 	Timestamp time.Time `json:"timestamp"`
 	Message   string    `json:"message"`
 	IsSummary bool      `json:"is_summary"` // true when added via stop -m
+	// Path and Line anchor the note to a location in the code (optional).
+	Path string `json:"path,omitempty"`
+	Line int    `json:"line,omitempty"`
+	// LogExcerpt holds the tail of a build or test log attached with
+	// note --log (optional).
+	LogExcerpt string `json:"log_excerpt,omitempty"`
+	// Level is the annotation's severity: LevelInfo (also when empty),
+	// LevelWarn or LevelBlocker.
+	Level string `json:"level,omitempty"`
+}
+
+// Annotation severity levels, set with note --level.
+const (
+	LevelInfo    = "info"
+	LevelWarn    = "warn"
+	LevelBlocker = "blocker"
+)
+
+// Levels lists the valid annotation levels, mildest first.
+var Levels = []string{LevelInfo, LevelWarn, LevelBlocker}
+
+// IsBlocker reports whether the annotation was marked as a blocker.
+func (a Annotation) IsBlocker() bool {
+	return a.Level == LevelBlocker
+}
+
+// Location formats the annotation's anchor as "path:line", with path made
+// relative to workDir when it lies inside it. Returns "" for unanchored notes.
+func (a Annotation) Location(workDir string) string {
+	if a.Path == "" {
+		return ""
+	}
+	loc, _ := RelPath(a.Path, workDir)
+	if a.Line > 0 {
+		loc += ":" + strconv.Itoa(a.Line)
+	}
+	return loc
 }
 
 // FileEdit records a single file modification event.
@@ -29,3 +136,32 @@ type FileEdit struct {
 	Timestamp time.Time `json:"timestamp"`
 	Diff      string    `json:"diff,omitempty"` // unified diff captured at session stop
 }
+
+// Command is a single terminal command from shell history, as listed in a
+// bundle.
+type Command struct {
+	Raw       string    `json:"raw"`
+	Timestamp time.Time `json:"timestamp"` // zero if shell doesn't record timestamps
+	// Count and LastTimestamp are set by bundle.DedupeCommands when the command
+	// ran more than once: Timestamp is then the first run, LastTimestamp
+	// the last.
+	Count         int       `json:"count,omitempty"`
+	LastTimestamp time.Time `json:"last_timestamp,omitzero"`
+	// ExitCode is the command's exit status, when the shell plugin logged
+	// it; after bundle.DedupeCommands, that of the last run.
+	ExitCode *int `json:"exit_code,omitempty"`
+}
+
+// Failed reports whether the command is known to have exited non-zero.
+func (c Command) Failed() bool {
+	return c.ExitCode != nil && *c.ExitCode != 0
+}
+
+// Label returns the command line, with "×N" appended when it stands for N
+// runs.
+func (c Command) Label() string {
+	if c.Count > 1 {
+		return fmt.Sprintf("%s ×%d", c.Raw, c.Count)
+	}
+	return c.Raw
+}
@@ -6,15 +6,43 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
 )
 
 // ErrNoSession is returned by Load when no session file exists on disk.
 var ErrNoSession = errors.New("no active session")
 
+// DefaultSessionName is the reserved name used when no --name is given.
+// The default session is stored in session.json for compatibility with
+// existing installs and shell plugins.
+const DefaultSessionName = "default"
+
+// validSessionName restricts names to characters that are safe in a filename.
+var validSessionName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
 // SessionStore persists a Session to disk.
+//
+// The file watcher and the CLI commands all modify the same session file, so
+// changes must go through Update: it holds an exclusive advisory lock (flock
+// on Unix, LockFileEx on Windows) across load, modify and save, which keeps
+// a note added while the watcher flushes edits from being overwritten. Save
+// takes the same lock but replaces the session wholesale. Load needs no lock:
+// saves replace the file with an atomic rename, so readers never see a
+// partial write.
 type SessionStore interface {
 	Save(s *Session) error
 	Load() (*Session, error) // returns ErrNoSession if none exists
+	// Update loads the session, applies fn and saves the result, all under
+	// the store's lock. Nothing is saved if fn returns an error. Returns
+	// ErrNoSession if none exists.
+	Update(fn func(*Session) error) error
+	// Take loads the session and keeps the store's lock until release is
+	// called, so the session can't change while the caller is done with
+	// it, as stop builds a bundle from it and then deletes it. Save and
+	// Update wait until then. Returns ErrNoSession if none exists.
+	Take() (s *Session, release func(), err error)
 	Delete() error
 }
 
@@ -23,21 +51,91 @@ type diskStore struct {
 	path string // full path to session.json
 }
 
-// NewSessionStore returns a SessionStore backed by the XDG data directory.
+// lock takes the store's exclusive lock, blocking until it is free, and
+// returns a function that releases it. The lock is held on a separate
+// <session file>.lock file, because saves replace the session file itself.
+// The lock file is left in place afterwards: deleting it could let two
+// processes hold "the" lock on different files.
+func (d *diskStore) lock() (unlock func(), err error) {
+	f, err := os.OpenFile(d.path+".lock", os.O_CREATE|os.O_RDWR, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session state: %w", err)
+	}
+	if err := lockFile(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to lock session state: %w", err)
+	}
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
+
+// NewSessionStore returns a SessionStore for the default session, backed by
+// the XDG data directory.
 // Path: $XDG_DATA_HOME/handoff/session.json or ~/.local/share/handoff/session.json
 func NewSessionStore() (SessionStore, error) {
-	dir, err := dataDir()
+	return NewNamedSessionStore("")
+}
+
+// NewNamedSessionStore returns a SessionStore for the session called name,
+// stored as session-<name>.json next to the default session file. An empty
+// name (or DefaultSessionName) selects the default session.
+func NewNamedSessionStore(name string) (SessionStore, error) {
+	if name != "" && !validSessionName.MatchString(name) {
+		return nil, fmt.Errorf("invalid session name %q: use letters, digits, '.', '_' or '-'", name)
+	}
+	dir, err := DataDir()
 	if err != nil {
 		return nil, fmt.Errorf("resolving data directory: %w", err)
 	}
 	if err := os.MkdirAll(dir, 0o755); err != nil {
 		return nil, fmt.Errorf("creating data directory: %w", err)
 	}
-	return &diskStore{path: filepath.Join(dir, "session.json")}, nil
+	return &diskStore{path: filepath.Join(dir, sessionFileName(name))}, nil
+}
+
+// ListSessions returns the names of all sessions currently persisted on disk,
+// sorted alphabetically. The default session is reported as DefaultSessionName.
+func ListSessions() ([]string, error) {
+	dir, err := DataDir()
+	if err != nil {
+		return nil, fmt.Errorf("resolving data directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		switch n := e.Name(); {
+		case n == "session.json":
+			names = append(names, DefaultSessionName)
+		case strings.HasPrefix(n, "session-") && strings.HasSuffix(n, ".json"):
+			names = append(names, strings.TrimSuffix(strings.TrimPrefix(n, "session-"), ".json"))
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// sessionFileName maps a session name to its file name in the data directory.
+func sessionFileName(name string) string {
+	if name == "" || name == DefaultSessionName {
+		return "session.json"
+	}
+	return "session-" + name + ".json"
 }
 
 // dataDir returns the handoff-specific XDG data directory.
-func dataDir() (string, error) {
+func DataDir() (string, error) {
 	base := os.Getenv("XDG_DATA_HOME")
 	if base == "" {
 		home, err := os.UserHomeDir()
@@ -51,6 +149,48 @@ func dataDir() (string, error) {
 
 // Save marshals s to JSON and writes it atomically via a temp file + os.Rename.
 func (d *diskStore) Save(s *Session) error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return d.save(s)
+}
+
+// Update implements SessionStore.
+func (d *diskStore) Update(fn func(*Session) error) error {
+	unlock, err := d.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	s, err := d.Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(s); err != nil {
+		return err
+	}
+	return d.save(s)
+}
+
+// Take implements SessionStore.
+func (d *diskStore) Take() (*Session, func(), error) {
+	unlock, err := d.lock()
+	if err != nil {
+		return nil, nil, err
+	}
+	s, err := d.Load()
+	if err != nil {
+		unlock()
+		return nil, nil, err
+	}
+	return s, unlock, nil
+}
+
+// save writes s without taking the lock; callers must hold it.
+func (d *diskStore) save(s *Session) (err error) {
 	data, err := json.Marshal(s)
 	if err != nil {
 		return fmt.Errorf("failed to persist session state: %w", err)
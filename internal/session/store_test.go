@@ -189,3 +189,202 @@ func TestSaveFailurePropagatesError(t *testing.T) {
 		t.Fatal("expected error creating store in unwritable directory, got nil")
 	}
 }
+
+// TestNamedSessionsAreIndependent verifies that named sessions are stored in
+// separate files, that ErrNoSession is reported per name, and that
+// ListSessions reports every active session.
+func TestNamedSessionsAreIndependent(t *testing.T) {
+	tmp := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", tmp)
+
+	def, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	featureX, err := session.NewNamedSessionStore("feature-x")
+	if err != nil {
+		t.Fatalf("NewNamedSessionStore: %v", err)
+	}
+
+	if err := featureX.Save(&session.Session{ID: "x", StartTime: time.Now()}); err != nil {
+		t.Fatalf("Save feature-x: %v", err)
+	}
+
+	// The default session is still absent.
+	if _, err := def.Load(); !errors.Is(err, session.ErrNoSession) {
+		t.Errorf("default Load: expected ErrNoSession, got %v", err)
+	}
+	// An unrelated name is also absent.
+	other, err := session.NewNamedSessionStore("other")
+	if err != nil {
+		t.Fatalf("NewNamedSessionStore: %v", err)
+	}
+	if _, err := other.Load(); !errors.Is(err, session.ErrNoSession) {
+		t.Errorf("other Load: expected ErrNoSession, got %v", err)
+	}
+
+	got, err := featureX.Load()
+	if err != nil {
+		t.Fatalf("feature-x Load: %v", err)
+	}
+	if got.ID != "x" {
+		t.Errorf("feature-x ID: got %q, want %q", got.ID, "x")
+	}
+
+	if err := def.Save(&session.Session{ID: "d", StartTime: time.Now()}); err != nil {
+		t.Fatalf("Save default: %v", err)
+	}
+	names, err := session.ListSessions()
+	if err != nil {
+		t.Fatalf("ListSessions: %v", err)
+	}
+	want := []string{session.DefaultSessionName, "feature-x"}
+	if len(names) != len(want) || names[0] != want[0] || names[1] != want[1] {
+		t.Errorf("ListSessions: got %v, want %v", names, want)
+	}
+
+	// Deleting one session leaves the other untouched.
+	if err := featureX.Delete(); err != nil {
+		t.Fatalf("Delete feature-x: %v", err)
+	}
+	if _, err := def.Load(); err != nil {
+		t.Errorf("default Load after deleting feature-x: %v", err)
+	}
+}
+
+// TestNamedSessionStoreRejectsInvalidNames verifies that names which could
+// escape the data directory are rejected.
+func TestNamedSessionStoreRejectsInvalidNames(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	for _, name := range []string{"../evil", "a/b", ".hidden", "has space"} {
+		if _, err := session.NewNamedSessionStore(name); err == nil {
+			t.Errorf("expected error for session name %q, got nil", name)
+		}
+	}
+}
+
+// TestConcurrentUpdatesKeepBothChanges interleaves note-adds with watcher-
+// style edit flushes through separate stores, as the CLI and the watcher
+// process do, and checks that no update is lost, including one racing stop.
+func TestConcurrentUpdatesKeepBothChanges(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	cli, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	watcher, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	if err := cli.Save(&session.Session{ID: "test-id", StartTime: time.Now()}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+
+	// Hold the watcher's update open until the note-add has started, so
+	// the two really overlap.
+	inFlush := make(chan struct{})
+	noteDone := make(chan error)
+	go func() {
+		<-inFlush
+		noteDone <- cli.Update(func(s *session.Session) error {
+			s.Annotations = append(s.Annotations, session.Annotation{Message: "during flush"})
+			return nil
+		})
+	}()
+	err = watcher.Update(func(s *session.Session) error {
+		close(inFlush)
+		time.Sleep(50 * time.Millisecond) // the note-add must wait for us
+		s.FileEdits = append(s.FileEdits, session.FileEdit{Path: "flushed.go"})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("watcher Update: %v", err)
+	}
+	if err := <-noteDone; err != nil {
+		t.Fatalf("note Update: %v", err)
+	}
+
+	// Then many small updates from both sides at once.
+	const n = 50
+	errs := make(chan error, 2*n)
+	for i := 0; i < n; i++ {
+		go func() {
+			errs <- cli.Update(func(s *session.Session) error {
+				s.Annotations = append(s.Annotations, session.Annotation{Message: "note"})
+				return nil
+			})
+		}()
+		go func() {
+			errs <- watcher.Update(func(s *session.Session) error {
+				s.FileEdits = append(s.FileEdits, session.FileEdit{Path: "edit.go"})
+				return nil
+			})
+		}()
+	}
+	for i := 0; i < 2*n; i++ {
+		if err := <-errs; err != nil {
+			t.Fatalf("Update: %v", err)
+		}
+	}
+
+	s, err := cli.Load()
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(s.Annotations) != n+1 || len(s.FileEdits) != n+1 {
+		t.Errorf("got %d annotations and %d file edits, want %d of each", len(s.Annotations), len(s.FileEdits), n+1)
+	}
+
+	// Last, stop: a note added while stop holds the session waits for it,
+	// then finds the session gone instead of being silently dropped.
+	taken, release, err := cli.Take()
+	if err != nil {
+		t.Fatalf("Take: %v", err)
+	}
+	go func() {
+		noteDone <- watcher.Update(func(s *session.Session) error {
+			s.Annotations = append(s.Annotations, session.Annotation{Message: "during stop"})
+			return nil
+		})
+	}()
+	time.Sleep(50 * time.Millisecond) // the note-add must wait for us
+	if len(taken.Annotations) != n+1 {
+		t.Errorf("taken session has %d annotations, want %d", len(taken.Annotations), n+1)
+	}
+	if err := cli.Delete(); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	release()
+	if err := <-noteDone; !errors.Is(err, session.ErrNoSession) {
+		t.Errorf("note Update after stop: got %v, want ErrNoSession", err)
+	}
+}
+
+// TestUpdateErrorSavesNothing verifies that an error from the update function
+// leaves the session as it was, and that Update reports a missing session.
+func TestUpdateErrorSavesNothing(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+	store, err := session.NewSessionStore()
+	if err != nil {
+		t.Fatalf("NewSessionStore: %v", err)
+	}
+	noop := func(*session.Session) error { return nil }
+	if err := store.Update(noop); !errors.Is(err, session.ErrNoSession) {
+		t.Errorf("Update without a session: got %v, want ErrNoSession", err)
+	}
+
+	if err := store.Save(&session.Session{ID: "test-id"}); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	boom := errors.New("boom")
+	err = store.Update(func(s *session.Session) error {
+		s.ID = "changed"
+		return boom
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("Update: got %v, want %v", err, boom)
+	}
+	if s, _ := store.Load(); s == nil || s.ID != "test-id" {
+		t.Errorf("session changed despite the error: %+v", s)
+	}
+}
@@ -2,6 +2,7 @@ package shell
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strconv"
@@ -25,13 +26,45 @@ func CommandLogPath() (string, error) {
 }
 
 // ReadCommandLog reads all entries from the command log and returns them as
-// bundle.Command values with accurate timestamps.
-// Format per line: <epoch>\t<command>
+// bundle.Command values with accurate timestamps. The log is left in place.
+// Format per line: <epoch>\t<exit>\t<command>, or <epoch>\t<command> from
+// plugins that can't see the exit status (PowerShell, and older installs).
 func ReadCommandLog() ([]bundle.Command, error) {
 	path, err := CommandLogPath()
 	if err != nil {
 		return nil, err
 	}
+	return readCommandLogFile(path)
+}
+
+// takeSettleDelay is how long TakeCommandLog waits after renaming the log
+// before reading it, so a plugin write that opened the old log just before
+// the rename has landed.
+const takeSettleDelay = 10 * time.Millisecond
+
+// TakeCommandLog reads and removes the command log in one step. The log is
+// first renamed aside, so commands the shell plugin appends while it is
+// being read go to a fresh log (the plugin's >> recreates it) instead of
+// being lost when the old one is cleared.
+func TakeCommandLog() ([]bundle.Command, error) {
+	path, err := CommandLogPath()
+	if err != nil {
+		return nil, err
+	}
+	taken := fmt.Sprintf("%s.%d.taken", path, os.Getpid())
+	if err := os.Rename(path, taken); err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil // no log yet — not an error
+		}
+		return nil, err
+	}
+	defer os.Remove(taken)
+	time.Sleep(takeSettleDelay)
+	return readCommandLogFile(taken)
+}
+
+// readCommandLogFile parses the command log at path.
+func readCommandLogFile(path string) ([]bundle.Command, error) {
 	f, err := os.Open(path)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -51,29 +84,24 @@ func ReadCommandLog() ([]bundle.Command, error) {
 		}
 		epochStr := line[:tab]
 		raw := line[tab+1:]
-		if raw == "" {
-			continue
-		}
 		epoch, err := strconv.ParseInt(epochStr, 10, 64)
 		if err != nil {
 			continue
 		}
+		var exitCode *int
+		if field, rest, ok := strings.Cut(raw, "\t"); ok {
+			if code, err := strconv.Atoi(field); err == nil {
+				exitCode, raw = &code, rest
+			}
+		}
+		if raw == "" {
+			continue
+		}
 		cmds = append(cmds, bundle.Command{
 			Raw:       raw,
 			Timestamp: time.Unix(epoch, 0),
+			ExitCode:  exitCode,
 		})
 	}
 	return cmds, scanner.Err()
 }
-
-// TruncateCommandLog empties the command log after a session is stopped.
-func TruncateCommandLog() error {
-	path, err := CommandLogPath()
-	if err != nil {
-		return err
-	}
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return nil
-	}
-	return os.WriteFile(path, nil, 0o644)
-}
@@ -0,0 +1,26 @@
+package shell
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Detect returns the name of the user's shell: "bash", "zsh", "fish",
+// "powershell", or the base name of any other shell. It reads $SHELL, then
+// $COMSPEC, which is what Windows sets instead. It returns "" when neither
+// is set.
+func Detect() string {
+	for _, env := range []string{"SHELL", "COMSPEC"} {
+		path := os.Getenv(env)
+		if path == "" {
+			continue
+		}
+		name := strings.TrimSuffix(strings.ToLower(filepath.Base(path)), ".exe")
+		if name == "pwsh" || name == "powershell" {
+			return "powershell"
+		}
+		return name
+	}
+	return ""
+}
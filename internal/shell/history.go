@@ -0,0 +1,81 @@
+package shell
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// HistoryAppendLine returns the rc-file line that makes shell write each
+// command to its history file as it runs, rather than when the shell exits,
+// or "" for shells that need no such line. The bash line keeps any
+// PROMPT_COMMAND already set, including the plugin's hook.
+func HistoryAppendLine(shell string) string {
+	switch shell {
+	case "zsh":
+		return "setopt INC_APPEND_HISTORY"
+	case "bash":
+		return `PROMPT_COMMAND="history -a${PROMPT_COMMAND:+; $PROMPT_COMMAND}"`
+	}
+	return ""
+}
+
+// HasHistoryAppend reports whether shell's rc file already makes history be
+// written as commands run, by HistoryAppendLine's setting or an equivalent
+// (zsh's SHARE_HISTORY, in any of the spellings zsh accepts). checked is
+// false when shell has no rc file handoff knows of.
+func HasHistoryAppend(shell string) (present, checked bool) {
+	rc := rcPath(shell)
+	if rc == "" || HistoryAppendLine(shell) == "" {
+		return false, false
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		return false, true
+	}
+	for line := range strings.Lines(string(data)) {
+		line, _, _ = strings.Cut(line, "#")
+		switch shell {
+		case "zsh":
+			opt := strings.ToLower(strings.ReplaceAll(line, "_", ""))
+			if strings.Contains(opt, "setopt") &&
+				(strings.Contains(opt, "incappendhistory") || strings.Contains(opt, "sharehistory")) {
+				return true, true
+			}
+		case "bash":
+			if strings.Contains(line, "history -a") {
+				return true, true
+			}
+		}
+	}
+	return false, true
+}
+
+// AppendHistoryLine adds HistoryAppendLine to shell's rc file, creating the
+// file if needed. It does nothing, and reports false, when the rc file
+// already has the setting.
+func AppendHistoryLine(shell string) (added bool, err error) {
+	line := HistoryAppendLine(shell)
+	rc := rcPath(shell)
+	if line == "" || rc == "" {
+		return false, fmt.Errorf("no history setting known for %s", shell)
+	}
+	if present, _ := HasHistoryAppend(shell); present {
+		return false, nil
+	}
+
+	f, err := os.OpenFile(rc, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	// Start on a fresh line even if the file doesn't end with one.
+	prefix := ""
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		prefix = "\n"
+	}
+	if _, err := fmt.Fprintf(f, "%s# Added by handoff: save each command to history as it runs.\n%s\n", prefix, line); err != nil {
+		return false, err
+	}
+	return true, f.Close()
+}
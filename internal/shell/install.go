@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 )
 
 // PluginPath returns the path where the plugin file should be written.
@@ -14,6 +15,9 @@ func PluginPath(shell string) (string, error) {
 		return "", err
 	}
 	name := "handoff.plugin." + shell
+	if shell == "powershell" {
+		name = "handoff.plugin.ps1" // PowerShell only runs .ps1 scripts
+	}
 	return filepath.Join(home, ".config", "handoff", name), nil
 }
 
@@ -34,8 +38,10 @@ func Install(shell string) error {
 		content = ZshPlugin
 	case "bash":
 		content = BashPlugin
+	case "powershell":
+		content = PowerShellPlugin
 	default:
-		return fmt.Errorf("unsupported shell for plugin: %s (supported: zsh, bash)", shell)
+		return fmt.Errorf("unsupported shell for plugin: %s (supported: zsh, bash, powershell)", shell)
 	}
 
 	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
@@ -43,10 +49,19 @@ func Install(shell string) error {
 	}
 
 	rcFile := rcFileName(shell)
+	source := "source"
+	if shell == "powershell" {
+		source = "." // dot-sourcing
+	}
 	fmt.Printf("\n  ✓ Plugin written to %s\n", path)
 	fmt.Printf("\n  Add this line to your %s:\n", rcFile)
-	fmt.Printf("    source %s\n", path)
-	fmt.Printf("\n  Then reload: source %s\n\n", rcFile)
+	fmt.Printf("    %s %s\n", source, path)
+	if present, checked := HasHistoryAppend(shell); checked && !present {
+		fmt.Printf("\n  To record commands as they run, also add:\n")
+		fmt.Printf("    %s\n", HistoryAppendLine(shell))
+		fmt.Printf("  (or run: handoff setup --append-history)\n")
+	}
+	fmt.Printf("\n  Then reload: %s %s\n\n", source, rcFile)
 	return nil
 }
 
@@ -60,12 +75,49 @@ func IsInstalled(shell string) bool {
 	return err == nil
 }
 
+// IsSourced reports whether the shell's rc file loads the plugin. checked is
+// false when there is no rc file to look at, as for PowerShell, whose profile
+// path only PowerShell itself knows.
+func IsSourced(shell string) (sourced, checked bool) {
+	rc := rcPath(shell)
+	if rc == "" {
+		return false, false
+	}
+	data, err := os.ReadFile(rc)
+	if err != nil {
+		return false, true
+	}
+	return strings.Contains(string(data), "handoff.plugin."+shell), true
+}
+
+// rcPath returns the path of shell's rc file, or "" for shells without one
+// handoff knows of.
+func rcPath(shell string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	switch shell {
+	case "zsh":
+		dir := os.Getenv("ZDOTDIR")
+		if dir == "" {
+			dir = home
+		}
+		return filepath.Join(dir, ".zshrc")
+	case "bash":
+		return filepath.Join(home, ".bashrc")
+	}
+	return ""
+}
+
 func rcFileName(shell string) string {
 	switch shell {
 	case "zsh":
 		return "~/.zshrc"
 	case "bash":
 		return "~/.bashrc"
+	case "powershell":
+		return "$PROFILE"
 	default:
 		return "~/." + shell + "rc"
 	}
@@ -1,20 +1,59 @@
 package shell
 
-// BashPlugin is the bash plugin source. It prepends a DEBUG trap that logs
-// commands with epoch timestamps when a handoff session is active.
+// BashPlugin is the bash plugin source. It installs a DEBUG trap that notes
+// each command line with an epoch timestamp when a handoff session is
+// active, and logs it with its exit status once the prompt comes back.
 const BashPlugin = `# handoff shell plugin — auto-generated, do not edit manually
 # Source this file from your ~/.bashrc:
 #   source ~/.config/handoff/handoff.plugin.bash
 
 _handoff_log_file="${XDG_DATA_HOME:-$HOME/.local/share}/handoff/commands.log"
-_handoff_session_file="${XDG_DATA_HOME:-$HOME/.local/share}/handoff/session.json"
+_handoff_data_dir="${XDG_DATA_HOME:-$HOME/.local/share}/handoff"
+
+# _handoff_flush logs the pending command with exit status $1.
+_handoff_flush() {
+  [[ -n "$_handoff_pending_cmd" ]] || return
+  printf '%s\t%s\t%s\n' "$_handoff_pending_ts" "$1" "$_handoff_pending_cmd" >> "$_handoff_log_file"
+  _handoff_pending_cmd=
+}
 
 _handoff_preexec() {
-  [[ -f "$_handoff_session_file" ]] || return
+  # $? is still the previous command's status here.
+  local exit_code=$?
   local cmd="$BASH_COMMAND"
+  # The trap also fires for what PROMPT_COMMAND runs, such as
+  # _handoff_precmd itself or "history -a"; those aren't the user's. The
+  # first of them runs right after the command line, so $? is its status.
+  if [[ ";${PROMPT_COMMAND//[[:space:]]/};" == *";${cmd//[[:space:]]/};"* ]]; then
+    _handoff_flush "$exit_code"
+    return
+  fi
+  # Only the first command after a prompt is noted: the rest of the line
+  # (a pipeline or list) belongs to the same entry. Nothing is logged
+  # before the first prompt, while ~/.bashrc still runs.
+  [[ -n "$_handoff_at_prompt" ]] || return
+  _handoff_at_prompt=
+  # Any session file (default or named) means a session is active.
+  compgen -G "$_handoff_data_dir/session*.json" > /dev/null || return
+  # The whole line is the newest history entry, unless history left it out
+  # (ignoredups, ignorespace); then only its first command is known.
+  local entry
+  entry="$(HISTTIMEFORMAT= builtin history 1)"
+  if [[ "$entry" =~ ^[[:space:]]*([0-9]+)[*]?[[:space:]]+(.*)$ && "${BASH_REMATCH[1]}" != "$_handoff_hist_num" ]]; then
+    cmd="${BASH_REMATCH[2]}"
+  fi
   [[ "$cmd" =~ ^[[:space:]]*(.*\/)?handoff[[:space:]]+(start|stop) ]] && return
-  printf '%s\t%s\n' "$(date +%s)" "$cmd" >> "$_handoff_log_file"
+  _handoff_pending_ts="$(date +%s)"
+  _handoff_pending_cmd="$cmd"
+}
+
+_handoff_precmd() {
+  _handoff_at_prompt=1
+  local entry
+  entry="$(HISTTIMEFORMAT= builtin history 1)"
+  [[ "$entry" =~ ^[[:space:]]*([0-9]+) ]] && _handoff_hist_num="${BASH_REMATCH[1]}"
 }
 
+[[ "$PROMPT_COMMAND" == *_handoff_precmd* ]] || PROMPT_COMMAND="_handoff_precmd${PROMPT_COMMAND:+;$PROMPT_COMMAND}"
 trap '_handoff_preexec' DEBUG
 `
@@ -0,0 +1,27 @@
+package shell
+
+// PowerShellPlugin is the PowerShell plugin source. It registers a PSReadLine
+// history handler that logs every command with an epoch timestamp to the
+// handoff commands log, but only when a handoff session is active.
+const PowerShellPlugin = `# handoff shell plugin — auto-generated, do not edit manually
+# Dot-source this file from your PowerShell profile ($PROFILE):
+#   . ~/.config/handoff/handoff.plugin.ps1
+
+$global:_handoffDataDir = if ($env:XDG_DATA_HOME) { Join-Path $env:XDG_DATA_HOME 'handoff' } else { Join-Path $HOME '.local/share/handoff' }
+$global:_handoffLogFile = Join-Path $global:_handoffDataDir 'commands.log'
+
+Set-PSReadLineOption -AddToHistoryHandler {
+  param([string]$line)
+  # Only log when a session (default or named) is active.
+  if (Test-Path (Join-Path $global:_handoffDataDir 'session*.json')) {
+    # Skip handoff start/stop noise.
+    if ($line -notmatch '^\s*(.*[\\/])?handoff(\.exe)?\s+(start|stop)') {
+      $epoch = [DateTimeOffset]::UtcNow.ToUnixTimeSeconds()
+      $cmd = $line -replace '\r?\n', ' '
+      # AppendAllText writes UTF-8 without a byte order mark.
+      [IO.File]::AppendAllText($global:_handoffLogFile, "$epoch` + "`" + `t$cmd` + "`" + `n")
+    }
+  }
+  return $true
+}
+`
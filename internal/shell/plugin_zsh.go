@@ -1,24 +1,36 @@
 package shell
 
-// ZshPlugin is the zsh plugin source. It installs a preexec hook that logs
-// every command with an epoch timestamp to the handoff commands log, but only
-// when a handoff session is active.
+// ZshPlugin is the zsh plugin source. It installs a preexec hook that notes
+// every command with an epoch timestamp, and a precmd hook that logs it with
+// its exit status to the handoff commands log, but only when a handoff
+// session is active.
 const ZshPlugin = `# handoff shell plugin — auto-generated, do not edit manually
 # Source this file from your ~/.zshrc:
 #   source ~/.config/handoff/handoff.plugin.zsh
 
 _handoff_log_file="${XDG_DATA_HOME:-$HOME/.local/share}/handoff/commands.log"
-_handoff_session_file="${XDG_DATA_HOME:-$HOME/.local/share}/handoff/session.json"
+_handoff_data_dir="${XDG_DATA_HOME:-$HOME/.local/share}/handoff"
 
 _handoff_preexec() {
-  # Only log when a session is active.
-  [[ -f "$_handoff_session_file" ]] || return
+  # Only log when a session (default or named) is active.
+  local -a _handoff_sessions
+  _handoff_sessions=("$_handoff_data_dir"/session*.json(N))
+  (( ${#_handoff_sessions} )) || return
   local cmd="$1"
   # Skip handoff start/stop noise.
   [[ "$cmd" =~ ^[[:space:]]*(.*\/)?handoff[[:space:]]+(start|stop) ]] && return
-  printf '%s\t%s\n' "$(date +%s)" "$cmd" >> "$_handoff_log_file"
+  _handoff_pending_ts="$(date +%s)"
+  _handoff_pending_cmd="$cmd"
+}
+
+_handoff_precmd() {
+  local exit_code=$?
+  [[ -n "$_handoff_pending_cmd" ]] || return
+  printf '%s\t%s\t%s\n' "$_handoff_pending_ts" "$exit_code" "$_handoff_pending_cmd" >> "$_handoff_log_file"
+  _handoff_pending_cmd=
 }
 
 autoload -Uz add-zsh-hook
 add-zsh-hook preexec _handoff_preexec
+add-zsh-hook precmd _handoff_precmd
 `
@@ -0,0 +1,59 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/x/ansi"
+)
+
+// diffScrollStep is how many columns left/right scroll a diff.
+const diffScrollStep = 8
+
+// diffContinuation starts the second and later rows of a wrapped diff line.
+const diffContinuation = "    ↪ "
+
+// diffLayout fits rendered diff lines to the screen, either soft-wrapping
+// long lines or cutting out a window of them at a horizontal offset. Cutting
+// works on the styled text, so colors carry over to every segment.
+type diffLayout struct {
+	wrap   bool
+	offset int // first column shown past the gutter, when not wrapping
+	// widest is the widest line seen, set as lines are fitted
+	widest int
+	theme  *Theme
+}
+
+// fit returns line as one or more rows at most width columns wide. The first
+// gutter columns (indent and +/- marker) stay put while scrolling.
+func (l *diffLayout) fit(line string, gutter, width int) []string {
+	limit := max(width-2, gutter+1)
+	w := ansi.StringWidth(line)
+	l.widest = max(l.widest, w)
+	if w <= limit && l.offset == 0 {
+		return []string{line}
+	}
+	if !l.wrap {
+		return []string{ansi.Cut(line, 0, gutter) + ansi.Cut(line, gutter+l.offset, gutter+l.offset+limit-gutter)}
+	}
+	rows := []string{ansi.Cut(line, 0, limit)}
+	step := max(limit-len(diffContinuation), 1)
+	for pos := limit; pos < w; pos += step {
+		rows = append(rows, l.theme.Dim.Render(diffContinuation)+ansi.Cut(line, pos, pos+step))
+	}
+	return rows
+}
+
+// block fits each line of a rendered multi-line block.
+func (l *diffLayout) block(s string, gutter, width int) string {
+	lines := strings.Split(s, "\n")
+	var out []string
+	for _, line := range lines {
+		out = append(out, l.fit(line, gutter, width)...)
+	}
+	return strings.Join(out, "\n")
+}
+
+// overflows reports whether the widest line fitted so far runs past width.
+func (l *diffLayout) overflows(width int) bool {
+	return l.widest > width-2
+}
@@ -0,0 +1,82 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// helpSection is one group of keys in the help overlay.
+type helpSection struct {
+	title string
+	keys  [][2]string // key, description
+}
+
+// helpSections lists every key binding, grouped by where it applies. Keep in
+// sync with Update.
+var helpSections = []helpSection{
+	{"Global", [][2]string{
+		{"←/→  tab  h/l", "previous / next tab"},
+		{"1-8", "jump to tab"},
+		{":  ctrl+k", "jump to a tab, file, note or command"},
+		{"↑/↓  pgup/pgdn", "scroll"},
+		{"y", "copy the tab to the clipboard"},
+		{"r", "clock times / times relative to stop"},
+		{"ctrl+j", "show the bundle's raw JSON"},
+		{"?", "toggle this help"},
+		{"q", "quit"},
+	}},
+	{"Annotations", [][2]string{
+		{"↑/↓", "select annotation"},
+		{"enter", "show / hide attached log"},
+		{"e", "edit annotation"},
+		{"d", "delete annotation"},
+		{"w", "write changes to the bundle file"},
+	}},
+	{"File Edits", [][2]string{
+		{"↑/↓", "select file"},
+		{"enter", "expand / collapse diff and notes"},
+		{"E / C", "expand all diffs / collapse all"},
+		{"o", "open file in $EDITOR"},
+		{"a", "abbreviated / full paths"},
+		{"w", "wrap / scroll long diff lines"},
+		{"←/→", "scroll long diff lines (when not wrapping)"},
+	}},
+	{"Git", [][2]string{
+		{"w", "wrap / scroll long diff lines"},
+		{"←/→", "scroll long diff lines (when not wrapping)"},
+	}},
+	{"Editor Tabs", [][2]string{
+		{"a", "abbreviated / full paths"},
+	}},
+	{"Timeline", [][2]string{
+		{"s", "sort newest / oldest first"},
+		{"f / u", "filter from / until a time"},
+		{"c", "clear time filter"},
+	}},
+}
+
+// renderHelp returns the help overlay centered in a width×height screen.
+func (t *Theme) renderHelp(width, height int) string {
+	keyWidth := 0
+	for _, sec := range helpSections {
+		for _, k := range sec.keys {
+			keyWidth = max(keyWidth, lipgloss.Width(k[0]))
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString(t.Title.Render("Keyboard shortcuts") + "\n")
+	for _, sec := range helpSections {
+		sb.WriteString("\n" + t.Section.Render(sec.title) + "\n")
+		for _, k := range sec.keys {
+			key := t.Label.Render(fmt.Sprintf("%-*s", keyWidth, k[0]))
+			sb.WriteString("  " + key + "  " + k[1] + "\n")
+		}
+	}
+	sb.WriteString("\n" + t.Dim.Render("? or esc to close"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		t.HelpBox.Render(sb.String()))
+}
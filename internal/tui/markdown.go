@@ -0,0 +1,50 @@
+package tui
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/charmbracelet/glamour"
+)
+
+// newMarkdownRenderer returns a glamour renderer for annotation messages in
+// the theme's Markdown styles, wrapping at width. The document margin and
+// surrounding blank lines are dropped so a message can sit beside its badge.
+func (t *Theme) newMarkdownRenderer(width int) *glamour.TermRenderer {
+	if width < 20 {
+		width = 20
+	}
+	style := t.Markdown
+	style.Document.Margin = nil
+	style.Document.BlockPrefix, style.Document.BlockSuffix = "", ""
+	r, err := glamour.NewTermRenderer(glamour.WithStyles(style), glamour.WithWordWrap(width))
+	if err != nil {
+		return nil
+	}
+	return r
+}
+
+// renderMarkdown renders text with r as terminal lines. It falls back to the
+// plain text if r is nil or fails, and always returns at least one line.
+func renderMarkdown(r *glamour.TermRenderer, text string) []string {
+	out := text
+	if r != nil {
+		if rendered, err := r.Render(text); err == nil {
+			out = rendered
+		}
+	}
+	lines := strings.Split(strings.Trim(out, "\n"), "\n")
+	for i, line := range lines {
+		// glamour pads every line to the wrap width with styled spaces.
+		if trimmed := mdPadding.ReplaceAllString(line, ""); trimmed != line {
+			lines[i] = trimmed
+			if strings.Contains(trimmed, "\x1b[") {
+				lines[i] += "\x1b[0m"
+			}
+		}
+	}
+	return lines
+}
+
+// mdPadding matches the spaces and style sequences at the end of a line.
+var mdPadding = regexp.MustCompile("(\x1b\\[[0-9;]*m| )+$")
@@ -0,0 +1,227 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// paletteMaxRows caps how many matches the palette lists at once.
+const paletteMaxRows = 10
+
+// paletteScopes maps the optional first word of a palette query to the kind
+// of item it restricts the search to.
+var paletteScopes = map[string]string{
+	"file":   "file",
+	"note":   "note",
+	"cmd":    "cmd",
+	"editor": "editor",
+	"url":    "url",
+}
+
+// paletteItem is one jump target: a whole tab (index -1) or an item in it.
+type paletteItem struct {
+	kind  string // "tab" or a paletteScopes value
+	label string
+	tab   tabID
+	index int
+}
+
+// palette is the state of the `:` command palette overlay.
+type palette struct {
+	input   textinput.Model
+	items   []paletteItem
+	matches []paletteItem
+	cursor  int
+}
+
+// paletteItems lists every tab and every item the palette can jump to.
+func paletteItems(b *bundle.ContextBundle) []paletteItem {
+	var items []paletteItem
+	for t := tabID(0); t < tabCount; t++ {
+		items = append(items, paletteItem{kind: "tab", label: tabNames[t], tab: t, index: -1})
+	}
+	workDir := b.Session.WorkDir
+	for i, fe := range b.FileEdits {
+		items = append(items, paletteItem{"file", stripWorkDir(fe.Path, workDir), tabFileEdits, i})
+	}
+	for i, a := range b.Annotations {
+		msg, _, _ := strings.Cut(a.Message, "\n")
+		items = append(items, paletteItem{"note", msg, tabAnnotations, i})
+	}
+	for i, c := range b.Commands {
+		items = append(items, paletteItem{"cmd", c.Raw, tabCommands, i})
+	}
+	for i, path := range b.EditorTabs {
+		items = append(items, paletteItem{"editor", stripWorkDir(path, workDir), tabEditorTabs, i})
+	}
+	for i, t := range b.BrowserTabs {
+		items = append(items, paletteItem{"url", strings.TrimSpace(t.Title + " " + t.URL), tabBrowserTabs, i})
+	}
+	return items
+}
+
+// filter returns the items matching query, best first. A leading scope word
+// ("file invoice") restricts the search to that kind of item; every other
+// word must fuzzy-match the item's label.
+func (p *palette) filter(query string) []paletteItem {
+	scope := ""
+	if first, rest, ok := strings.Cut(strings.TrimLeft(query, " "), " "); ok {
+		if kind, known := paletteScopes[first]; known {
+			scope, query = kind, rest
+		}
+	}
+	words := strings.Fields(strings.ToLower(query))
+
+	type scored struct {
+		item  paletteItem
+		score int
+	}
+	var found []scored
+	for _, it := range p.items {
+		if scope != "" && it.kind != scope {
+			continue
+		}
+		total, ok := 0, true
+		label := strings.ToLower(it.label)
+		for _, w := range words {
+			s, matched := fuzzyScore(w, label)
+			if !matched {
+				ok = false
+				break
+			}
+			total += s
+		}
+		if ok {
+			found = append(found, scored{it, total})
+		}
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].score < found[j].score })
+
+	matches := make([]paletteItem, len(found))
+	for i, f := range found {
+		matches[i] = f.item
+	}
+	return matches
+}
+
+// fuzzyScore reports whether the letters of pattern appear in text in order,
+// and how good a match it is (lower is better): substrings beat scattered
+// letters, and earlier matches beat later ones.
+func fuzzyScore(pattern, text string) (int, bool) {
+	if i := strings.Index(text, pattern); i >= 0 {
+		return i, true
+	}
+	first, pos := -1, 0
+	for _, r := range pattern {
+		i := strings.IndexRune(text[pos:], r)
+		if i < 0 {
+			return 0, false
+		}
+		if first < 0 {
+			first = pos + i
+		}
+		pos += i + len(string(r))
+	}
+	return 1000 + pos - first, true
+}
+
+// openPalette shows the palette with an empty query.
+func (m *Model) openPalette() tea.Cmd {
+	m.palette = palette{input: textinput.New(), items: paletteItems(m.bundle)}
+	m.palette.input.Prompt = ": "
+	m.palette.input.Placeholder = "file, note, cmd, editor or url, then a search"
+	m.palette.matches = m.palette.filter("")
+	m.showPalette = true
+	return m.palette.input.Focus()
+}
+
+// updatePalette routes key presses to the open palette. Enter jumps to the
+// selected match, Esc closes it.
+func (m Model) updatePalette(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	p := &m.palette
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.showPalette = false
+		return m, nil
+	case "enter":
+		m.showPalette = false
+		if len(p.matches) > 0 {
+			m.jumpTo(p.matches[p.cursor])
+		}
+		return m, nil
+	case "up", "ctrl+p":
+		if p.cursor > 0 {
+			p.cursor--
+		}
+		return m, nil
+	case "down", "ctrl+n":
+		if p.cursor < min(len(p.matches), paletteMaxRows)-1 {
+			p.cursor++
+		}
+		return m, nil
+	}
+	var cmd tea.Cmd
+	p.input, cmd = p.input.Update(msg)
+	p.matches = p.filter(p.input.Value())
+	p.cursor = 0
+	return m, cmd
+}
+
+// jumpTo switches to the tab holding it and, for an item, selects it where
+// the tab has a cursor and scrolls it to the top of the view.
+func (m *Model) jumpTo(it paletteItem) {
+	m.activeTab = it.tab
+	if it.index < 0 {
+		return
+	}
+	switch it.tab {
+	case tabFileEdits:
+		m.editCursor = it.index
+		m.rebuildFileEditsViewport()
+	case tabAnnotations:
+		m.annCursor = it.index
+		m.rebuildAnnotationsViewport()
+	}
+	if lines := m.itemLines[it.tab]; it.index < len(lines) {
+		m.viewports[it.tab].SetYOffset(lines[it.index])
+	}
+}
+
+// renderPalette returns the palette overlay centered in a width×height
+// screen.
+func (m *Model) renderPalette(width, height int) string {
+	p := &m.palette
+	boxWidth := max(min(width-8, 80), 20)
+
+	var sb strings.Builder
+	sb.WriteString(p.input.View() + "\n\n")
+	if len(p.matches) == 0 {
+		sb.WriteString(m.theme.Dim.Render("  no matches") + "\n")
+	}
+	for i, it := range p.matches[:min(len(p.matches), paletteMaxRows)] {
+		kind := m.theme.Dim.Render(fmt.Sprintf("%-7s", it.kind))
+		label := ansi.Truncate(it.label, boxWidth-6-2-7-2, "…")
+		if it.index < 0 {
+			label = m.theme.Section.Render(label)
+		}
+		row := "  " + kind + "  " + label
+		if i == p.cursor {
+			row = m.theme.SelectedRow.Width(boxWidth - 6).Render(row)
+		}
+		sb.WriteString(row + "\n")
+	}
+	if more := len(p.matches) - paletteMaxRows; more > 0 {
+		sb.WriteString(m.theme.Dim.Render(fmt.Sprintf("  … %d more", more)) + "\n")
+	}
+	sb.WriteString("\n" + m.theme.Dim.Render("↑/↓ select  enter jump  esc close"))
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		m.theme.HelpBox.Width(boxWidth).Render(sb.String()))
+}
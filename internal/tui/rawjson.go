@@ -0,0 +1,52 @@
+package tui
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// openRawJSON shows the bundle as the JSON renderer would write it, for
+// checking what the viewer was actually given.
+func (m *Model) openRawJSON() {
+	data, err := (&bundle.JSONRenderer{}).Render(m.bundle)
+	if err != nil {
+		m.statusMsg = "render JSON: " + err.Error()
+		return
+	}
+	// Same rows as a tab: title, header and status bar around it.
+	m.rawView = viewport.New(m.width, max(m.height-3, 1))
+	m.rawView.SetHorizontalStep(diffScrollStep)
+	m.rawView.SetContent(string(data))
+	m.showRaw = true
+}
+
+// updateRawJSON routes key presses to the open raw JSON overlay: ctrl+j or
+// Esc close it, everything else scrolls.
+func (m Model) updateRawJSON(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "ctrl+j", "esc", "q":
+		m.showRaw = false
+		return m, nil
+	case "ctrl+c":
+		m.showRaw = false
+		return m.Update(msg)
+	}
+	var cmd tea.Cmd
+	m.rawView, cmd = m.rawView.Update(msg)
+	return m, cmd
+}
+
+// renderRawJSON draws the raw JSON overlay in place of the tabs.
+func (m *Model) renderRawJSON() string {
+	title := m.theme.Title.Width(m.width).Render("  handoff  " + m.filename + "  (raw JSON)")
+	header := m.theme.TabBar.Width(m.width).Render(m.theme.Dim.Render(" bundle data as written by --format json"))
+	hint := "  ↑/↓ scroll  ←/→ scroll sideways  esc or ctrl+j close"
+	right := lineRange(m.rawView)
+	pad := max(m.width-lipgloss.Width(hint)-lipgloss.Width(right)-2, 1)
+	status := m.theme.StatusBar.Width(m.width).Render(hint + fmt.Sprintf("%*s", pad, "") + right)
+	return lipgloss.JoinVertical(lipgloss.Left, title, header, m.rawView.View(), status)
+}
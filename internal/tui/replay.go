@@ -0,0 +1,192 @@
+package tui
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/fakeyudi/handoff/internal/bundle"
+)
+
+// ReplayModel steps through a bundle's timeline one event at a time, oldest
+// first, for walking someone through a session.
+type ReplayModel struct {
+	bundle   *bundle.ContextBundle
+	filename string
+	events   []timelineEvent
+	cur      int
+	// backward reverses the direction space/enter step in
+	backward bool
+	// jump collects typed digits until enter jumps to that event number
+	jump     string
+	viewport viewport.Model
+	width    int
+	height   int
+	ready    bool
+	theme    *Theme
+}
+
+// NewReplay creates a replay model for the given bundle and source filename,
+// drawn with theme.
+func NewReplay(b *bundle.ContextBundle, filename string, theme Theme) ReplayModel {
+	events := buildTimeline(b)
+	sort.SliceStable(events, func(i, j int) bool { return events[i].ts.Before(events[j].ts) })
+	return ReplayModel{
+		bundle:   b,
+		filename: filepath.Base(filename),
+		events:   events,
+		theme:    &theme,
+	}
+}
+
+func (m ReplayModel) Init() tea.Cmd { return nil }
+
+func (m ReplayModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		m.height = msg.Height
+		// title(1) + position(1) + statusBar(1) = 3 fixed rows
+		vpHeight := m.height - 3
+		if vpHeight < 1 {
+			vpHeight = 1
+		}
+		if !m.ready {
+			m.viewport = viewport.New(m.width, vpHeight)
+			m.ready = true
+		} else {
+			m.viewport.Width = m.width
+			m.viewport.Height = vpHeight
+		}
+		m.viewport.SetContent(m.renderEvent())
+		return m, nil
+
+	case tea.KeyMsg:
+		key := msg.String()
+		if len(key) == 1 && key[0] >= '0' && key[0] <= '9' {
+			m.jump += key
+			return m, nil
+		}
+		switch key {
+		case "q", "ctrl+c":
+			return m, tea.Quit
+		case "esc":
+			m.jump = ""
+			return m, nil
+		case "backspace":
+			if m.jump != "" {
+				m.jump = m.jump[:len(m.jump)-1]
+				return m, nil
+			}
+			m.goTo(m.cur - 1)
+		case "enter", " ":
+			if m.jump != "" {
+				n, _ := strconv.Atoi(m.jump)
+				m.jump = ""
+				m.goTo(n - 1)
+				break
+			}
+			if m.backward {
+				m.goTo(m.cur - 1)
+			} else {
+				m.goTo(m.cur + 1)
+			}
+		case "right", "l", "n":
+			m.goTo(m.cur + 1)
+		case "left", "h", "p":
+			m.goTo(m.cur - 1)
+		case "home", "g":
+			m.goTo(0)
+		case "end", "G":
+			m.goTo(len(m.events) - 1)
+		case "r":
+			m.backward = !m.backward
+		default:
+			var cmd tea.Cmd
+			m.viewport, cmd = m.viewport.Update(msg)
+			return m, cmd
+		}
+		return m, nil
+	}
+	return m, nil
+}
+
+// goTo moves to event i, clamped to the timeline.
+func (m *ReplayModel) goTo(i int) {
+	i = max(0, min(i, len(m.events)-1))
+	if i == m.cur {
+		return
+	}
+	m.cur = i
+	m.viewport.SetContent(m.renderEvent())
+	m.viewport.GotoTop()
+}
+
+func (m ReplayModel) View() string {
+	if !m.ready {
+		return "Loading…"
+	}
+
+	title := m.theme.Title.Width(m.width).Render("  handoff replay  " + m.filename)
+
+	position := "  no timestamped events in this session"
+	if len(m.events) > 0 {
+		ev := m.events[m.cur]
+		position = fmt.Sprintf("  Event %d/%d  %s", m.cur+1, len(m.events), m.theme.Time.Render(ev.ts.Format("2006-01-02 15:04:05")))
+		if m.cur > 0 {
+			position += m.theme.Dim.Render(fmt.Sprintf("  (+%s)", ev.ts.Sub(m.events[m.cur-1].ts).Round(time.Second)))
+		}
+	}
+	positionRow := lipgloss.NewStyle().Width(m.width).Render(position)
+
+	direction := "forward"
+	if m.backward {
+		direction = "backward"
+	}
+	hint := "  space/enter step (" + direction + ")  ←/→ prev/next  r reverse  g/G first/last  <n> enter jump  ↑/↓ scroll  q quit"
+	if m.jump != "" {
+		hint = "  jump to event " + m.jump + "  (enter to go, esc to cancel)"
+	}
+	statusBar := m.theme.StatusBar.Width(m.width).Render(hint)
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, positionRow, m.viewport.View(), statusBar)
+}
+
+// renderEvent renders the current event: its kind and text, plus the diff
+// for file edits and the command line for commands.
+func (m *ReplayModel) renderEvent() string {
+	if len(m.events) == 0 {
+		return ""
+	}
+	ev := m.events[m.cur]
+
+	var sb strings.Builder
+	sb.WriteString("\n" + m.theme.eventBadge(ev.kind) + "\n\n")
+	switch ev.kind {
+	case kindEdit:
+		sb.WriteString(m.theme.Label.Render("  "+ev.text) + "\n\n")
+		if ev.diff == "" {
+			sb.WriteString(m.theme.Dim.Render("  (no diff captured)") + "\n")
+		} else {
+			sb.WriteString(m.theme.renderDiff(strings.TrimRight(ev.diff, "\n"), m.width, &diffLayout{wrap: true, theme: m.theme}))
+		}
+	case kindCmd:
+		sb.WriteString("  " + m.theme.ReplayCommand.Render("$ "+ev.text) + "\n")
+	default:
+		sb.WriteString(indent(ev.text, "  ") + "\n")
+	}
+	return sb.String()
+}
+
+// RunReplay starts the replay TUI for the given bundle.
+func RunReplay(b *bundle.ContextBundle, filename string, theme Theme) error {
+	p := tea.NewProgram(NewReplay(b, filename, theme), tea.WithAltScreen())
+	_, err := p.Run()
+	return err
+}
@@ -0,0 +1,200 @@
+package tui
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/charmbracelet/glamour/ansi"
+	"github.com/charmbracelet/glamour/styles"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Theme holds every style the viewer and replay draw with. Pick one of the
+// presets with LookupTheme and pass it to New, Run or RunReplay.
+type Theme struct {
+	Title       lipgloss.Style // title bar at the very top
+	ActiveTab   lipgloss.Style
+	InactiveTab lipgloss.Style
+	TabSep      lipgloss.Style // separator between tabs
+	TabBar      lipgloss.Style // background behind the tab row
+	Section     lipgloss.Style // section heading inside a tab
+	Label       lipgloss.Style // key of a key: value row
+	Dim         lipgloss.Style
+	Time        lipgloss.Style
+	Bullet      lipgloss.Style
+	Warning     lipgloss.Style
+	Failed      lipgloss.Style // a command's non-zero exit status
+	StatusBar   lipgloss.Style
+	SelectedRow lipgloss.Style // selected row in the File Edits list
+	HelpBox     lipgloss.Style // frame of the help and palette overlays
+
+	KindAnnotation lipgloss.Style
+	KindFileEdit   lipgloss.Style
+	KindCommand    lipgloss.Style
+	KindBlocker    lipgloss.Style
+
+	DiffAdd    lipgloss.Style
+	DiffDel    lipgloss.Style
+	DiffMeta   lipgloss.Style
+	DiffAddHi  lipgloss.Style // changed words on a paired + line
+	DiffAddDim lipgloss.Style // unchanged words on a paired + line
+	DiffDelHi  lipgloss.Style
+	DiffDelDim lipgloss.Style
+
+	Markdown ansi.StyleConfig // annotation messages, rendered with glamour
+
+	ReplayCommand lipgloss.Style
+}
+
+// DefaultTheme is used when no theme is configured and NO_COLOR is unset.
+const DefaultTheme = "dark"
+
+// themes maps preset names to constructors.
+var themes = map[string]func() Theme{
+	"dark":    darkTheme,
+	"light":   lightTheme,
+	"nocolor": noColorTheme,
+}
+
+// ThemeNames returns the preset names, sorted.
+func ThemeNames() []string {
+	names := make([]string, 0, len(themes))
+	for name := range themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTheme returns the preset called name.
+func LookupTheme(name string) (Theme, error) {
+	mk, ok := themes[name]
+	if !ok {
+		return Theme{}, fmt.Errorf("unknown theme %q (want one of %v)", name, ThemeNames())
+	}
+	return mk(), nil
+}
+
+// palette256 builds a theme from 256-color codes; dark and light differ only
+// in their colors.
+type palette256 struct {
+	accent, onAccent, tabFg, tabBg, tabSep, section, label, dim, time, bullet,
+	warning, selectedBg, note, edit, cmd, blocker, add, del, meta, addHi,
+	addDim, delHi, delDim, code, codeBg, quote, cmdBg string
+	// markdown is the glamour style the Markdown colors are laid over
+	markdown ansi.StyleConfig
+}
+
+func (p palette256) theme() Theme {
+	c := func(s string) lipgloss.Color { return lipgloss.Color(s) }
+	fg := func(s string) lipgloss.Style { return lipgloss.NewStyle().Foreground(c(s)) }
+	return Theme{
+		Title:       lipgloss.NewStyle().Bold(true).Foreground(c(p.onAccent)).Background(c(p.accent)).Padding(0, 2),
+		ActiveTab:   lipgloss.NewStyle().Bold(true).Foreground(c(p.onAccent)).Background(c(p.accent)).Padding(0, 1),
+		InactiveTab: lipgloss.NewStyle().Foreground(c(p.tabFg)).Background(c(p.tabBg)).Padding(0, 1),
+		TabSep:      fg(p.tabSep).Background(c(p.tabBg)),
+		TabBar:      lipgloss.NewStyle().Background(c(p.tabBg)),
+		Section:     fg(p.section).Bold(true),
+		Label:       fg(p.label).Bold(true),
+		Dim:         fg(p.dim),
+		Time:        fg(p.time),
+		Bullet:      fg(p.bullet),
+		Warning:     fg(p.warning),
+		Failed:      fg(p.blocker).Bold(true),
+		StatusBar:   fg(p.tabFg).Background(c(p.tabBg)).Padding(0, 1),
+		SelectedRow: lipgloss.NewStyle().Bold(true).Foreground(c(p.onAccent)).Background(c(p.selectedBg)),
+		HelpBox:     lipgloss.NewStyle().Border(lipgloss.RoundedBorder()).BorderForeground(c(p.accent)).Padding(1, 3),
+
+		KindAnnotation: fg(p.note).Bold(true),
+		KindFileEdit:   fg(p.edit).Bold(true),
+		KindCommand:    fg(p.cmd).Bold(true),
+		KindBlocker:    fg(p.blocker).Bold(true),
+
+		DiffAdd:    fg(p.add),
+		DiffDel:    fg(p.del),
+		DiffMeta:   fg(p.meta),
+		DiffAddHi:  fg(p.addHi).Bold(true),
+		DiffAddDim: fg(p.addDim),
+		DiffDelHi:  fg(p.delHi).Bold(true),
+		DiffDelDim: fg(p.delDim),
+
+		Markdown: withMarkdown(p.markdown, p.code, p.codeBg, p.quote),
+
+		ReplayCommand: fg(p.onAccent).Background(c(p.cmdBg)).Padding(0, 1),
+	}
+}
+
+// withMarkdown returns base with the code span and block quote colors
+// replaced.
+func withMarkdown(base ansi.StyleConfig, code, codeBg, quote string) ansi.StyleConfig {
+	base.Code.Color, base.Code.BackgroundColor = &code, &codeBg
+	base.BlockQuote.Color = &quote
+	return base
+}
+
+// darkTheme suits light text on a dark terminal background.
+func darkTheme() Theme {
+	return palette256{
+		accent: "62", onAccent: "15", tabFg: "245", tabBg: "235", tabSep: "238",
+		section: "86", label: "33", dim: "240", time: "178", bullet: "205",
+		warning: "214", selectedBg: "237",
+		note: "82", edit: "214", cmd: "39", blocker: "196",
+		add: "82", del: "196", meta: "244", addHi: "120", addDim: "28", delHi: "210", delDim: "88",
+		code: "215", codeBg: "236", quote: "245", cmdBg: "236",
+		markdown: styles.DarkStyleConfig,
+	}.theme()
+}
+
+// lightTheme suits dark text on a light terminal background.
+func lightTheme() Theme {
+	return palette256{
+		accent: "25", onAccent: "15", tabFg: "238", tabBg: "254", tabSep: "250",
+		section: "30", label: "19", dim: "244", time: "130", bullet: "163",
+		warning: "166", selectedBg: "67",
+		note: "28", edit: "166", cmd: "25", blocker: "160",
+		add: "28", del: "160", meta: "242", addHi: "22", addDim: "71", delHi: "124", delDim: "174",
+		code: "124", codeBg: "255", quote: "242", cmdBg: "67",
+		markdown: styles.LightStyleConfig,
+	}.theme()
+}
+
+// noColorTheme uses no colors at all, only bold, underline, italic and
+// reverse video, for NO_COLOR and monochrome terminals.
+func noColorTheme() Theme {
+	plain := lipgloss.NewStyle()
+	bold := plain.Bold(true)
+	return Theme{
+		Title:       bold.Reverse(true).Padding(0, 2),
+		ActiveTab:   bold.Reverse(true).Padding(0, 1),
+		InactiveTab: plain.Padding(0, 1),
+		TabSep:      plain,
+		TabBar:      plain,
+		Section:     bold.Underline(true),
+		Label:       bold,
+		Dim:         plain.Faint(true),
+		Time:        plain,
+		Bullet:      bold,
+		Warning:     bold,
+		Failed:      bold.Reverse(true),
+		StatusBar:   plain.Reverse(true).Padding(0, 1),
+		SelectedRow: bold.Reverse(true),
+		HelpBox:     plain.Border(lipgloss.RoundedBorder()).Padding(1, 3),
+
+		KindAnnotation: bold,
+		KindFileEdit:   bold,
+		KindCommand:    bold,
+		KindBlocker:    bold.Reverse(true),
+
+		DiffAdd:    plain,
+		DiffDel:    plain,
+		DiffMeta:   plain.Faint(true),
+		DiffAddHi:  bold.Underline(true),
+		DiffAddDim: plain,
+		DiffDelHi:  bold.Underline(true),
+		DiffDelDim: plain,
+
+		Markdown: styles.NoTTYStyleConfig,
+
+		ReplayCommand: plain.Reverse(true).Padding(0, 1),
+	}
+}
@@ -3,86 +3,22 @@ package tui
 
 import (
 	"fmt"
+	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	"github.com/charmbracelet/bubbles/viewport"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/glamour"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 	"github.com/fakeyudi/handoff/internal/bundle"
-)
-
-// ── Styles ────────────
-
-var (
-	// Title bar at the very top
-	titleStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("15")).
-			Background(lipgloss.Color("62")).
-			Padding(0, 2)
-
-	// Active tab: bright, underlined
-	activeTabStyle = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("15")).
-			Background(lipgloss.Color("62")).
-			Padding(0, 1)
-
-	// Inactive tab: muted
-	inactiveTabStyle = lipgloss.NewStyle().
-				Foreground(lipgloss.Color("245")).
-				Background(lipgloss.Color("235")).
-				Padding(0, 1)
-
-	// Separator between tabs
-	tabSepStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("238")).
-			Background(lipgloss.Color("235"))
-
-	// Section heading inside a tab
-	sectionHeader = lipgloss.NewStyle().
-			Bold(true).
-			Foreground(lipgloss.Color("86"))
-
-	// Key=value label
-	labelStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("33")).
-			Bold(true)
-
-	dimStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
-
-	timeStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("178"))
-
-	bulletStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("205"))
-
-	kindAnnotationStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Bold(true)
-	kindFileEditStyle   = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
-	kindCommandStyle    = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
-
-	hintStyle = lipgloss.NewStyle().
-			Foreground(lipgloss.Color("240"))
-
-	statusBarStyle = lipgloss.NewStyle().
-			Background(lipgloss.Color("235")).
-			Foreground(lipgloss.Color("245")).
-			Padding(0, 1)
-
-	// Diff rendering
-	diffAddStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("82"))
-	diffDelStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
-	diffMetaStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("244"))
-
-	// Selected row in File Edits list
-	selectedRowStyle = lipgloss.NewStyle().
-				Bold(true).
-				Foreground(lipgloss.Color("15")).
-				Background(lipgloss.Color("237"))
+	"github.com/fakeyudi/handoff/internal/clipboard"
+	"github.com/fakeyudi/handoff/internal/session"
 )
 
 // ── Tab definitions ─────────────────
@@ -96,12 +32,13 @@ const (
 	tabGit
 	tabCommands
 	tabEditorTabs
+	tabBrowserTabs
 	tabTimeline
 	tabCount
 )
 
 var tabNames = [tabCount]string{
-	"Summary", "Annotations", "File Edits", "Git", "Commands", "Editor Tabs", "Timeline",
+	"Summary", "Annotations", "File Edits", "Git", "Commands", "Editor Tabs", "Browser", "Timeline",
 }
 
 // ── Timeline event ───────────────────
@@ -113,12 +50,34 @@ const (
 	kindSummary eventKind = "SUMMARY"
 	kindEdit    eventKind = "EDIT"
 	kindCmd     eventKind = "CMD"
+	kindBlocker eventKind = "BLOCKER"
 )
 
 type timelineEvent struct {
 	ts   time.Time
 	kind eventKind
 	text string
+	diff string // file edits only; shown by replay
+}
+
+// ── Prompt ───────────────────
+
+// promptKind identifies what the status-bar text input is currently asking for.
+type promptKind int
+
+const (
+	promptNone promptKind = iota
+	promptTimeFrom
+	promptTimeUntil
+	promptEditAnnotation
+	promptConfirmWrite
+)
+
+var promptLabels = map[promptKind]string{
+	promptTimeFrom:       "Show events from (HH:MM): ",
+	promptTimeUntil:      "Show events until (HH:MM): ",
+	promptEditAnnotation: "Edit annotation: ",
+	promptConfirmWrite:   "Overwrite source file? (y/N): ",
 }
 
 // ── Model ────────────────────
@@ -127,6 +86,7 @@ type timelineEvent struct {
 type Model struct {
 	bundle        *bundle.ContextBundle
 	filename      string
+	path          string
 	activeTab     tabID
 	viewports     [tabCount]viewport.Model
 	width         int
@@ -137,15 +97,70 @@ type Model struct {
 	// File Edits tab: cursor position and expanded set
 	editCursor    int
 	expandedEdits map[int]bool
+	// fullPaths turns off abbreviation of long paths (File Edits, Editor Tabs)
+	fullPaths bool
+	// File Edits and Git tabs: wrap long diff lines, or scroll them
+	// horizontally by diffOffset columns; diffWidest is each tab's widest
+	// diff line, filled in as the tab is rendered
+	diffWrap   bool
+	diffOffset int
+	diffWidest [tabCount]int
+	// Annotations tab: cursor position, expanded log excerpts and
+	// unsaved-changes flag
+	annCursor   int
+	expandedAnn map[int]bool
+	dirty       bool
+	quitArmed   bool
+	// save writes the bundle back to its file (nil: it can't be), and
+	// signed says the file carries a signature that writing will drop
+	save   func(*bundle.ContextBundle) error
+	signed bool
+	// Timeline tab: optional time range filter (zero = unbounded), and how
+	// close a file edit must be to an annotation to be grouped under it
+	// (zero = no grouping)
+	timeFrom      time.Time
+	timeUntil     time.Time
+	clusterWindow time.Duration
+	// raw shows annotation messages as plain text instead of rendering
+	// their Markdown with markdown, which wraps to the window's width and
+	// is re-created when it changes
+	raw      bool
+	markdown *glamour.TermRenderer
+	// relTimes shows timestamps as offsets from the stop time instead of
+	// clock times
+	relTimes bool
+	// loc is the zone times are shown in; nil shows them as stored
+	loc *time.Location
+	// showHelp is true while the `?` help overlay is displayed
+	showHelp bool
+	// showPalette is true while the `:` command palette is displayed
+	showPalette bool
+	palette     palette
+	// showRaw is true while the ctrl+j raw JSON overlay is displayed
+	showRaw bool
+	rawView viewport.Model
+	// itemLines holds, per list tab, the content line each item starts
+	// on; filled in as the tab is rendered
+	itemLines [tabCount][]int
+	// Status-bar text input, active when prompt != promptNone
+	prompt    promptKind
+	input     textinput.Model
+	statusMsg string
+	theme     *Theme
 }
 
-// New creates a new TUI model for the given bundle and source filename.
-func New(b *bundle.ContextBundle, filename string) Model {
+// New creates a new TUI model for the given bundle and source filename,
+// drawn with theme.
+func New(b *bundle.ContextBundle, filename string, theme Theme) Model {
 	m := Model{
 		bundle:        b,
+		theme:         &theme,
 		filename:      filepath.Base(filename),
+		path:          filename,
 		sortAsc:       false,
 		expandedEdits: make(map[int]bool),
+		expandedAnn:   make(map[int]bool),
+		input:         textinput.New(),
 	}
 	m.timeline = buildTimeline(b)
 	return m
@@ -158,36 +173,194 @@ func (m Model) Init() tea.Cmd { return nil }
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.prompt != promptNone {
+			return m.updatePrompt(msg)
+		}
+		if m.showPalette {
+			return m.updatePalette(msg)
+		}
+		if m.showRaw {
+			return m.updateRawJSON(msg)
+		}
+		if m.showHelp {
+			// Only closing keys work over the overlay; ctrl+c also goes on
+			// to the normal quit handling below.
+			switch msg.String() {
+			case "?", "esc":
+				m.showHelp = false
+				return m, nil
+			case "ctrl+c":
+				m.showHelp = false
+			default:
+				return m, nil
+			}
+		}
+		m.statusMsg = ""
+		quitArmed := m.quitArmed
+		m.quitArmed = false
 		switch msg.String() {
 		case "q", "ctrl+c":
+			if m.dirty && !quitArmed {
+				m.quitArmed = true
+				m.statusMsg = "unsaved annotation changes — w to write, q again to discard"
+				return m, nil
+			}
 			return m, tea.Quit
-		case "tab", "l", "right":
+		case "?":
+			m.showHelp = true
+			return m, nil
+		case "left", "right":
+			if m.scrollsDiffs() {
+				if msg.String() == "left" {
+					m.diffOffset = max(m.diffOffset-diffScrollStep, 0)
+				} else if m.diffWidest[m.activeTab] > m.width-2+m.diffOffset {
+					m.diffOffset += diffScrollStep
+				}
+				m.rebuildDiffViewports()
+				return m, nil
+			}
+			if msg.String() == "left" {
+				m.activeTab = (m.activeTab - 1 + tabCount) % tabCount
+			} else {
+				m.activeTab = (m.activeTab + 1) % tabCount
+			}
+		case "tab", "l":
 			m.activeTab = (m.activeTab + 1) % tabCount
-		case "shift+tab", "h", "left":
+		case "shift+tab", "h":
 			m.activeTab = (m.activeTab - 1 + tabCount) % tabCount
-		case "1", "2", "3", "4", "5", "6", "7":
+		case "1", "2", "3", "4", "5", "6", "7", "8":
 			m.activeTab = tabID(msg.String()[0] - '1')
+		case ":", "ctrl+k":
+			return m, m.openPalette()
+		case "ctrl+j":
+			m.openRawJSON()
+			return m, nil
+		case "y":
+			if err := clipboard.Copy(m.clipboardText()); err != nil {
+				m.statusMsg = "copy failed: " + err.Error()
+			} else {
+				m.statusMsg = "copied " + tabNames[m.activeTab] + " to clipboard"
+				if m.activeTab == tabFileEdits && m.cursorDiffExpanded() {
+					m.statusMsg = "copied diff to clipboard"
+				}
+			}
+			return m, nil
+		case "r":
+			m.relTimes = !m.relTimes
+			for _, tab := range []tabID{tabAnnotations, tabFileEdits, tabCommands, tabTimeline} {
+				m.viewports[tab].SetContent(m.renderTab(tab))
+			}
+			return m, nil
+		case "a":
+			if m.activeTab == tabFileEdits || m.activeTab == tabEditorTabs {
+				m.fullPaths = !m.fullPaths
+				m.rebuildFileEditsViewport()
+				m.viewports[tabEditorTabs].SetContent(m.renderTab(tabEditorTabs))
+			}
+			return m, nil
+		case "o":
+			if m.activeTab == tabFileEdits && len(m.bundle.FileEdits) > 0 {
+				cmd, err := m.openInEditor(m.bundle.FileEdits[m.editCursor].Path)
+				if err != nil {
+					m.statusMsg = err.Error()
+				}
+				return m, cmd
+			}
 		case "s":
 			if m.activeTab == tabTimeline {
 				m.sortAsc = !m.sortAsc
 				m.rebuildTimelineViewport()
 			}
+		case "f":
+			if m.activeTab == tabTimeline {
+				return m, m.startPrompt(promptTimeFrom, formatClock(m.inZone(m.timeFrom)))
+			}
+		case "u":
+			if m.activeTab == tabTimeline {
+				return m, m.startPrompt(promptTimeUntil, formatClock(m.inZone(m.timeUntil)))
+			}
+		case "c":
+			if m.activeTab == tabTimeline && m.hasTimeRange() {
+				m.timeFrom, m.timeUntil = time.Time{}, time.Time{}
+				m.rebuildTimelineViewport()
+			}
+		case "E":
+			if m.activeTab == tabFileEdits {
+				// Mark them all first so the tab is rendered once, not per diff.
+				for i, fe := range m.bundle.FileEdits {
+					if fe.Diff != "" {
+						m.expandedEdits[i] = true
+					}
+				}
+				m.rebuildFileEditsViewport()
+				return m, nil
+			}
+		case "C":
+			if m.activeTab == tabFileEdits && len(m.expandedEdits) > 0 {
+				clear(m.expandedEdits)
+				m.rebuildFileEditsViewport()
+				return m, nil
+			}
+		case "e":
+			if m.activeTab == tabAnnotations && len(m.bundle.Annotations) > 0 {
+				return m, m.startPrompt(promptEditAnnotation, m.bundle.Annotations[m.annCursor].Message)
+			}
+		case "d":
+			if m.activeTab == tabAnnotations && len(m.bundle.Annotations) > 0 {
+				m.deleteAnnotation(m.annCursor)
+				return m, nil
+			}
+		case "w":
+			if m.activeTab == tabAnnotations && m.dirty {
+				if m.save == nil {
+					m.statusMsg = m.filename + " can't be written back; changes are kept until you quit"
+					return m, nil
+				}
+				cmd := m.startPrompt(promptConfirmWrite, "")
+				if m.signed {
+					m.input.Prompt = "Overwrite source file and remove its signature? (y/N): "
+				}
+				return m, cmd
+			}
+			if m.activeTab == tabFileEdits || m.activeTab == tabGit {
+				m.diffWrap = !m.diffWrap
+				m.diffOffset = 0
+				m.rebuildDiffViewports()
+				return m, nil
+			}
 		case "up", "k":
+			if m.activeTab == tabAnnotations && m.annCursor > 0 {
+				m.annCursor--
+				m.rebuildAnnotationsViewport()
+				return m, nil
+			}
 			if m.activeTab == tabFileEdits && m.editCursor > 0 {
 				m.editCursor--
 				m.rebuildFileEditsViewport()
 				return m, nil
 			}
 		case "down", "j":
+			if m.activeTab == tabAnnotations && m.annCursor < len(m.bundle.Annotations)-1 {
+				m.annCursor++
+				m.rebuildAnnotationsViewport()
+				return m, nil
+			}
 			if m.activeTab == tabFileEdits && m.editCursor < len(m.bundle.FileEdits)-1 {
 				m.editCursor++
 				m.rebuildFileEditsViewport()
 				return m, nil
 			}
 		case "enter", " ":
+			if m.activeTab == tabAnnotations && len(m.bundle.Annotations) > 0 {
+				if m.bundle.Annotations[m.annCursor].LogExcerpt != "" {
+					m.expandedAnn[m.annCursor] = !m.expandedAnn[m.annCursor]
+					m.rebuildAnnotationsViewport()
+				}
+				return m, nil
+			}
 			if m.activeTab == tabFileEdits && len(m.bundle.FileEdits) > 0 {
 				fe := m.bundle.FileEdits[m.editCursor]
-				if fe.Diff != "" { // only expandable when diff exists
+				if fe.Diff != "" || len(m.notesFor(fe.Path)) > 0 { // only expandable with something to show
 					if m.expandedEdits[m.editCursor] {
 						delete(m.expandedEdits, m.editCursor)
 					} else {
@@ -202,11 +375,21 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.viewports[m.activeTab], cmd = m.viewports[m.activeTab].Update(msg)
 		return m, cmd
 
+	case editorFinishedMsg:
+		if msg.err != nil {
+			m.statusMsg = "editor: " + msg.err.Error()
+		}
+		return m, nil
+
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
 		m.height = msg.Height
 		m.ready = true
+		m.markdown = m.theme.newMarkdownRenderer(m.width - annotationLeadWidth - 2)
 		m.initViewports()
+		if m.showRaw {
+			m.rawView.Width, m.rawView.Height = m.width, max(m.height-3, 1)
+		}
 		return m, nil
 	}
 	return m, nil
@@ -216,25 +399,40 @@ func (m Model) View() string {
 	if !m.ready {
 		return "Loading…"
 	}
+	if m.showHelp {
+		return m.theme.renderHelp(m.width, m.height)
+	}
+	if m.showPalette {
+		return m.renderPalette(m.width, m.height)
+	}
+	if m.showRaw {
+		return m.renderRawJSON()
+	}
 
 	// ── Row 1: title bar ──────────────────────────────────────────────────────
-	title := titleStyle.Width(m.width).Render("  handoff  " + m.filename)
+	name := m.filename
+	if m.dirty {
+		name += " [modified]"
+	}
+	if project := m.bundle.Session.ProjectName; project != "" {
+		name = project + "  " + m.theme.Dim.Render(name)
+	}
+	title := m.theme.Title.Width(m.width).Render("  handoff  " + name)
 
 	// ── Row 2: tab bar ────────────────────────────────────────────────────────
 	var tabParts []string
 	for i := tabID(0); i < tabCount; i++ {
 		label := fmt.Sprintf(" %d %s ", i+1, tabNames[i])
 		if i == m.activeTab {
-			tabParts = append(tabParts, activeTabStyle.Render(label))
+			tabParts = append(tabParts, m.theme.ActiveTab.Render(label))
 		} else {
-			tabParts = append(tabParts, inactiveTabStyle.Render(label))
+			tabParts = append(tabParts, m.theme.InactiveTab.Render(label))
 		}
 		if i < tabCount-1 {
-			tabParts = append(tabParts, tabSepStyle.Render("│"))
+			tabParts = append(tabParts, m.theme.TabSep.Render("│"))
 		}
 	}
-	tabRow := lipgloss.NewStyle().
-		Background(lipgloss.Color("235")).
+	tabRow := m.theme.TabBar.
 		Width(m.width).
 		Render(lipgloss.JoinHorizontal(lipgloss.Top, tabParts...))
 
@@ -242,30 +440,90 @@ func (m Model) View() string {
 	content := m.viewports[m.activeTab].View()
 
 	// ── Row N: status / hint bar ──────────────────────────────────────────────
-	hint := "  ←/→ tab  ↑/↓ scroll  1-7 jump  q quit"
+	if m.prompt != promptNone {
+		statusBar := m.theme.StatusBar.Width(m.width).Render("  " + m.input.View())
+		return lipgloss.JoinVertical(lipgloss.Left, title, tabRow, content, statusBar)
+	}
+
+	hint := "  ←/→ tab  ↑/↓ scroll  1-8 jump  : find  y copy  ? help  q quit"
 	if m.activeTab == tabTimeline {
 		dir := "newest first"
 		if m.sortAsc {
 			dir = "oldest first"
 		}
-		hint += "  s sort (" + dir + ")"
+		hint += "  s sort (" + dir + ")  f/u from/until"
+		if m.hasTimeRange() {
+			hint += "  c clear (" + m.timeRangeLabel() + ")"
+		}
 	}
 	if m.activeTab == tabFileEdits {
-		hint += "  ↑/↓ select  enter expand/collapse"
+		hint += "  ↑/↓ select  enter expand/collapse  E/C all  o open  a paths"
+	}
+	if m.activeTab == tabFileEdits || m.activeTab == tabGit {
+		if m.diffWrap {
+			hint += "  w scroll long lines"
+		} else {
+			hint += "  w wrap long lines"
+			if m.scrollsDiffs() {
+				hint += "  ←/→ scroll diff"
+			}
+		}
+	}
+	if m.activeTab == tabEditorTabs {
+		hint += "  a paths"
+	}
+	if m.activeTab == tabAnnotations {
+		hint += "  ↑/↓ select  enter log  e edit  d delete"
+		if m.dirty {
+			hint += "  w write"
+		}
+	}
+	switch m.activeTab {
+	case tabAnnotations, tabFileEdits, tabCommands, tabTimeline:
+		if m.relTimes {
+			hint += "  r times (to stop)"
+		} else {
+			hint += "  r times (clock)"
+		}
 	}
-	// show scroll % on the right
-	pct := fmt.Sprintf("%3.0f%%", m.viewports[m.activeTab].ScrollPercent()*100)
-	pad := m.width - lipgloss.Width(hint) - len(pct) - 2
+	if m.loc != nil {
+		hint += "  tz " + zoneLabel(m.loc)
+	}
+	if m.statusMsg != "" {
+		hint = "  " + m.statusMsg
+	}
+	// Scroll position on the right: "line X–Y of Z" plus a percentage,
+	// dropping the percentage first when the terminal is too narrow.
+	vp := m.viewports[m.activeTab]
+	lines := lineRange(vp)
+	pct := fmt.Sprintf("%3.0f%%", vp.ScrollPercent()*100)
+	right := lines + "  " + pct
+	room := m.width - lipgloss.Width(hint) - 2
+	if lipgloss.Width(right) >= room {
+		right = lines
+	}
+	pad := room - lipgloss.Width(right)
 	if pad < 1 {
 		pad = 1
 	}
-	statusBar := statusBarStyle.Width(m.width).Render(
-		hint + strings.Repeat(" ", pad) + pct,
+	statusBar := m.theme.StatusBar.Width(m.width).Render(
+		hint + strings.Repeat(" ", pad) + right,
 	)
 
 	return lipgloss.JoinVertical(lipgloss.Left, title, tabRow, content, statusBar)
 }
 
+// lineRange describes the visible part of vp as "line X–Y of Z".
+func lineRange(vp viewport.Model) string {
+	total := vp.TotalLineCount()
+	if total == 0 {
+		return "line 0 of 0"
+	}
+	first := vp.YOffset + 1
+	last := min(vp.YOffset+vp.VisibleLineCount(), total)
+	return fmt.Sprintf("line %d–%d of %d", first, last, total)
+}
+
 // ── Viewport management ───────────────────────────────────────────────────────
 
 func (m *Model) initViewports() {
@@ -290,6 +548,196 @@ func (m *Model) rebuildFileEditsViewport() {
 	m.viewports[tabFileEdits].SetContent(m.renderTab(tabFileEdits))
 }
 
+// rebuildDiffViewports re-renders the tabs that show diffs after the diff
+// layout changes.
+func (m *Model) rebuildDiffViewports() {
+	m.rebuildFileEditsViewport()
+	m.viewports[tabGit].SetContent(m.renderTab(tabGit))
+}
+
+// diffLayout returns the layout for rendering diffs with the current wrap
+// and scroll settings.
+func (m *Model) diffLayout() *diffLayout {
+	return &diffLayout{wrap: m.diffWrap, offset: m.diffOffset, theme: m.theme}
+}
+
+// scrollsDiffs reports whether left/right scroll the active tab's diffs
+// instead of switching tabs: the tab shows diffs, wrapping is off and a line
+// is too wide (or already scrolled).
+func (m *Model) scrollsDiffs() bool {
+	if m.activeTab != tabFileEdits && m.activeTab != tabGit || m.diffWrap {
+		return false
+	}
+	return m.diffOffset > 0 || m.diffWidest[m.activeTab] > m.width-2
+}
+
+func (m *Model) rebuildAnnotationsViewport() {
+	m.viewports[tabAnnotations].SetContent(m.renderTab(tabAnnotations))
+}
+
+// ── Clipboard ─────────────────────────────────────────────────────────────────
+
+// cursorDiffExpanded reports whether the File Edits cursor is on an expanded diff.
+func (m *Model) cursorDiffExpanded() bool {
+	if len(m.bundle.FileEdits) == 0 || !m.expandedEdits[m.editCursor] {
+		return false
+	}
+	return m.bundle.FileEdits[m.editCursor].Diff != ""
+}
+
+// clipboardText returns what `y` copies: the selected diff when one is
+// expanded on the File Edits tab, otherwise the active tab's text without
+// ANSI styling.
+func (m *Model) clipboardText() string {
+	if m.activeTab == tabFileEdits && m.cursorDiffExpanded() {
+		return m.bundle.FileEdits[m.editCursor].Diff
+	}
+	return ansi.Strip(m.renderTab(m.activeTab))
+}
+
+// ── Annotation editing ────────────────────────────────────────────────────────
+
+// annotationsChanged marks the bundle dirty and refreshes every view that
+// shows annotations.
+func (m *Model) annotationsChanged() {
+	m.dirty = true
+	m.timeline = buildTimeline(m.bundle)
+	if m.annCursor >= len(m.bundle.Annotations) {
+		m.annCursor = len(m.bundle.Annotations) - 1
+	}
+	if m.annCursor < 0 {
+		m.annCursor = 0
+	}
+	if m.ready {
+		m.rebuildAnnotationsViewport()
+		m.viewports[tabSummary].SetContent(m.renderTab(tabSummary))
+		m.viewports[tabTimeline].SetContent(m.renderTab(tabTimeline))
+	}
+}
+
+// deleteAnnotation removes the annotation at index i.
+func (m *Model) deleteAnnotation(i int) {
+	anns := m.bundle.Annotations
+	m.bundle.Annotations = append(anns[:i:i], anns[i+1:]...)
+	clear(m.expandedAnn) // indices have shifted
+	m.annotationsChanged()
+	m.statusMsg = "annotation deleted (w to write)"
+}
+
+// writeBundle saves the bundle over its source file with Options.Save.
+func (m *Model) writeBundle() error {
+	if err := m.save(m.bundle); err != nil {
+		return err
+	}
+	m.dirty = false
+	return nil
+}
+
+// ── Prompt handling ───────────────────────────────────────────────────────────
+
+// startPrompt switches the status bar into a text input for kind, pre-filled
+// with value.
+func (m *Model) startPrompt(kind promptKind, value string) tea.Cmd {
+	m.prompt = kind
+	m.input.Prompt = promptLabels[kind]
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// updatePrompt routes key presses to the active text input. Enter submits,
+// Esc cancels.
+func (m Model) updatePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "ctrl+c":
+		m.prompt = promptNone
+		m.input.Blur()
+		return m, nil
+	case "enter":
+		kind := m.prompt
+		m.prompt = promptNone
+		m.input.Blur()
+		m.submitPrompt(kind, strings.TrimSpace(m.input.Value()))
+		return m, nil
+	}
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+// submitPrompt applies the value entered for kind.
+func (m *Model) submitPrompt(kind promptKind, value string) {
+	switch kind {
+	case promptTimeFrom, promptTimeUntil:
+		var t time.Time
+		if value != "" {
+			parsed, err := parseClock(value, m.inZone(m.bundle.Session.StartTime))
+			if err != nil {
+				m.statusMsg = err.Error()
+				return
+			}
+			t = parsed
+		}
+		if kind == promptTimeFrom {
+			m.timeFrom = t
+		} else {
+			m.timeUntil = t
+		}
+		m.rebuildTimelineViewport()
+	case promptEditAnnotation:
+		if value == "" {
+			m.statusMsg = "annotation text cannot be empty (use d to delete)"
+			return
+		}
+		if value != m.bundle.Annotations[m.annCursor].Message {
+			m.bundle.Annotations[m.annCursor].Message = value
+			m.annotationsChanged()
+		}
+	case promptConfirmWrite:
+		if !strings.EqualFold(value, "y") && !strings.EqualFold(value, "yes") {
+			m.statusMsg = "write cancelled"
+			return
+		}
+		if err := m.writeBundle(); err != nil {
+			m.statusMsg = err.Error()
+			return
+		}
+		m.statusMsg = "wrote " + m.filename
+		if m.signed {
+			m.signed = false
+			m.statusMsg += " (signature removed; run 'handoff sign' to sign it again)"
+		}
+	}
+}
+
+// hasTimeRange reports whether a Timeline time filter is active.
+func (m *Model) hasTimeRange() bool {
+	return !m.timeFrom.IsZero() || !m.timeUntil.IsZero()
+}
+
+// timeRangeLabel formats the active time filter, e.g. "14:00–15:00".
+func (m *Model) timeRangeLabel() string {
+	from, until := "…", "…"
+	if !m.timeFrom.IsZero() {
+		from = m.inZone(m.timeFrom).Format("15:04")
+	}
+	if !m.timeUntil.IsZero() {
+		until = m.inZone(m.timeUntil).Format("15:04")
+	}
+	return from + "–" + until
+}
+
+// inTimeRange reports whether ts falls within the active time filter.
+func (m *Model) inTimeRange(ts time.Time) bool {
+	if !m.timeFrom.IsZero() && ts.Before(m.timeFrom) {
+		return false
+	}
+	if !m.timeUntil.IsZero() && ts.After(m.timeUntil) {
+		return false
+	}
+	return true
+}
+
 // ── Tab renderers ─────────────────────────────────────────────────────────────
 
 func (m *Model) renderTab(t tabID) string {
@@ -306,78 +754,278 @@ func (m *Model) renderTab(t tabID) string {
 		return m.renderCommands()
 	case tabEditorTabs:
 		return m.renderEditorTabs()
+	case tabBrowserTabs:
+		return m.renderBrowserTabs()
 	case tabTimeline:
 		return m.renderTimeline()
 	}
 	return ""
 }
 
-func heading(s string) string {
-	return "\n" + sectionHeader.Render("  "+s) + "\n\n"
+func (t *Theme) heading(s string) string {
+	return "\n" + t.Section.Render("  "+s) + "\n\n"
 }
 
-func bullet(text string) string {
-	return bulletStyle.Render("  •") + "  " + text + "\n"
+func (t *Theme) bullet(text string) string {
+	return t.Bullet.Render("  •") + "  " + text + "\n"
 }
 
 func (m *Model) renderSummary() string {
 	s := m.bundle.Session
 	var sb strings.Builder
-	sb.WriteString(heading("Session Summary"))
+	sb.WriteString(m.theme.heading("Session Summary"))
 
 	row := func(label, value string) {
-		sb.WriteString(labelStyle.Render(fmt.Sprintf("  %-14s", label)) + "  " + value + "\n")
+		sb.WriteString(m.theme.Label.Render(fmt.Sprintf("  %-14s", label)) + "  " + value + "\n")
+	}
+	if s.ProjectName != "" {
+		row("Project:", s.ProjectName)
 	}
 	row("Work Dir:", s.WorkDir)
-	row("Started:", s.StartTime.Format("2006-01-02 15:04:05 MST"))
-	row("Stopped:", s.StopTime.Format("2006-01-02 15:04:05 MST"))
+	row("Started:", m.inZone(s.StartTime).Format("2006-01-02 15:04:05 MST"))
+	row("Stopped:", m.inZone(s.StopTime).Format("2006-01-02 15:04:05 MST"))
 	row("Duration:", s.Duration)
+	if line := s.DiffStatLine(); line != "" {
+		row("Changes:", line)
+	}
 	if s.Author != "" {
 		row("Author:", s.Author)
 	}
+	if len(m.bundle.Tags) > 0 {
+		row("Tags:", strings.Join(m.bundle.Tags, ", "))
+	}
 	if m.bundle.Git != nil {
 		row("Branch:", m.bundle.Git.Branch)
 		row("Head Commit:", m.bundle.Git.HeadCommit)
 	}
 
 	sb.WriteString("\n")
-	sb.WriteString(heading("Counts"))
+	sb.WriteString(m.theme.heading("Counts"))
 	row("Annotations:", fmt.Sprintf("%d", len(m.bundle.Annotations)))
 	row("File Edits:", fmt.Sprintf("%d", len(m.bundle.FileEdits)))
 	row("Commands:", fmt.Sprintf("%d", len(m.bundle.Commands)))
+	if c := bundle.LastFailed(m.bundle.Commands); c != nil {
+		row("Last Failure:", m.theme.Failed.Render(fmt.Sprintf("exit %d", *c.ExitCode))+"  "+c.Raw)
+	}
 	row("Editor Tabs:", fmt.Sprintf("%d", len(m.bundle.EditorTabs)))
+	row("Browser Tabs:", fmt.Sprintf("%d", len(m.bundle.BrowserTabs)))
+
+	if len(m.bundle.Languages) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading("Languages"))
+		for _, lc := range bundle.LanguageBreakdown(m.bundle.Languages) {
+			row(lc.Name+":", fmt.Sprintf("%d files", lc.Files))
+		}
+	}
+
+	if len(m.bundle.Env) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Environment (%d)", len(m.bundle.Env))))
+		keys := make([]string, 0, len(m.bundle.Env))
+		for k := range m.bundle.Env {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			row(k, m.bundle.Env[k])
+		}
+	}
+
+	if len(m.bundle.Toolchains) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Toolchain (%d)", len(m.bundle.Toolchains))))
+		probes := make([]string, 0, len(m.bundle.Toolchains))
+		for p := range m.bundle.Toolchains {
+			probes = append(probes, p)
+		}
+		sort.Strings(probes)
+		for _, p := range probes {
+			sb.WriteString(m.theme.bullet(m.theme.Label.Render(p) + "  " + m.bundle.Toolchains[p]))
+		}
+	}
+
+	if t := m.bundle.Tmux; t != nil && len(t.Windows) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Tmux Layout (%s)", t.Session)))
+		for _, w := range t.Windows {
+			panes := make([]string, len(w.Panes))
+			for i, p := range w.Panes {
+				panes[i] = p.Command
+			}
+			sb.WriteString(m.theme.bullet(m.theme.Label.Render(w.Name) + "  " + strings.Join(panes, " │ ")))
+		}
+	}
+
+	if len(m.bundle.Processes) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Running Processes (%d)", len(m.bundle.Processes))))
+		for _, p := range m.bundle.Processes {
+			sb.WriteString(m.theme.bullet(p))
+		}
+	}
+
+	if len(m.bundle.Warnings) > 0 {
+		sb.WriteString("\n")
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Warnings (%d)", len(m.bundle.Warnings))))
+		for _, w := range m.bundle.Warnings {
+			sb.WriteString(m.theme.Warning.Render("  ⚠") + "  " + w + "\n")
+		}
+	}
 	return sb.String()
 }
 
+// annotationLeadWidth is the width of the time and badge before an
+// annotation message, with the widest badge; messages are wrapped to what
+// is left of the line.
+const annotationLeadWidth = len("  15:04:05  [SUMMARY]  ")
+
 func (m *Model) renderAnnotations() string {
 	var sb strings.Builder
-	sb.WriteString(heading(fmt.Sprintf("Annotations (%d)", len(m.bundle.Annotations))))
+	sb.WriteString(m.theme.heading(fmt.Sprintf("Annotations (%d)", len(m.bundle.Annotations))))
 	if len(m.bundle.Annotations) == 0 {
-		sb.WriteString(dimStyle.Render("  (none)") + "\n")
+		sb.WriteString(m.theme.Dim.Render("  (none)") + "\n")
 		return sb.String()
 	}
+
+	// Blockers are repeated in a callout above the list, which keeps its
+	// order so the cursor still matches the annotation being edited.
+	var blockers []session.Annotation
 	for _, a := range m.bundle.Annotations {
+		if a.IsBlocker() {
+			blockers = append(blockers, a)
+		}
+	}
+	if len(blockers) > 0 {
+		sb.WriteString("  " + m.theme.KindBlocker.Render(fmt.Sprintf("■ Blockers (%d)", len(blockers))) + "\n")
+		for _, a := range blockers {
+			row := "    " + m.theme.KindBlocker.Render("•") + " " + a.Message
+			if loc := a.Location(m.bundle.Session.WorkDir); loc != "" {
+				row += "  " + m.theme.Dim.Render(loc)
+			}
+			sb.WriteString(row + "\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	m.itemLines[tabAnnotations] = nil
+	for i, a := range m.bundle.Annotations {
+		m.itemLines[tabAnnotations] = append(m.itemLines[tabAnnotations], strings.Count(sb.String(), "\n"))
 		kind := "NOTE"
 		if a.IsSummary {
 			kind = "SUMMARY"
 		}
-		ts := timeStyle.Render(a.Timestamp.Format("15:04:05"))
-		badge := kindAnnotationStyle.Render("[" + kind + "]")
-		sb.WriteString(fmt.Sprintf("  %s  %s  %s\n\n", ts, badge, a.Message))
+		ts := m.theme.Time.Render(m.stamp(a.Timestamp))
+		var badge string
+		switch a.Level {
+		case session.LevelBlocker:
+			badge = m.theme.KindBlocker.Render("[BLOCKER]")
+		case session.LevelWarn:
+			badge = m.theme.Warning.Bold(true).Render("[WARN]")
+		default:
+			badge = m.theme.KindAnnotation.Render("[" + kind + "]")
+		}
+		lead := fmt.Sprintf("  %s  %s  ", ts, badge)
+		message, more := a.Message, []string(nil)
+		if !m.raw {
+			// Render Markdown in the message to fit beside the badge;
+			// lines after the first go underneath, aligned with it.
+			lines := renderMarkdown(m.markdown, a.Message)
+			message, more = lines[0], lines[1:]
+		}
+		row := lead + message
+		if loc := a.Location(m.bundle.Session.WorkDir); loc != "" {
+			row += "  " + m.theme.Dim.Render(loc)
+		}
+		if a.LogExcerpt != "" {
+			toggle := "▶"
+			if m.expandedAnn[i] {
+				toggle = "▼"
+			}
+			row += "  " + m.theme.Dim.Render(toggle+" log")
+		}
+		if i == m.annCursor {
+			row = m.theme.SelectedRow.Width(m.width - 2).Render(row)
+		}
+		sb.WriteString(row + "\n")
+		pad := strings.Repeat(" ", lipgloss.Width(lead))
+		for _, line := range more {
+			sb.WriteString(pad + line + "\n")
+		}
+		if a.LogExcerpt != "" && m.expandedAnn[i] {
+			sb.WriteString(m.theme.renderLogExcerpt(a.LogExcerpt))
+		}
+		sb.WriteString("\n")
 	}
 	return sb.String()
 }
 
+// logFailPrefixes and logPassPrefixes identify result lines of test runners
+// (go test, pytest, jest and similar), which renderLogExcerpt colorizes.
+var (
+	logFailPrefixes = []string{"--- FAIL", "FAIL", "FAILED", "panic:", "✕", "✗", "E   "}
+	logPassPrefixes = []string{"--- PASS", "PASS", "ok ", "PASSED", "✓"}
+)
+
+// renderLogExcerpt renders an annotation's log excerpt as an indented block.
+// When it looks like test output, failures and passes are colored like diff
+// removals and additions; other logs are shown dimmed.
+func (t *Theme) renderLogExcerpt(excerpt string) string {
+	lines := strings.Split(strings.TrimRight(excerpt, "\n"), "\n")
+	isTest := false
+	for _, line := range lines {
+		if t.logLineStyle(line) != nil {
+			isTest = true
+			break
+		}
+	}
+
+	var sb strings.Builder
+	for _, line := range lines {
+		style := &t.Dim
+		if isTest {
+			if s := t.logLineStyle(line); s != nil {
+				style = s
+			} else {
+				style = &t.DiffMeta
+			}
+		}
+		sb.WriteString("      " + style.Render(line) + "\n")
+	}
+	return sb.String()
+}
+
+// logLineStyle returns the diff style for a test pass or failure line, or nil
+// for any other line.
+func (t *Theme) logLineStyle(line string) *lipgloss.Style {
+	trimmed := strings.TrimLeft(line, " \t")
+	for _, p := range logFailPrefixes {
+		if strings.HasPrefix(trimmed, p) {
+			return &t.DiffDel
+		}
+	}
+	for _, p := range logPassPrefixes {
+		if strings.HasPrefix(trimmed, p) {
+			return &t.DiffAdd
+		}
+	}
+	return nil
+}
+
 func (m *Model) renderFileEdits() string {
 	var sb strings.Builder
-	sb.WriteString(heading(fmt.Sprintf("File Edits (%d)", len(m.bundle.FileEdits))))
+	sb.WriteString(m.theme.heading(fmt.Sprintf("File Edits (%d)", len(m.bundle.FileEdits))))
 	if len(m.bundle.FileEdits) == 0 {
-		sb.WriteString(dimStyle.Render("  (none)") + "\n")
+		sb.WriteString(m.theme.Dim.Render("  (none)") + "\n")
 		return sb.String()
 	}
+	m.itemLines[tabFileEdits] = nil
+	layout := m.diffLayout()
+	defer func() { m.diffWidest[tabFileEdits] = layout.widest }()
 	for i, fe := range m.bundle.FileEdits {
-		ts := timeStyle.Render(fe.Timestamp.Format("15:04:05"))
+		m.itemLines[tabFileEdits] = append(m.itemLines[tabFileEdits], strings.Count(sb.String(), "\n"))
+		ts := m.theme.Time.Render(m.stamp(fe.Timestamp))
 		relPath := stripWorkDir(fe.Path, m.bundle.Session.WorkDir)
+		notes := m.notesFor(fe.Path)
 
 		// Toggle indicator and diff icon
 		hasDiff := fe.Diff != ""
@@ -386,31 +1034,52 @@ func (m *Model) renderFileEdits() string {
 
 		var icon string
 		if !hasDiff {
-			icon = dimStyle.Render("○ ") // no diff available
+			icon = m.theme.Dim.Render("○ ") // no diff available
 		} else if isGitDiff {
-			icon = lipgloss.NewStyle().Foreground(lipgloss.Color("82")).Render("◈ ") // git diff
+			icon = m.theme.DiffAdd.Render("◈ ") // git diff
 		} else {
-			icon = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Render("◇ ") // full file (non-git)
+			icon = m.theme.Warning.Render("◇ ") // full file (non-git)
 		}
 
-		toggle := dimStyle.Render("  ▶ ")
+		toggle := m.theme.Dim.Render("  ▶ ")
 		if expanded {
-			toggle = dimStyle.Render("  ▼ ")
+			toggle = m.theme.Dim.Render("  ▼ ")
 		}
-		if !hasDiff {
+		if !hasDiff && len(notes) == 0 {
 			toggle = "    " // no arrow, not expandable
 		}
 
-		row := fmt.Sprintf("%s%s%s  %s", toggle, icon, ts, relPath)
+		var badge string
+		if m.bundle.DiffsOmitted {
+			badge = m.theme.Dim.Render("  (diff omitted)")
+		}
+		if len(notes) > 0 {
+			badge += m.theme.KindAnnotation.Render(fmt.Sprintf("  ✎ %d", len(notes)))
+		}
+		if i != m.editCursor {
+			// 16 columns for toggle, icon and time; the selected row keeps
+			// its full path.
+			relPath = m.fitPath(relPath, m.width-2-16-lipgloss.Width(badge))
+		}
+		row := fmt.Sprintf("%s%s%s  %s", toggle, icon, ts, relPath) + badge
 		if i == m.editCursor {
 			// Pad to width so the highlight fills the line
-			row = selectedRowStyle.Width(m.width - 2).Render(row)
+			row = m.theme.SelectedRow.Width(m.width - 2).Render(row)
 		}
 		sb.WriteString(row + "\n")
 
-		// Expanded diff block
+		// Expanded notes and diff block
+		if expanded && len(notes) > 0 {
+			for _, a := range notes {
+				loc := ""
+				if a.Line > 0 {
+					loc = m.theme.Dim.Render(fmt.Sprintf("line %d  ", a.Line))
+				}
+				sb.WriteString("      " + m.theme.KindAnnotation.Render("✎ ") + loc + a.Message + "\n")
+			}
+		}
 		if expanded && hasDiff {
-			sb.WriteString(renderDiff(fe.Diff, m.width))
+			sb.WriteString(m.theme.renderDiff(fe.Diff, m.width, layout))
 			sb.WriteString("\n")
 		} else {
 			sb.WriteString("\n")
@@ -419,26 +1088,101 @@ func (m *Model) renderFileEdits() string {
 	return sb.String()
 }
 
+// editorFinishedMsg is sent when the external editor launched by `o` exits.
+type editorFinishedMsg struct{ err error }
+
+// openInEditor suspends the TUI and opens path in $VISUAL or $EDITOR.
+func (m *Model) openInEditor(path string) (tea.Cmd, error) {
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	args := strings.Fields(editor)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("set $EDITOR or $VISUAL to open files")
+	}
+	local, ok := resolveLocalPath(path, m.bundle.Session.WorkDir)
+	if !ok {
+		return nil, fmt.Errorf("%s does not exist on this machine", stripWorkDir(path, m.bundle.Session.WorkDir))
+	}
+	c := exec.Command(args[0], append(args[1:], local)...)
+	return tea.ExecProcess(c, func(err error) tea.Msg { return editorFinishedMsg{err} }), nil
+}
+
+// resolveLocalPath maps a path recorded on another machine to this one: the
+// path itself if it exists, otherwise its work-dir-relative part under the
+// current directory.
+func resolveLocalPath(path, workDir string) (string, bool) {
+	if _, err := os.Stat(path); err == nil {
+		return path, true
+	}
+	rel := stripWorkDir(path, workDir)
+	if rel == path {
+		return "", false
+	}
+	if _, err := os.Stat(rel); err == nil {
+		if abs, err := filepath.Abs(rel); err == nil {
+			return abs, true
+		}
+		return rel, true
+	}
+	return "", false
+}
+
+// notesFor returns the annotations anchored to path, in bundle order.
+func (m *Model) notesFor(path string) []session.Annotation {
+	var notes []session.Annotation
+	for _, a := range m.bundle.Annotations {
+		if a.Path != "" && a.Path == path {
+			notes = append(notes, a)
+		}
+	}
+	return notes
+}
+
 // renderDiff colorises a unified diff string.
-func renderDiff(diff string, width int) string {
+func (t *Theme) renderDiff(diff string, width int, layout *diffLayout) string {
 	var sb strings.Builder
-	border := dimStyle.Render("  " + strings.Repeat("─", width-4))
+	border := t.Dim.Render("  " + strings.Repeat("─", width-4))
 	sb.WriteString(border + "\n")
-	for _, line := range strings.Split(diff, "\n") {
+	lines := strings.Split(diff, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if isDiffRemoval(line) {
+			// Collect the removal run and the addition run that follows it
+			// so changed line pairs can get intra-line highlights.
+			j := i
+			for j < len(lines) && isDiffRemoval(lines[j]) {
+				j++
+			}
+			k := j
+			for k < len(lines) && isDiffAddition(lines[k]) {
+				k++
+			}
+			for _, r := range t.renderChangeBlock(lines[i:j], lines[j:k]) {
+				for _, row := range layout.fit(r, 3, width) {
+					sb.WriteString(row + "\n")
+				}
+			}
+			i = k - 1
+			continue
+		}
 		var rendered string
 		switch {
 		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
-			rendered = diffMetaStyle.Render("  " + line)
+			rendered = t.DiffMeta.Render("  " + line)
 		case strings.HasPrefix(line, "+"):
-			rendered = diffAddStyle.Render("  " + line)
+			rendered = t.DiffAdd.Render("  " + line)
 		case strings.HasPrefix(line, "-"):
-			rendered = diffDelStyle.Render("  " + line)
+			rendered = t.DiffDel.Render("  " + line)
 		case strings.HasPrefix(line, "@@"):
-			rendered = diffMetaStyle.Render("  " + line)
+			rendered = t.DiffMeta.Render("  " + line)
 		default:
-			rendered = dimStyle.Render("  " + line)
+			rendered = t.Dim.Render("  " + line)
+		}
+		for _, row := range layout.fit(rendered, 3, width) {
+			sb.WriteString(row + "\n")
 		}
-		sb.WriteString(rendered + "\n")
 	}
 	sb.WriteString(border + "\n")
 	return sb.String()
@@ -446,49 +1190,111 @@ func renderDiff(diff string, width int) string {
 
 func (m *Model) renderGit() string {
 	var sb strings.Builder
-	sb.WriteString(heading("Git Changes"))
+	sb.WriteString(m.theme.heading("Git Changes"))
 	if m.bundle.Git == nil {
-		sb.WriteString(dimStyle.Render("  (not a git repository or git data unavailable)") + "\n")
+		sb.WriteString(m.theme.Dim.Render("  (not a git repository or git data unavailable)") + "\n")
 		return sb.String()
 	}
 	g := m.bundle.Git
+	layout := m.diffLayout()
+	defer func() { m.diffWidest[tabGit] = layout.widest }()
 	row := func(label, value string) {
-		sb.WriteString(labelStyle.Render(fmt.Sprintf("  %-14s", label)) + "  " + value + "\n")
+		sb.WriteString(m.theme.Label.Render(fmt.Sprintf("  %-14s", label)) + "  " + value + "\n")
 	}
 	row("Branch:", g.Branch)
 	row("Head Commit:", g.HeadCommit)
 
+	if stats := bundle.MergeDiffStats(g.StagedDiff, g.Diff); len(stats) > 0 {
+		sb.WriteString(m.theme.heading("Diff Stat"))
+		sb.WriteString(m.theme.renderDiffStat(stats))
+	}
+
 	if len(g.RecentLog) > 0 {
-		sb.WriteString(heading("Recent Commits"))
+		sb.WriteString(m.theme.heading("Recent Commits"))
 		for _, l := range g.RecentLog {
-			sb.WriteString(bullet(l))
+			sb.WriteString(m.theme.bullet(l))
+		}
+		if g.RecentLogOmitted > 0 {
+			sb.WriteString(m.theme.Dim.Render(fmt.Sprintf("    ... and %d more", g.RecentLogOmitted)) + "\n")
 		}
 	}
+	if m.bundle.DiffsOmitted {
+		sb.WriteString(m.theme.heading("Diff"))
+		sb.WriteString(m.theme.Dim.Render("  (diffs omitted from this bundle)") + "\n")
+	}
 	if g.StagedDiff != "" {
-		sb.WriteString(heading("Staged Diff"))
-		sb.WriteString(dimStyle.Render(indent(g.StagedDiff, "    ")) + "\n")
+		sb.WriteString(m.theme.heading("Staged Diff"))
+		sb.WriteString(layout.block(m.theme.Dim.Render(indent(g.StagedDiff, "    ")), 4, m.width) + "\n")
 	}
 	if g.Diff != "" {
-		sb.WriteString(heading("Unstaged Diff"))
-		sb.WriteString(dimStyle.Render(indent(g.Diff, "    ")) + "\n")
+		sb.WriteString(m.theme.heading("Unstaged Diff"))
+		sb.WriteString(layout.block(m.theme.Dim.Render(indent(g.Diff, "    ")), 4, m.width) + "\n")
+	}
+	if len(g.UntrackedFiles) > 0 {
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Untracked (%d)", len(g.UntrackedFiles))))
+		for _, f := range g.UntrackedFiles {
+			sb.WriteString(m.theme.bullet(f))
+		}
+		if g.UntrackedDiff != "" {
+			sb.WriteString("\n" + layout.block(m.theme.Dim.Render(indent(g.UntrackedDiff, "    ")), 4, m.width) + "\n")
+		}
+	}
+	if len(g.Stashes) > 0 {
+		sb.WriteString(m.theme.heading(fmt.Sprintf("Stashes (%d)", len(g.Stashes))))
+		for _, s := range g.Stashes {
+			sb.WriteString(m.theme.bullet(s))
+		}
 	}
 	return sb.String()
 }
 
+// renderDiffStat renders stats like `git diff --stat`: one "path | +N -M"
+// row per file followed by a totals line.
+func (t *Theme) renderDiffStat(stats []bundle.FileDiffStat) string {
+	width := 0
+	for _, st := range stats {
+		width = max(width, len(st.Path))
+	}
+	var sb strings.Builder
+	added, removed := 0, 0
+	for _, st := range stats {
+		counts := t.DiffAdd.Render(fmt.Sprintf("+%d", st.Added)) + " " +
+			t.DiffDel.Render(fmt.Sprintf("-%d", st.Removed))
+		if st.Binary {
+			counts = t.Dim.Render("Bin")
+		}
+		sb.WriteString(fmt.Sprintf("    %-*s %s %s\n", width, st.Path, t.Dim.Render("|"), counts))
+		added += st.Added
+		removed += st.Removed
+	}
+	noun := "files"
+	if len(stats) == 1 {
+		noun = "file"
+	}
+	sb.WriteString(t.Dim.Render(fmt.Sprintf("    %d %s changed, +%d -%d", len(stats), noun, added, removed)) + "\n")
+	return sb.String()
+}
+
 func (m *Model) renderCommands() string {
 	var sb strings.Builder
-	sb.WriteString(heading(fmt.Sprintf("Terminal Commands (%d)", len(m.bundle.Commands))))
+	sb.WriteString(m.theme.heading(fmt.Sprintf("Terminal Commands (%d)", len(m.bundle.Commands))))
 	if len(m.bundle.Commands) == 0 {
-		sb.WriteString(dimStyle.Render("  (none)") + "\n")
+		sb.WriteString(m.theme.Dim.Render("  (none)") + "\n")
 		return sb.String()
 	}
+	m.itemLines[tabCommands] = nil
 	for i, c := range m.bundle.Commands {
-		num := dimStyle.Render(fmt.Sprintf("  %3d.", i+1))
+		m.itemLines[tabCommands] = append(m.itemLines[tabCommands], strings.Count(sb.String(), "\n"))
+		num := m.theme.Dim.Render(fmt.Sprintf("  %3d.", i+1))
+		label := c.Label()
+		if c.Failed() {
+			label += "  " + m.theme.Failed.Render(fmt.Sprintf("✗ exit %d", *c.ExitCode))
+		}
 		if !c.Timestamp.IsZero() && c.Timestamp.Year() > 1 {
-			ts := timeStyle.Render(" [" + c.Timestamp.Format("15:04:05") + "]")
-			sb.WriteString(num + ts + "  " + c.Raw + "\n\n")
+			ts := m.theme.Time.Render(" [" + m.stamp(c.Timestamp) + "]")
+			sb.WriteString(num + ts + "  " + label + "\n\n")
 		} else {
-			sb.WriteString(num + "  " + c.Raw + "\n\n")
+			sb.WriteString(num + "  " + label + "\n\n")
 		}
 	}
 	return sb.String()
@@ -496,14 +1302,37 @@ func (m *Model) renderCommands() string {
 
 func (m *Model) renderEditorTabs() string {
 	var sb strings.Builder
-	sb.WriteString(heading(fmt.Sprintf("Editor Tabs (%d)", len(m.bundle.EditorTabs))))
+	sb.WriteString(m.theme.heading(fmt.Sprintf("Editor Tabs (%d)", len(m.bundle.EditorTabs))))
 	if len(m.bundle.EditorTabs) == 0 {
-		sb.WriteString(dimStyle.Render("  (none)") + "\n")
+		sb.WriteString(m.theme.Dim.Render("  (none)") + "\n")
 		return sb.String()
 	}
+	m.itemLines[tabEditorTabs] = nil
 	for i, tab := range m.bundle.EditorTabs {
-		num := dimStyle.Render(fmt.Sprintf("  %3d.", i+1))
-		sb.WriteString(num + "  " + stripWorkDir(tab, m.bundle.Session.WorkDir) + "\n\n")
+		m.itemLines[tabEditorTabs] = append(m.itemLines[tabEditorTabs], strings.Count(sb.String(), "\n"))
+		num := m.theme.Dim.Render(fmt.Sprintf("  %3d.", i+1))
+		path := m.fitPath(stripWorkDir(tab, m.bundle.Session.WorkDir), m.width-2-8)
+		sb.WriteString(num + "  " + path + "\n\n")
+	}
+	return sb.String()
+}
+
+func (m *Model) renderBrowserTabs() string {
+	var sb strings.Builder
+	sb.WriteString(m.theme.heading(fmt.Sprintf("Browser Tabs (%d)", len(m.bundle.BrowserTabs))))
+	if len(m.bundle.BrowserTabs) == 0 {
+		sb.WriteString(m.theme.Dim.Render("  (none)") + "\n")
+		return sb.String()
+	}
+	m.itemLines[tabBrowserTabs] = nil
+	for i, t := range m.bundle.BrowserTabs {
+		m.itemLines[tabBrowserTabs] = append(m.itemLines[tabBrowserTabs], strings.Count(sb.String(), "\n"))
+		num := m.theme.Dim.Render(fmt.Sprintf("  %3d.", i+1))
+		if t.Title != "" {
+			sb.WriteString(num + "  " + t.Title + "\n       " + m.theme.Dim.Render(t.URL) + "\n\n")
+		} else {
+			sb.WriteString(num + "  " + t.URL + "\n\n")
+		}
 	}
 	return sb.String()
 }
@@ -515,10 +1344,17 @@ func (m *Model) renderTimeline() string {
 	if m.sortAsc {
 		dir = "oldest first"
 	}
-	sb.WriteString(heading(fmt.Sprintf("Timeline (%s)", dir)))
+	if m.hasTimeRange() {
+		dir += ", " + m.timeRangeLabel()
+	}
+	sb.WriteString(m.theme.heading(fmt.Sprintf("Timeline (%s)", dir)))
 
-	events := make([]timelineEvent, len(m.timeline))
-	copy(events, m.timeline)
+	events := make([]timelineEvent, 0, len(m.timeline))
+	for _, ev := range m.timeline {
+		if m.inTimeRange(ev.ts) {
+			events = append(events, ev)
+		}
+	}
 	if m.sortAsc {
 		sort.Slice(events, func(i, j int) bool { return events[i].ts.Before(events[j].ts) })
 	} else {
@@ -526,26 +1362,102 @@ func (m *Model) renderTimeline() string {
 	}
 
 	if len(events) == 0 {
-		sb.WriteString(dimStyle.Render("  (no timestamped events in this session)") + "\n")
+		if m.hasTimeRange() {
+			sb.WriteString(m.theme.Dim.Render("  (no events in the selected time range)") + "\n")
+		} else {
+			sb.WriteString(m.theme.Dim.Render("  (no timestamped events in this session)") + "\n")
+		}
 		return sb.String()
 	}
 
-	for _, ev := range events {
-		ts := timeStyle.Render(ev.ts.Format("15:04:05"))
-		var badge string
-		switch ev.kind {
-		case kindNote, kindSummary:
-			badge = kindAnnotationStyle.Render(fmt.Sprintf("  %-8s", string(ev.kind)))
-		case kindEdit:
-			badge = kindFileEditStyle.Render(fmt.Sprintf("  %-8s", string(ev.kind)))
-		case kindCmd:
-			badge = kindCommandStyle.Render(fmt.Sprintf("  %-8s", string(ev.kind)))
+	for _, c := range clusterTimeline(events, m.clusterWindow) {
+		ts := m.theme.Time.Render(m.stamp(c.head.ts))
+		sb.WriteString(ts + m.theme.eventBadge(c.head.kind) + "  " + c.head.text + "\n")
+		for _, ev := range c.edits {
+			ts := m.theme.Time.Render(m.stamp(ev.ts))
+			sb.WriteString(ts + m.theme.Dim.Render("    └") + m.theme.eventBadge(ev.kind) + ev.text + "\n")
 		}
-		sb.WriteString(ts + badge + "  " + ev.text + "\n\n")
+		sb.WriteString("\n")
 	}
 	return sb.String()
 }
 
+// timelineCluster is an event shown on the Timeline tab with, for an
+// annotation, the file edits made around the same time indented under it.
+type timelineCluster struct {
+	head  timelineEvent
+	edits []timelineEvent
+}
+
+// clusterTimeline groups each file edit under the annotation nearest to it
+// in time, if one is within window, keeping the order of events. Edits near
+// no annotation, and everything else, stay on their own.
+func clusterTimeline(events []timelineEvent, window time.Duration) []timelineCluster {
+	owner := make([]int, len(events)) // index of the annotation an edit goes under, or -1
+	for i, ev := range events {
+		owner[i] = -1
+		if window <= 0 || ev.kind != kindEdit {
+			continue
+		}
+		best := window + 1
+		for j, other := range events {
+			if !other.kind.isAnnotation() {
+				continue
+			}
+			if d := absDuration(ev.ts.Sub(other.ts)); d <= window && d < best {
+				owner[i], best = j, d
+			}
+		}
+	}
+
+	clusters := make([]timelineCluster, 0, len(events))
+	at := make(map[int]int) // annotation's event index → its cluster
+	for i, ev := range events {
+		if owner[i] >= 0 {
+			continue
+		}
+		if ev.kind.isAnnotation() {
+			at[i] = len(clusters)
+		}
+		clusters = append(clusters, timelineCluster{head: ev})
+	}
+	for i, ev := range events {
+		if j := owner[i]; j >= 0 {
+			c := &clusters[at[j]]
+			c.edits = append(c.edits, ev)
+		}
+	}
+	return clusters
+}
+
+// isAnnotation reports whether events of this kind come from annotations.
+func (k eventKind) isAnnotation() bool {
+	return k == kindNote || k == kindSummary || k == kindBlocker
+}
+
+func absDuration(d time.Duration) time.Duration {
+	if d < 0 {
+		return -d
+	}
+	return d
+}
+
+// eventBadge renders the colored kind label of a timeline event.
+func (t *Theme) eventBadge(kind eventKind) string {
+	label := fmt.Sprintf("  %-8s", string(kind))
+	switch kind {
+	case kindNote, kindSummary:
+		return t.KindAnnotation.Render(label)
+	case kindEdit:
+		return t.KindFileEdit.Render(label)
+	case kindCmd:
+		return t.KindCommand.Render(label)
+	case kindBlocker:
+		return t.KindBlocker.Render(label)
+	}
+	return label
+}
+
 // ── Helpers ───────────────────────────────────────────────────────────────────
 
 func buildTimeline(b *bundle.ContextBundle) []timelineEvent {
@@ -559,37 +1471,141 @@ func buildTimeline(b *bundle.ContextBundle) []timelineEvent {
 		if a.IsSummary {
 			k = kindSummary
 		}
+		if a.IsBlocker() {
+			k = kindBlocker
+		}
 		events = append(events, timelineEvent{ts: a.Timestamp, kind: k, text: a.Message})
 	}
 	for _, fe := range b.FileEdits {
 		if fe.Timestamp == zero {
 			continue
 		}
-		events = append(events, timelineEvent{ts: fe.Timestamp, kind: kindEdit, text: stripWorkDir(fe.Path, b.Session.WorkDir)})
+		events = append(events, timelineEvent{ts: fe.Timestamp, kind: kindEdit, text: stripWorkDir(fe.Path, b.Session.WorkDir), diff: fe.Diff})
 	}
 	for _, c := range b.Commands {
 		if c.Timestamp == zero || c.Timestamp.Year() <= 1 {
 			continue
 		}
-		events = append(events, timelineEvent{ts: c.Timestamp, kind: kindCmd, text: c.Raw})
+		events = append(events, timelineEvent{ts: c.Timestamp, kind: kindCmd, text: c.Label()})
 	}
 	return events
 }
 
-// stripWorkDir removes the workDir prefix from path, returning a relative path.
-// If path doesn't start with workDir, it's returned unchanged.
+// parseClock parses a wall-clock time ("15:04" or "15:04:05") on the same day
+// and in the same location as ref. Full "2006-01-02 15:04" timestamps are
+// also accepted for sessions that span midnight.
+func parseClock(value string, ref time.Time) (time.Time, error) {
+	loc := ref.Location()
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, nil
+		}
+	}
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			y, mo, d := ref.Date()
+			return time.Date(y, mo, d, t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q (want HH:MM)", value)
+}
+
+// stamp formats an event time for the list tabs: the clock time, or with
+// relTimes the time left until the session stopped ("−2m15s"), padded to the
+// same width so columns line up.
+func (m *Model) stamp(t time.Time) string {
+	if !m.relTimes {
+		return m.inZone(t).Format("15:04:05")
+	}
+	return fmt.Sprintf("%8s", relativeTime(t, m.bundle.Session.StopTime))
+}
+
+// inZone converts t to the zone times are shown in.
+func (m *Model) inZone(t time.Time) time.Time {
+	if m.loc == nil {
+		return t
+	}
+	return t.In(m.loc)
+}
+
+// zoneLabel names loc for the status bar: its IANA name, or for the local
+// zone "local" and its abbreviation.
+func zoneLabel(loc *time.Location) string {
+	if loc == time.Local {
+		return "local (" + time.Now().Format("MST") + ")"
+	}
+	return loc.String()
+}
+
+// relativeTime formats t as an offset from ref: "−45s", "−2m15s", "−1h03m",
+// or with a "+" for times after ref.
+func relativeTime(t, ref time.Time) string {
+	d := ref.Sub(t).Round(time.Second)
+	sign := "−"
+	if d < 0 {
+		sign, d = "+", -d
+	} else if d == 0 {
+		return "0s"
+	}
+	switch {
+	case d >= time.Hour:
+		return fmt.Sprintf("%s%dh%02dm", sign, int(d.Hours()), int(d.Minutes())%60)
+	case d >= time.Minute:
+		return fmt.Sprintf("%s%dm%02ds", sign, int(d.Minutes()), int(d.Seconds())%60)
+	}
+	return fmt.Sprintf("%s%ds", sign, int(d.Seconds()))
+}
+
+// formatClock formats t for pre-filling a time prompt; zero yields "".
+func formatClock(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format("15:04")
+}
+
+// stripWorkDir removes the workDir prefix from path, returning a relative path
+// with forward slashes whichever OS the bundle was made on. If path isn't
+// under workDir, it's returned whole with forward slashes.
 func stripWorkDir(path, workDir string) string {
-	if workDir == "" {
+	rel, _ := session.RelPath(path, workDir)
+	return rel
+}
+
+// fitPath abbreviates path to fit in width columns unless the user turned
+// abbreviation off with `a`.
+func (m *Model) fitPath(path string, width int) string {
+	if m.fullPaths {
 		return path
 	}
-	prefix := workDir
-	if !strings.HasSuffix(prefix, string(filepath.Separator)) {
-		prefix += string(filepath.Separator)
+	return abbreviatePath(path, width)
+}
+
+// abbreviatePath shortens the directories of path to their first letter,
+// from the outermost inwards, until it fits in width columns, e.g.
+// "services/billing/internal/handlers/v2/invoice.go" becomes
+// "s/b/i/h/v2/invoice.go". The file name is never shortened, so the result
+// may still exceed width. path uses forward slashes, as from stripWorkDir.
+func abbreviatePath(path string, width int) string {
+	if lipgloss.Width(path) <= width {
+		return path
 	}
-	if strings.HasPrefix(path, prefix) {
-		return path[len(prefix):]
+	const sep = "/"
+	parts := strings.Split(path, sep)
+	for i := 0; i < len(parts)-1; i++ {
+		dir := []rune(parts[i])
+		keep := 1
+		if len(dir) > 1 && dir[0] == '.' {
+			keep = 2 // ".github" → ".g"
+		}
+		if len(dir) > keep {
+			parts[i] = string(dir[:keep])
+		}
+		if short := strings.Join(parts, sep); lipgloss.Width(short) <= width {
+			return short
+		}
 	}
-	return path
+	return strings.Join(parts, sep)
 }
 
 func indent(s, prefix string) string {
@@ -602,9 +1618,35 @@ func indent(s, prefix string) string {
 	return strings.Join(lines, "\n")
 }
 
+// Options adjusts how Run displays a bundle.
+type Options struct {
+	// Raw shows annotation messages as plain text, without rendering
+	// their Markdown.
+	Raw bool
+	// Theme is the set of styles to draw with; see LookupTheme.
+	Theme Theme
+	// ClusterWindow groups file edits on the Timeline tab under an
+	// annotation made within this long of them; zero lists every event on
+	// its own.
+	ClusterWindow time.Duration
+	// Location converts the times shown to this zone; nil shows them in
+	// the zone they were recorded in.
+	Location *time.Location
+	// Save writes edited annotations back to the bundle's file; nil makes
+	// the bundle read-only. Signed warns before a write that the file's
+	// signature will be removed.
+	Save   func(*bundle.ContextBundle) error
+	Signed bool
+}
+
 // Run starts the TUI for the given bundle.
-func Run(b *bundle.ContextBundle, filename string) error {
-	p := tea.NewProgram(New(b, filename), tea.WithAltScreen())
+func Run(b *bundle.ContextBundle, filename string, opts Options) error {
+	m := New(b, filename, opts.Theme)
+	m.raw = opts.Raw
+	m.clusterWindow = opts.ClusterWindow
+	m.loc = opts.Location
+	m.save, m.signed = opts.Save, opts.Signed
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	_, err := p.Run()
 	return err
 }
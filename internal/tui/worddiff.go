@@ -0,0 +1,110 @@
+package tui
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const (
+	// maxIntralineRunes caps the LCS input so huge (e.g. minified) lines
+	// don't cost quadratic time; they fall back to whole-line coloring.
+	maxIntralineRunes = 500
+	// minIntralineSimilarity is the share of characters two lines must have
+	// in common before highlighting their differences is worth it.
+	minIntralineSimilarity = 0.5
+)
+
+func isDiffRemoval(line string) bool {
+	return strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")
+}
+
+func isDiffAddition(line string) bool {
+	return strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")
+}
+
+// renderChangeBlock renders a run of removed lines followed by a run of added
+// lines. Lines are paired in order and each pair gets intra-line highlights
+// when similar enough (changed characters bright, unchanged ones dim);
+// everything else keeps the whole-line colors.
+func (t *Theme) renderChangeBlock(dels, adds []string) []string {
+	delOut := make([]string, len(dels))
+	addOut := make([]string, len(adds))
+	for i, d := range dels {
+		delOut[i] = t.DiffDel.Render("  " + d)
+	}
+	for i, a := range adds {
+		addOut[i] = t.DiffAdd.Render("  " + a)
+	}
+	for i := 0; i < len(dels) && i < len(adds); i++ {
+		if d, a, ok := t.highlightPair(dels[i][1:], adds[i][1:]); ok {
+			delOut[i] = t.DiffDel.Render("  -") + d
+			addOut[i] = t.DiffAdd.Render("  +") + a
+		}
+	}
+	return append(delOut, addOut...)
+}
+
+// highlightPair styles the characters of before and after that are not part of
+// their longest common subsequence. ok is false when the lines are too long
+// or too dissimilar for the highlight to help.
+func (t *Theme) highlightPair(before, after string) (string, string, bool) {
+	a, b := []rune(before), []rune(after)
+	if len(a) > maxIntralineRunes || len(b) > maxIntralineRunes || len(a)+len(b) == 0 {
+		return "", "", false
+	}
+
+	// dp[i][j] is the LCS length of a[i:] and b[j:].
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				dp[i][j] = dp[i+1][j+1] + 1
+			} else {
+				dp[i][j] = max(dp[i+1][j], dp[i][j+1])
+			}
+		}
+	}
+	if float64(2*dp[0][0])/float64(len(a)+len(b)) < minIntralineSimilarity {
+		return "", "", false
+	}
+
+	commonA := make([]bool, len(a))
+	commonB := make([]bool, len(b))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			commonA[i], commonB[j] = true, true
+			i++
+			j++
+		case dp[i+1][j] >= dp[i][j+1]:
+			i++
+		default:
+			j++
+		}
+	}
+	return styleRuns(a, commonA, t.DiffDelDim, t.DiffDelHi),
+		styleRuns(b, commonB, t.DiffAddDim, t.DiffAddHi), true
+}
+
+// styleRuns renders runes in runs, using dim for common runes and hi for
+// changed ones.
+func styleRuns(runes []rune, common []bool, dim, hi lipgloss.Style) string {
+	var sb strings.Builder
+	for start := 0; start < len(runes); {
+		end := start
+		for end < len(runes) && common[end] == common[start] {
+			end++
+		}
+		style := hi
+		if common[start] {
+			style = dim
+		}
+		sb.WriteString(style.Render(string(runes[start:end])))
+		start = end
+	}
+	return sb.String()
+}